@@ -7,67 +7,231 @@ import (
 	"strings"
 )
 
-// GetTotalValue 获取现货账户总价值（以USDT计价）
+// defaultReportingCurrency 未配置valuation.currency时使用的计价币种
+const defaultReportingCurrency = "USDT"
+
+var (
+	defaultStablecoins   = []string{"USDT", "USDC", "FDUSD", "DAI", "TUSD"}
+	defaultIntermediates = []string{"BTC", "ETH"}
+)
+
+// AssetValue 单个币种的估值
+type AssetValue struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+	Price    float64 `json:"price"` // 以计价币种表示的单价
+	Value    float64 `json:"value"`
+	Route    string  `json:"route"` // 估值使用的交易对，例如 ETH_BTC*BTC_USDT；稳定币为 par
+}
+
+// UnvaluedAsset 无法估值的币种
+type UnvaluedAsset struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+	Reason   string  `json:"reason"`
+}
+
+// Valuation 账户估值明细
+type Valuation struct {
+	QuoteCurrency string          `json:"quote_currency"`
+	TotalValue    float64         `json:"total_value"`
+	Assets        []AssetValue    `json:"assets"`
+	Unvalued      []UnvaluedAsset `json:"unvalued"` // 找不到行情的币种，未计入总价值
+}
+
+// ReportingCurrency 获取账户总价值的计价币种
+func ReportingCurrency() string {
+	if config.AppConfig.Valuation.Currency != "" {
+		return strings.ToUpper(config.AppConfig.Valuation.Currency)
+	}
+	return defaultReportingCurrency
+}
+
+// GetTotalValue 获取账户总价值，以ReportingCurrency计价
 func GetTotalValue(ex exchange.Exchange) (float64, error) {
-	// 获取所有币种的余额信息
-	gateio, ok := ex.(*exchange.GateIO)
-	if !ok {
-		return 0, fmt.Errorf("不支持的交易所类型，无法获取账户总价值")
+	valuation, err := Valuate(ex)
+	if err != nil {
+		return 0, err
 	}
-	
-	// 获取账户余额
-	balances, err := gateio.GetClient().GetAccountBalance("")
+	return valuation.TotalValue, nil
+}
+
+// Valuate 计算账户中各币种的价值
+// 优先一次获取全部行情，没有直接计价交易对时经中间币种换算，稳定币按1:1计价
+func Valuate(ex exchange.Exchange) (*Valuation, error) {
+	// 获取所有币种的余额信息
+	balances, err := ex.GetAllBalances()
 	if err != nil {
-		return 0, fmt.Errorf("获取账户余额失败: %w", err)
+		return nil, fmt.Errorf("获取账户余额失败: %w", err)
 	}
 	
-	// 初始化总价值
-	totalValue := 0.0
+	quoteCurrency := ReportingCurrency()
+	book := newPriceBook(ex, true)
+	valuation := &Valuation{QuoteCurrency: quoteCurrency}
+	
+	for _, balance := range balances {
+		if balance.Total <= 0 {
+			continue
+		}
+		
+		price, route, ok := book.valueOf(balance.Currency, quoteCurrency)
+		if !ok {
+			valuation.Unvalued = append(valuation.Unvalued, UnvaluedAsset{
+				Currency: balance.Currency,
+				Amount:   balance.Total,
+				Reason:   fmt.Sprintf("没有可用于换算为%s的交易对", quoteCurrency),
+			})
+			continue
+		}
+		
+		asset := AssetValue{
+			Currency: balance.Currency,
+			Amount:   balance.Total,
+			Price:    price,
+			Value:    balance.Total * price,
+			Route:    route,
+		}
+		valuation.Assets = append(valuation.Assets, asset)
+		valuation.TotalValue += asset.Value
+		
+		config.Logger.Debugw("添加币种到总价值",
+			"currency", asset.Currency,
+			"amount", asset.Amount,
+			"price", asset.Price,
+			"route", asset.Route,
+			"value", asset.Value,
+		)
+	}
 	
-	// 遍历所有币种余额
-	for key, value := range balances {
-		// 只处理 total 余额
-		if strings.HasSuffix(key, ".total") {
-			currency := strings.TrimSuffix(key, ".total")
-			
-			// 如果是 USDT，直接加到总价值中
-			if currency == "USDT" {
-				totalValue += value
-				config.Logger.Debugw("添加USDT余额到总价值",
-					"currency", currency,
-					"amount", value,
-					"total_value", totalValue,
-				)
-			} else if value > 0 {
-				// 对于非 USDT 币种且余额大于0，获取其 USDT 价格并计算价值
-				price, err := ex.GetSymbolPrice(currency + "_USDT")
-				if err != nil {
-					// 如果获取价格失败，记录日志但继续处理其他币种
-					config.Logger.Warnw("获取币种价格失败，跳过该币种",
-						"currency", currency,
-						"error", err.Error(),
-					)
-					continue
-				}
-				
-				// 计算该币种的 USDT 价值并加到总价值中
-				currencyValue := value * price
-				totalValue += currencyValue
-				
-				config.Logger.Debugw("添加非USDT币种到总价值",
-					"currency", currency,
-					"amount", value,
-					"price", price,
-					"value", currencyValue,
-					"total_value", totalValue,
-				)
-			}
+	if len(valuation.Unvalued) > 0 {
+		currencies := make([]string, 0, len(valuation.Unvalued))
+		for _, asset := range valuation.Unvalued {
+			currencies = append(currencies, asset.Currency)
 		}
+		config.Logger.Warnw("部分币种无法估值，未计入账户总价值",
+			"currencies", strings.Join(currencies, ","),
+		)
 	}
 	
 	config.Logger.Infow("计算账户总价值完成",
-		"total_value", totalValue,
+		"total_value", valuation.TotalValue,
+		"valued_assets", len(valuation.Assets),
+		"unvalued_assets", len(valuation.Unvalued),
 	)
 	
-	return totalValue, nil
+	return valuation, nil
+}
+
+// ConversionRate 获取1单位from币种折合多少to币种，规则与账户估值一致
+func ConversionRate(ex exchange.Exchange, from, to string) (float64, error) {
+	book := newPriceBook(ex, false)
+	rate, _, ok := book.valueOf(strings.ToUpper(from), strings.ToUpper(to))
+	if !ok {
+		return 0, fmt.Errorf("无法将%s换算为%s", from, to)
+	}
+	return rate, nil
+}
+
+// priceBook 一次估值过程中使用的行情
+// 交易所支持时一次取回全部行情，否则按需逐个查询并缓存结果（包括查询失败）
+type priceBook struct {
+	ex     exchange.Exchange
+	prices map[string]float64
+	batch  bool
+	
+	stablecoins   map[string]bool
+	intermediates []string
+}
+
+// newPriceBook 创建估值行情，batch为true时先批量获取全部行情，失败时退回逐个查询
+func newPriceBook(ex exchange.Exchange, batch bool) *priceBook {
+	book := &priceBook{
+		ex:            ex,
+		prices:        make(map[string]float64),
+		stablecoins:   make(map[string]bool),
+		intermediates: defaultIntermediates,
+	}
+	
+	stablecoins := defaultStablecoins
+	if len(config.AppConfig.Valuation.Stablecoins) > 0 {
+		stablecoins = config.AppConfig.Valuation.Stablecoins
+	}
+	for _, coin := range stablecoins {
+		book.stablecoins[strings.ToUpper(coin)] = true
+	}
+	if len(config.AppConfig.Valuation.Intermediates) > 0 {
+		book.intermediates = config.AppConfig.Valuation.Intermediates
+	}
+	
+	if !batch {
+		return book
+	}
+	if provider, ok := ex.(exchange.TickerProvider); ok {
+		prices, err := provider.GetAllPrices()
+		if err == nil {
+			book.prices = prices
+			book.batch = true
+		} else {
+			config.Logger.Debugw("批量获取行情失败，逐个查询交易对价格",
+				"error", err.Error(),
+			)
+		}
+	}
+	
+	return book
+}
+
+// price 获取交易对最新价
+func (b *priceBook) price(symbol string) (float64, bool) {
+	if price, ok := b.prices[symbol]; ok || b.batch {
+		return price, price > 0
+	}
+	
+	price, err := b.ex.GetSymbolPrice(symbol)
+	if err != nil {
+		price = 0
+	}
+	b.prices[symbol] = price
+	return price, price > 0
+}
+
+// rate 获取base以quote计价的汇率，只有反向交易对时取倒数
+func (b *priceBook) rate(base, quote string) (float64, string, bool) {
+	if base == quote {
+		return 1, "", true
+	}
+	if b.stablecoins[base] && b.stablecoins[quote] {
+		return 1, "par", true
+	}
+	if price, ok := b.price(base + "_" + quote); ok {
+		return price, base + "_" + quote, true
+	}
+	if price, ok := b.price(quote + "_" + base); ok {
+		return 1 / price, "1/" + quote + "_" + base, true
+	}
+	return 0, "", false
+}
+
+// valueOf 获取币种以quote计价的单价，没有直接交易对时经中间币种换算
+func (b *priceBook) valueOf(currency, quote string) (float64, string, bool) {
+	if price, route, ok := b.rate(currency, quote); ok {
+		return price, route, true
+	}
+	
+	for _, via := range b.intermediates {
+		via = strings.ToUpper(via)
+		if via == currency || via == quote {
+			continue
+		}
+		first, firstRoute, ok := b.rate(currency, via)
+		if !ok {
+			continue
+		}
+		second, secondRoute, ok := b.rate(via, quote)
+		if !ok {
+			continue
+		}
+		return first * second, firstRoute + "*" + secondRoute, true
+	}
+	return 0, "", false
 }
@@ -1,9 +1,13 @@
 package admin
 
 import (
+	"fmt"
 	"net/http"
+	"order_go/internal/constants"
+	"order_go/internal/exchange"
 	"order_go/internal/models"
 	"order_go/internal/repository"
+	"order_go/internal/rulesync"
 	"strconv"
 	"time"
 
@@ -77,6 +81,22 @@ func CreateContractCode(c *gin.Context) {
 		})
 		return
 	}
+	
+	// 验证市场类型只能是现货或永续合约
+	if contractCode.MarketType != "" && contractCode.MarketType != constants.ExchangeTypeSpot && contractCode.MarketType != constants.ExchangeTypeFutures {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "市场类型只能是spot或futures",
+		})
+		return
+	}
+	
+	// 指定了交易所时，交易所的市场类型必须与交易对一致
+	if err := validateContractExchange(&contractCode); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	// 检查交易对是否已存在
 	// 使用Count而不是First，避免在没有记录时报错
@@ -137,6 +157,22 @@ func UpdateContractCode(c *gin.Context) {
 		})
 		return
 	}
+	
+	// 验证市场类型只能是现货或永续合约
+	if contractCode.MarketType != "" && contractCode.MarketType != constants.ExchangeTypeSpot && contractCode.MarketType != constants.ExchangeTypeFutures {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "市场类型只能是spot或futures",
+		})
+		return
+	}
+	
+	// 指定了交易所时，交易所的市场类型必须与交易对一致
+	if err := validateContractExchange(&contractCode); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	// 如果Symbol或Code发生变化，检查是否与其他记录冲突
 	if (contractCode.Symbol != originalSymbol || contractCode.Code != originalCode) && 
@@ -161,7 +197,9 @@ func UpdateContractCode(c *gin.Context) {
 		"min_amount":        contractCode.MinAmount,
 		"amount_precision":  contractCode.AmountPrecision,
 		"price_precision":   contractCode.PricePrecision,
+		"min_notional":      contractCode.MinNotional,
 		"max_position_ratio": contractCode.MaxPositionRatio,
+		"market_type":       contractCode.MarketType,
 		"status":            contractCode.Status,
 		"updated_at":        contractCode.UpdatedAt,
 	}).Error; err != nil {
@@ -238,3 +276,50 @@ func GetContractCodeBySymbol(c *gin.Context) {
 
 	c.JSON(http.StatusOK, contractCode)
 }
+
+// GetContractRuleDiff 对比交易对配置与交易所规则，只报告差异不写入
+func GetContractRuleDiff(c *gin.Context) {
+	report, err := rulesync.Sync(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "对比交易所规则失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// SyncContractRules 将交易所规则写入交易对配置，并返回同步前的差异
+func SyncContractRules(c *gin.Context) {
+	report, err := rulesync.Sync(true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "同步交易所规则失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// validateContractExchange 校验交易对指定的交易所存在且市场类型一致
+func validateContractExchange(contractCode *models.ContractCode) error {
+	if contractCode.ExchangeID == nil {
+		return nil
+	}
+
+	var ex models.Exchange
+	if err := repository.DB.First(&ex, *contractCode.ExchangeID).Error; err != nil {
+		return fmt.Errorf("交易所不存在: %d", *contractCode.ExchangeID)
+	}
+
+	marketType := contractCode.MarketType
+	if marketType == "" {
+		marketType = constants.ExchangeTypeSpot
+	}
+	if exchange.MarketOf(ex.Kind()) != marketType {
+		return fmt.Errorf("交易所%s不支持%s市场", ex.Code, marketType)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package admin
+
+import (
+	"net/http"
+	"order_go/internal/exchange"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/trading"
+	"order_go/internal/utils/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// secretMask 返回给前端的密钥掩码，更新时传回掩码表示不修改
+const secretMask = "******"
+
+// GetExchanges 获取交易所列表
+func GetExchanges(c *gin.Context) {
+	var exchanges []models.Exchange
+	if err := repository.DB.Order("id").Find(&exchanges).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "查询交易所失败: " + err.Error(),
+		})
+		return
+	}
+
+	for i := range exchanges {
+		maskExchangeSecrets(&exchanges[i])
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"items": exchanges,
+		"total": len(exchanges),
+	})
+}
+
+// GetExchangeByID 根据ID获取交易所
+func GetExchangeByID(c *gin.Context) {
+	var ex models.Exchange
+	if err := repository.DB.First(&ex, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "交易所不存在",
+		})
+		return
+	}
+
+	maskExchangeSecrets(&ex)
+	c.JSON(http.StatusOK, ex)
+}
+
+// CreateExchange 新增交易所，启用的交易所立即注册到交易引擎
+func CreateExchange(c *gin.Context) {
+	var ex models.Exchange
+	if err := c.ShouldBindJSON(&ex); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "无效的请求数据: " + err.Error(),
+		})
+		return
+	}
+
+	if !exchange.IsSupportedKind(ex.Kind()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "不支持的交易所类型: " + ex.Kind(),
+		})
+		return
+	}
+
+	var count int64
+	repository.DB.Model(&models.Exchange{}).Where("code = ?", ex.Code).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "交易所代码已存在",
+		})
+		return
+	}
+
+	if err := repository.DB.Create(&ex).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "创建交易所失败: " + err.Error(),
+		})
+		return
+	}
+
+	reloadExchanges()
+	maskExchangeSecrets(&ex)
+	c.JSON(http.StatusCreated, ex)
+}
+
+// UpdateExchange 更新交易所，修改密钥、类型或停用后立即生效
+// 密钥字段为空或为掩码时保留原值
+func UpdateExchange(c *gin.Context) {
+	var ex models.Exchange
+	if err := repository.DB.First(&ex, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "交易所不存在",
+		})
+		return
+	}
+
+	var req models.Exchange
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "无效的请求数据: " + err.Error(),
+		})
+		return
+	}
+
+	if !exchange.IsSupportedKind(req.Kind()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "不支持的交易所类型: " + req.Kind(),
+		})
+		return
+	}
+
+	var count int64
+	repository.DB.Model(&models.Exchange{}).Where("code = ? AND id != ?", req.Code, ex.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "交易所代码已被其他交易所使用",
+		})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"name":    req.Name,
+		"code":    req.Code,
+		"type":    req.Type,
+		"account": req.Account,
+		"status":  req.Status,
+	}
+	// 未传或传回掩码的密钥保持不变
+	if req.ApiKey != "" && req.ApiKey != secretMask {
+		updates["api_key"] = req.ApiKey
+	}
+	if req.ApiSecret != "" && req.ApiSecret != secretMask {
+		updates["api_secret"] = req.ApiSecret
+	}
+	if req.Passphrase != "" && req.Passphrase != secretMask {
+		updates["passphrase"] = req.Passphrase
+	}
+
+	if err := repository.DB.Model(&ex).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "更新交易所失败: " + err.Error(),
+		})
+		return
+	}
+
+	if err := repository.DB.First(&ex, ex.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "获取更新后的交易所失败: " + err.Error(),
+		})
+		return
+	}
+
+	reloadExchanges()
+	maskExchangeSecrets(&ex)
+	c.JSON(http.StatusOK, ex)
+}
+
+// DeleteExchange 删除交易所，仍被交易对引用时不允许删除，可改为停用
+func DeleteExchange(c *gin.Context) {
+	var ex models.Exchange
+	if err := repository.DB.First(&ex, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "交易所不存在",
+		})
+		return
+	}
+
+	var count int64
+	repository.DB.Model(&models.ContractCode{}).Where("exchange_id = ?", ex.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "交易所仍被交易对使用，请先修改交易对或停用交易所",
+		})
+		return
+	}
+
+	if err := repository.DB.Delete(&ex).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "删除交易所失败: " + err.Error(),
+		})
+		return
+	}
+
+	reloadExchanges()
+	c.JSON(http.StatusOK, gin.H{
+		"message": "交易所删除成功",
+	})
+}
+
+// reloadExchanges 让交易引擎按最新的交易所记录重新注册
+// 数据库已经修改成功，重新加载失败只记录日志，下次修改或重启时会再次加载
+func reloadExchanges() {
+	if err := trading.GetEngine().ReloadExchanges(); err != nil {
+		config.Logger.Errorw("重新加载交易所失败",
+			"error", err.Error(),
+		)
+	}
+}
+
+// maskExchangeSecrets 隐藏返回结果中的密钥
+func maskExchangeSecrets(ex *models.Exchange) {
+	if ex.ApiSecret != "" {
+		ex.ApiSecret = secretMask
+	}
+	if ex.Passphrase != "" {
+		ex.Passphrase = secretMask
+	}
+}
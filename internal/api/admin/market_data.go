@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"net/http"
+	"order_go/internal/marketdata"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMarketDataCoverage 获取行情记录的覆盖情况，可按symbol筛选
+// 返回每个交易对和周期已记录K线的时间范围、缺失数量，以及行情快照的时间范围
+func GetMarketDataCoverage(c *gin.Context) {
+	symbol := c.Query("symbol")
+
+	klines, err := marketdata.GetKlineCoverage(symbol)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "查询K线覆盖情况失败: " + err.Error(),
+		})
+		return
+	}
+
+	snapshots, err := marketdata.GetSnapshotCoverage(symbol)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "查询行情快照覆盖情况失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"klines":    klines,
+		"snapshots": snapshots,
+	})
+}
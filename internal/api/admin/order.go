@@ -65,4 +65,44 @@ func GetOrderByID(c *gin.Context) {
 	}
 	
 	c.JSON(http.StatusOK, order)
-}
\ No newline at end of file
+}
+// GetOrderEvents 获取订单详情及其状态变更时间线，以及超时后为剩余数量重新下的子订单
+func GetOrderEvents(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "无效的订单ID",
+		})
+		return
+	}
+	
+	order, err := repository.GetOrderByID(c, uint(id))
+	if err != nil || order.ID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "订单不存在",
+		})
+		return
+	}
+	
+	events, err := repository.GetOrderEvents(c, order.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "获取订单事件失败: " + err.Error(),
+		})
+		return
+	}
+	
+	children, err := repository.GetChildOrders(c, order.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "获取子订单失败: " + err.Error(),
+		})
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"order":    order,
+		"events":   events,
+		"children": children,
+	})
+}
@@ -0,0 +1,123 @@
+package admin
+
+import (
+	"net/http"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/trading"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// positionSummary 账户在交易对上的汇总持仓及各策略的明细
+type positionSummary struct {
+	models.PositionLedger
+	Strategies []models.PositionLedger `json:"strategies"`
+}
+
+// GetPositions 获取持仓账本，按账户和交易对汇总并附带各策略的明细
+// 可按account、symbol筛选，open=true时只返回仍有持仓的交易对
+func GetPositions(c *gin.Context) {
+	query := repository.DB.Model(&models.PositionLedger{})
+	if account, ok := c.GetQuery("account"); ok {
+		query = query.Where("account = ?", account)
+	}
+	if symbol := c.Query("symbol"); symbol != "" {
+		query = query.Where("symbol = ?", symbol)
+	}
+
+	var rows []models.PositionLedger
+	if err := query.Order("account, symbol, strategy_id").Find(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "查询持仓账本失败: " + err.Error(),
+		})
+		return
+	}
+
+	openOnly := c.Query("open") == "true"
+	items := make([]*positionSummary, 0)
+	byKey := make(map[string]*positionSummary)
+	for _, row := range rows {
+		key := row.Account + "|" + row.Symbol
+		if row.StrategyID == 0 {
+			if openOnly && row.Quantity == 0 {
+				continue
+			}
+			summary := &positionSummary{PositionLedger: row, Strategies: []models.PositionLedger{}}
+			byKey[key] = summary
+			items = append(items, summary)
+			continue
+		}
+		// 汇总行排在策略行之前
+		if summary, ok := byKey[key]; ok {
+			summary.Strategies = append(summary.Strategies, row)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// GetStrategyPositions 获取策略在各交易对上的持仓账本
+func GetStrategyPositions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "无效的策略ID",
+		})
+		return
+	}
+
+	var rows []models.PositionLedger
+	if err := repository.DB.Where("strategy_id = ?", id).Order("account, symbol").Find(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "查询持仓账本失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": rows,
+		"total": len(rows),
+	})
+}
+
+// RebuildPositions 按订单记录重新生成持仓账本
+func RebuildPositions(c *gin.Context) {
+	count, err := trading.RebuildPositionLedger()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "重建持仓账本失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "持仓账本已重建",
+		"orders":  count,
+	})
+}
+
+// GetPositionNetting 核对各策略的虚拟持仓之和与交易所实际持仓，可按account、symbol筛选
+func GetPositionNetting(c *gin.Context) {
+	var account *string
+	if value, ok := c.GetQuery("account"); ok {
+		account = &value
+	}
+
+	report, err := trading.GetEngine().BuildNettingReport(account, c.Query("symbol"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "生成持仓核对报告失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": report,
+		"total": len(report),
+	})
+}
@@ -0,0 +1,110 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"order_go/internal/report"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPerformanceReport 获取交易绩效报告
+// group_by指定分组方式 (strategy/symbol/time_circle/date)，默认按策略；
+// 可按strategy_id、symbol、time_circle筛选，from和to为日期(2006-01-02)或RFC3339时间，to为日期时包含当天
+func GetPerformanceReport(c *gin.Context) {
+	from, to, err := parseReportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	filter := report.Filter{
+		Symbol:     c.Query("symbol"),
+		TimeCircle: c.Query("time_circle"),
+		From:       from,
+		To:         to,
+	}
+	if value := c.Query("strategy_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "无效的策略ID",
+			})
+			return
+		}
+		filter.StrategyID = uint(id)
+	}
+
+	groupBy := c.DefaultQuery("group_by", report.GroupByStrategy)
+	items, total, err := report.GetPerformance(groupBy, filter)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, report.ErrInvalidGroupBy) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error": "生成绩效报告失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"group_by": groupBy,
+		"items":    items,
+		"summary":  total,
+	})
+}
+
+// GetEquityReport 获取账户总价值快照形成的净值曲线和最大回撤，可按exchange和from、to筛选
+func GetEquityReport(c *gin.Context) {
+	from, to, err := parseReportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	equity, err := report.GetEquity(c.Query("exchange"), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "生成净值报告失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, equity)
+}
+
+// parseReportRange 解析报告的时间范围，未指定时为零值表示不限制
+func parseReportRange(c *gin.Context) (time.Time, time.Time, error) {
+	from, _, err := parseReportTime(c.Query("from"))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("无效的开始时间: %w", err)
+	}
+	to, dateOnly, err := parseReportTime(c.Query("to"))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("无效的结束时间: %w", err)
+	}
+	if dateOnly {
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to, nil
+}
+
+// parseReportTime 解析日期或RFC3339时间，日期按本地时区的零点处理
+func parseReportTime(value string) (time.Time, bool, error) {
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
@@ -2,8 +2,11 @@ package admin
 
 import (
 	"net/http"
+	"order_go/internal/account"
 	"order_go/internal/cache"
+	"order_go/internal/exchange"
 	"order_go/internal/repository"
+	"order_go/internal/trading"
 
 	"github.com/gin-gonic/gin"
 )
@@ -50,4 +53,39 @@ func RefreshAccountValue(c *gin.Context) {
         "accountValue": formattedAccountValue,
         "message": "账户总值已刷新",
     })
+}
+
+// GetAccountValuation 获取账户估值明细，包括无法估值的币种
+// 可通过exchange参数指定交易所代码查看其他账户，默认使用account_exchange配置的交易所
+func GetAccountValuation(c *gin.Context) {
+    var ex exchange.Exchange
+    if code := c.Query("exchange"); code != "" {
+        found, ok := trading.GetEngine().GetExchange(code)
+        if !ok {
+            c.JSON(http.StatusNotFound, gin.H{
+                "error": "交易所未注册: " + code,
+            })
+            return
+        }
+        ex = found
+    } else {
+        found, err := cache.AccountExchange()
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error": "获取交易所失败: " + err.Error(),
+            })
+            return
+        }
+        ex = found
+    }
+    
+    valuation, err := account.Valuate(ex)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{
+            "error": "计算账户估值失败: " + err.Error(),
+        })
+        return
+    }
+    
+    c.JSON(http.StatusOK, valuation)
 }
\ No newline at end of file
@@ -1,11 +1,15 @@
 package admin
 
 import (
+	"fmt"
 	"net/http"
+	"order_go/internal/constants"
+	"order_go/internal/exchange"
 	"order_go/internal/models"
 	"order_go/internal/repository"
 	"order_go/internal/strategy"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -58,6 +62,46 @@ func CreateStrategy(c *gin.Context) {
 		return
 	}
 	
+	// 校验默认订单类型和有效方式
+	if err := normalizeStrategyOrderMode(&stra); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	
+	// 校验限价单定价方式和滑点限制
+	if err := normalizeStrategyPricing(&stra); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	
+	// 校验监控超时后的执行方式
+	if err := normalizeStrategyExecution(&stra); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	
+	// 校验信号过滤参数
+	if err := validateStrategyFilters(&stra); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	
+	// 绑定的账户必须在交易所管理中存在
+	if err := validateStrategyAccount(stra.Account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	
 	// 检查策略代码是否已存在
 	var count int64
 	repository.DB.Model(&models.Strategy{}).Where("code = ?", stra.Code).Count(&count)
@@ -107,6 +151,46 @@ func UpdateStrategy(c *gin.Context) {
 		return
 	}
 	
+	// 校验默认订单类型和有效方式
+	if err := normalizeStrategyOrderMode(&stra); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	
+	// 校验限价单定价方式和滑点限制
+	if err := normalizeStrategyPricing(&stra); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	
+	// 校验监控超时后的执行方式
+	if err := normalizeStrategyExecution(&stra); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	
+	// 校验信号过滤参数
+	if err := validateStrategyFilters(&stra); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	
+	// 绑定的账户必须在交易所管理中存在
+	if err := validateStrategyAccount(stra.Account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	
 	// 检查策略代码是否与其他策略冲突
 	var count int64
 	repository.DB.Model(&models.Strategy{}).Where("code = ? AND id != ?", stra.Code, id).Count(&count)
@@ -165,3 +249,72 @@ func DeleteStrategy(c *gin.Context) {
 		"message": "策略删除成功",
 	})
 }
+
+// normalizeStrategyOrderMode 校验并规范化策略的默认订单类型和有效方式
+func normalizeStrategyOrderMode(stra *models.Strategy) error {
+	orderType, timeInForce, err := exchange.NormalizeOrderMode(stra.OrderType, stra.TimeInForce)
+	if err != nil {
+		return err
+	}
+	stra.OrderType = orderType
+	stra.TimeInForce = timeInForce
+	return nil
+}
+
+// normalizeStrategyPricing 校验并规范化策略的限价单定价方式，未指定时使用信号价格
+func normalizeStrategyPricing(stra *models.Strategy) error {
+	stra.PricePolicy = strings.ToLower(stra.PricePolicy)
+	switch stra.PricePolicy {
+	case "":
+		stra.PricePolicy = constants.PricePolicySignal
+	case constants.PricePolicySignal, constants.PricePolicyBest, constants.PricePolicyMid:
+	default:
+		return fmt.Errorf("不支持的定价方式: %s", stra.PricePolicy)
+	}
+	
+	if stra.MaxSlippage < 0 || stra.MaxSlippage >= 1 {
+		return fmt.Errorf("最大滑点比例必须在0到1之间")
+	}
+	return nil
+}
+
+// normalizeStrategyExecution 校验并规范化策略在订单监控超时后的执行方式，未指定时撤单放弃
+func normalizeStrategyExecution(stra *models.Strategy) error {
+	stra.ExecutionPolicy = strings.ToLower(stra.ExecutionPolicy)
+	switch stra.ExecutionPolicy {
+	case "":
+		stra.ExecutionPolicy = constants.ExecutionPolicyCancel
+	case constants.ExecutionPolicyCancel, constants.ExecutionPolicyChase, constants.ExecutionPolicyMarket:
+	default:
+		return fmt.Errorf("不支持的执行方式: %s", stra.ExecutionPolicy)
+	}
+	
+	if stra.ChaseSteps < 0 {
+		return fmt.Errorf("重新挂单次数不能为负数")
+	}
+	return nil
+}
+
+// validateStrategyFilters 校验策略的信号过滤参数
+func validateStrategyFilters(stra *models.Strategy) error {
+	if stra.TrendMAPeriod < 0 || stra.TrendMAPeriod > strategy.MaxTrendMAPeriod {
+		return fmt.Errorf("均线过滤周期数必须在0到%d之间", strategy.MaxTrendMAPeriod)
+	}
+	return nil
+}
+
+// validateStrategyAccount 校验策略绑定的交易所账户存在，默认账户不需要校验
+func validateStrategyAccount(account string) error {
+	if account == "" {
+		return nil
+	}
+	
+	var count int64
+	if err := repository.DB.Model(&models.Exchange{}).Where("account = ?", account).Count(&count).Error; err != nil {
+		return fmt.Errorf("查询交易所账户失败: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("交易所账户不存在: %s", account)
+	}
+	return nil
+}
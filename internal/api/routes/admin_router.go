@@ -18,10 +18,16 @@ func RegisterAdminRoutes(router *gin.Engine) {
 		// 订单相关路由
 		apiGroup.GET("/orders", admin.GetOrders)
 		apiGroup.GET("/orders/:id", admin.GetOrderByID)
+		apiGroup.GET("/orders/:id/events", admin.GetOrderEvents)
 		
 		// 统计数据路由
 		apiGroup.GET("/stats", admin.GetStats)
 		apiGroup.POST("/refresh-account", admin.RefreshAccountValue)
+		apiGroup.GET("/account/valuation", admin.GetAccountValuation)
+		
+		// 绩效报告路由
+		apiGroup.GET("/reports/performance", admin.GetPerformanceReport)
+		apiGroup.GET("/reports/equity", admin.GetEquityReport)
 		
 		// 交易对管理路由
 		apiGroup.GET("/contract-codes", admin.GetContractCodes)
@@ -30,6 +36,24 @@ func RegisterAdminRoutes(router *gin.Engine) {
 		apiGroup.POST("/contract-codes", admin.CreateContractCode)
 		apiGroup.PUT("/contract-codes/:id", admin.UpdateContractCode)
 		apiGroup.DELETE("/contract-codes/:id", admin.DeleteContractCode)
+		apiGroup.GET("/contract-codes/rules/diff", admin.GetContractRuleDiff)
+		apiGroup.POST("/contract-codes/rules/sync", admin.SyncContractRules)
+		
+		// 持仓账本路由
+		apiGroup.GET("/positions", admin.GetPositions)
+		apiGroup.GET("/positions/strategy/:id", admin.GetStrategyPositions)
+		apiGroup.GET("/positions/netting", admin.GetPositionNetting)
+		apiGroup.POST("/positions/rebuild", admin.RebuildPositions)
+		
+		// 行情记录覆盖情况
+		apiGroup.GET("/market-data/coverage", admin.GetMarketDataCoverage)
+		
+		// 交易所管理路由，修改后立即重新注册到交易引擎
+		apiGroup.GET("/exchanges", admin.GetExchanges)
+		apiGroup.GET("/exchanges/:id", admin.GetExchangeByID)
+		apiGroup.POST("/exchanges", admin.CreateExchange)
+		apiGroup.PUT("/exchanges/:id", admin.UpdateExchange)
+		apiGroup.DELETE("/exchanges/:id", admin.DeleteExchange)
 		
 		// 策略管理路由
 		apiGroup.GET("/strategies", admin.GetStrategies)
@@ -49,6 +73,7 @@ func RegisterAdminRoutes(router *gin.Engine) {
 		// 订单相关路由
 		adminGroup.GET("/orders", admin.GetOrders)
 		adminGroup.GET("/orders/:id", admin.GetOrderByID)
+		adminGroup.GET("/orders/:id/events", admin.GetOrderEvents)
 		
 		// 统计数据路由
 		adminGroup.GET("/stats", admin.GetStats)
@@ -3,7 +3,11 @@ package cache
 import (
 	"fmt"
 	"order_go/internal/account"
+	"order_go/internal/constants"
 	"order_go/internal/exchange"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/trading"
 	"order_go/internal/utils/config"
 	"sync"
 	"time"
@@ -31,7 +35,14 @@ func GetCachedAccountValue() string {
 
 // UpdateAccountValueCache 更新账户总价值缓存
 func UpdateAccountValueCache() {
-	ex := exchange.NewGateIO()
+	ex, err := AccountExchange()
+	if err != nil {
+		config.Logger.Errorw("更新账户总价值缓存失败",
+			"error", err.Error(),
+		)
+		return
+	}
+	
 	accountValue, err := account.GetTotalValue(ex)
 	if err != nil {
 		config.Logger.Errorw("更新账户总价值缓存失败",
@@ -40,6 +51,8 @@ func UpdateAccountValueCache() {
 		return
 	}
 	
+	saveAccountSnapshot(accountValue)
+	
 	formattedValue := fmt.Sprintf("%.2f", accountValue)
 	
 	accountValueCacheMux.Lock()
@@ -53,12 +66,62 @@ func UpdateAccountValueCache() {
 	)
 }
 
+// saveAccountSnapshot 保存账户总价值快照，保存失败不影响缓存更新
+func saveAccountSnapshot(value float64) {
+	snapshot := models.AccountSnapshot{
+		Exchange:   accountExchangeName(),
+		Currency:   account.ReportingCurrency(),
+		TotalValue: value,
+	}
+	if err := repository.DB.Create(&snapshot).Error; err != nil {
+		config.Logger.Errorw("保存账户总价值快照失败",
+			"error", err.Error(),
+		)
+	}
+}
+
+// accountExchangeName 计算账户总价值使用的交易所名称，由account_exchange配置指定，默认为现货交易所
+func accountExchangeName() string {
+	if config.AppConfig.AccountExchange == "" {
+		return constants.ExchangeTypeSpot
+	}
+	return config.AppConfig.AccountExchange
+}
+
+// AccountExchange 获取计算账户总价值使用的交易所，由account_exchange配置指定，默认为现货交易所
+func AccountExchange() (exchange.Exchange, error) {
+	name := accountExchangeName()
+	
+	ex, ok := trading.GetEngine().GetExchange(name)
+	if !ok {
+		return nil, fmt.Errorf("交易所未注册: %s", name)
+	}
+	return ex, nil
+}
+
 // StartAccountValueCacheUpdater 启动账户总价值缓存更新器
 func StartAccountValueCacheUpdater() {
 	// 立即更新一次缓存
 	UpdateAccountValueCache()
 	
-	// 不再启动定期更新任务，改为由用户手动触发更新
+	// 默认不启动定期更新任务，由用户手动触发更新；配置了快照间隔时定期更新并保存快照
+	interval, err := time.ParseDuration(config.AppConfig.Valuation.SnapshotInterval)
+	if config.AppConfig.Valuation.SnapshotInterval == "" || err != nil || interval <= 0 {
+		if config.AppConfig.Valuation.SnapshotInterval != "" {
+			config.Logger.Warnw("账户快照间隔配置无效，不定期保存快照",
+				"snapshot_interval", config.AppConfig.Valuation.SnapshotInterval,
+			)
+		}
+		config.Logger.Info("账户总价值缓存已初始化，后续更新将由用户手动触发")
+		return
+	}
 	
-	config.Logger.Info("账户总价值缓存已初始化，后续更新将由用户手动触发")
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			UpdateAccountValueCache()
+		}
+	}()
+	config.Logger.Infow("账户总价值缓存已初始化，定期更新并保存快照", "interval", interval.String())
 }
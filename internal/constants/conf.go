@@ -14,6 +14,20 @@ const (
     ExchangeTypeFutures  = "futures" // 期货
 )
 
+// 限价单定价方式常量
+const (
+    PricePolicySignal = "signal" // 使用信号中的价格
+    PricePolicyBest   = "best"   // 买入按卖一价、卖出按买一价，再向对手方向偏移若干个最小价格变动单位
+    PricePolicyMid    = "mid"    // 买一价和卖一价的中间价
+)
+
+// 订单监控超时后剩余数量的执行方式常量
+const (
+    ExecutionPolicyCancel = "cancel" // 撤单后放弃剩余数量
+    ExecutionPolicyChase  = "chase"  // 撤单后按最新盘口重新挂单，最多重复若干次
+    ExecutionPolicyMarket = "market" // 撤单后将剩余数量转为市价单
+)
+
 // 获取合约类型名称
 func GetContractTypeName(contractType int) string {
     switch contractType {
@@ -20,6 +20,11 @@ func MigrateDB(db *gorm.DB) {
 		&models.Exchange{},
 		&models.User{},
 		&models.OrderRecord{},
+		&models.OrderEvent{},
+		&models.PositionLedger{},
+		&models.MarketKline{},
+		&models.TickerSnapshot{},
+		&models.AccountSnapshot{},
 	)
 	if err != nil {
 		config.Logger.Errorw("数据库迁移失败", "error", err.Error())
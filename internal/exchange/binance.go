@@ -0,0 +1,173 @@
+package exchange
+
+import (
+	"fmt"
+	"order_go/internal/exchange/binance"
+	"order_go/internal/exchange/types"
+	"order_go/internal/models"
+	"order_go/internal/utils/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewBinance 创建Binance现货交易所实例
+func NewBinance() Exchange {
+	// 从配置中读取API密钥等信息
+	binanceCfg, exists := config.GetExchangeConfig("binance")
+	if !exists {
+		// 如果配置不存在，使用空配置创建客户端
+		binanceCfg = &config.ExchangeConfig{}
+	}
+	return &Binance{client: binance.NewClient(binanceCfg)}
+}
+
+// Binance Binance现货交易所实现
+type Binance struct {
+	client *binance.Client
+}
+
+// GetClient 获取内部的Binance客户端
+func (b *Binance) GetClient() *binance.Client {
+	return b.client
+}
+
+// GetSymbolPrice 获取交易对价格
+func (b *Binance) GetSymbolPrice(symbol string) (float64, error) {
+	return b.client.GetSymbolPrice(symbol)
+}
+
+// GetOrderBook 获取交易对盘口深度
+func (b *Binance) GetOrderBook(symbol string, depth int) (*OrderBook, error) {
+	return b.client.GetOrderBook(symbol, depth)
+}
+
+// GetKlines 获取K线
+func (b *Binance) GetKlines(symbol, interval string, since time.Time, limit int) ([]Kline, error) {
+	return b.client.GetKlines(symbol, interval, since, limit)
+}
+
+// CreateOrder 创建订单
+func (b *Binance) CreateOrder(order *OrderRequest) (*OrderResponse, error) {
+	clientID := binanceClientID(order.ClientID)
+	if clientID == "" {
+		clientID = "t-" + strconv.FormatInt(time.Now().UnixNano(), 10) // 与Gate.io保持一致的客户端ID格式
+	}
+
+	resp, err := b.client.CreateOrder(&types.Order{
+		Symbol:      order.Symbol,
+		Side:        types.OrderSide(order.Side),
+		Type:        types.OrderType(order.Type),
+		Amount:      order.Amount,
+		Price:       order.Price,
+		ClientID:    clientID,
+		TimeInForce: types.TimeInForce(order.TimeInForce),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderResponse{
+		OrderID:     resp.OrderID,
+		Status:      resp.Status,
+		FilledQty:   resp.FilledQty,
+		FilledPrice: resp.FilledPrice,
+		Fee:         resp.Fee,
+		FeeCurrency: resp.FeeCurrency,
+	}, nil
+}
+
+// CancelOrder 取消订单
+func (b *Binance) CancelOrder(symbol, orderID string) error {
+	return b.client.CancelOrder(symbol, orderID)
+}
+
+// GetOrderStatus 获取订单状态
+func (b *Binance) GetOrderStatus(symbol, orderID string) (*OrderResponse, error) {
+	resp, err := b.client.GetOrderStatus(symbol, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderResponse{
+		OrderID:     resp.OrderID,
+		Status:      resp.Status,
+		FilledQty:   resp.FilledQty,
+		FilledPrice: resp.FilledPrice,
+		Fee:         resp.Fee,
+		FeeCurrency: resp.FeeCurrency,
+	}, nil
+}
+
+// FindOrderByClientID 按客户端订单ID查询订单
+func (b *Binance) FindOrderByClientID(symbol, clientID string) (*OrderResponse, error) {
+	resp, err := b.client.GetOrderByClientID(symbol, binanceClientID(clientID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderResponse{
+		OrderID:     resp.OrderID,
+		Status:      resp.Status,
+		FilledQty:   resp.FilledQty,
+		FilledPrice: resp.FilledPrice,
+		Fee:         resp.Fee,
+		FeeCurrency: resp.FeeCurrency,
+	}, nil
+}
+
+// binanceClientID 为系统订单号加上前缀作为客户端订单ID，Binance的newClientOrderId允许字母、数字和-
+func binanceClientID(clientID string) string {
+	if clientID == "" {
+		return ""
+	}
+	return "t-" + clientID
+}
+
+// GetBalance 获取账户余额
+func (b *Binance) GetBalance(currency string) (float64, float64, error) {
+	return b.client.GetBalance(currency)
+}
+
+// GetAllBalances 获取账户中所有有余额的币种
+func (b *Binance) GetAllBalances() ([]Balance, error) {
+	balances, err := b.client.GetAccountBalance("")
+	if err != nil {
+		return nil, err
+	}
+	return balancesFromMap(balances), nil
+}
+
+// GetPosition 获取持仓信息
+// 现货持仓即基础币的总余额
+func (b *Binance) GetPosition(symbol string) (*models.Position, error) {
+	parts := strings.Split(symbol, "_")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("无效的交易对格式: %s", symbol)
+	}
+	asset := parts[0]
+
+	_, total, err := b.client.GetBalance(asset)
+	if err != nil {
+		return nil, fmt.Errorf("获取%s余额失败: %w", asset, err)
+	}
+
+	config.Logger.Infow("当前资产持仓状态",
+		"exchange", "binance",
+		"asset", asset,
+		"total", total,
+	)
+
+	// 如果没有持仓，返回null
+	if total <= 0 {
+		return nil, nil
+	}
+
+	return &models.Position{
+		Symbol:     symbol,
+		Size:       total,
+		EntryPrice: 0,      // 现货没有入场价格概念
+		Leverage:   1,      // 现货没有杠杆概念
+		MarginType: "spot", // 标记为现货
+	}, nil
+}
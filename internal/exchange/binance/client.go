@@ -0,0 +1,519 @@
+package binance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"order_go/internal/exchange/types"
+	"order_go/internal/utils/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 默认的Binance现货API地址
+const defaultBaseURL = "https://api.binance.com"
+
+// recvWindow 签名请求的有效时间窗口（毫秒）
+const recvWindow = "5000"
+
+// APIError Binance接口返回的错误
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+}
+
+// Error 实现error接口
+func (e *APIError) Error() string {
+	return fmt.Sprintf("binance api error: %d - %s", e.Code, e.Message)
+}
+
+// Unwrap 返回错误码对应的错误分类，未识别的错误码返回nil
+func (e *APIError) Unwrap() error {
+	switch e.Code {
+	case -2013, -2011: // 订单不存在、撤单时订单不存在
+		return types.ErrOrderNotFound
+	case -2010:
+		// 下单被拒绝的原因只体现在错误信息中
+		if strings.Contains(e.Message, "insufficient balance") {
+			return types.ErrInsufficientFunds
+		}
+		return types.ErrOrderRejected
+	case -1013:
+		// 过滤器校验失败，NOTIONAL/MIN_NOTIONAL表示金额过小
+		if strings.Contains(e.Message, "NOTIONAL") {
+			return types.ErrMinNotional
+		}
+		return types.ErrInvalidOrder
+	case -1121:
+		return types.ErrInvalidSymbol
+	case -1100, -1101, -1102, -1104, -1106, -1111, -1115, -1116, -1117:
+		return types.ErrInvalidOrder
+	case -1003, -1015:
+		return types.ErrRateLimited
+	case -1001, -1007, -1008:
+		return types.ErrUnavailable
+	case -1002, -1021, -1022, -2014, -2015:
+		return types.ErrAuth
+	default:
+		return nil
+	}
+}
+
+// Client Binance现货客户端
+type Client struct {
+	apiKey     string
+	apiSecret  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient 创建Binance客户端
+func NewClient(cfg *config.ExchangeConfig) *Client {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Client{
+		apiKey:     cfg.ApiKey,
+		apiSecret:  cfg.ApiSecret,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: cfg.RequestTimeout()},
+	}
+}
+
+// ToBinanceSymbol 将系统交易对格式(BTC_USDT)转换为Binance格式(BTCUSDT)
+func ToBinanceSymbol(symbol string) string {
+	return strings.ToUpper(strings.ReplaceAll(symbol, "_", ""))
+}
+
+// sign 使用API Secret对请求参数进行HMAC-SHA256签名
+func (c *Client) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(c.apiSecret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// doRequest 发送请求并将结果解析到out中
+// signed为true时附加时间戳和签名
+func (c *Client) doRequest(method, path string, params url.Values, signed bool, out interface{}) error {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	if signed {
+		params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+		params.Set("recvWindow", recvWindow)
+	}
+
+	query := params.Encode()
+	if signed {
+		query += "&signature=" + c.sign(query)
+	}
+
+	reqURL := c.baseURL + path
+	if query != "" {
+		reqURL += "?" + query
+	}
+
+	req, err := http.NewRequest(method, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-MBX-APIKEY", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求Binance接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取Binance响应失败: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{}
+		if jsonErr := json.Unmarshal(body, apiErr); jsonErr != nil || apiErr.Code == 0 {
+			return &types.HTTPError{Exchange: "binance", StatusCode: resp.StatusCode, Body: string(body)}
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("解析Binance响应失败: %w", err)
+	}
+	return nil
+}
+
+// GetSymbolPrice 获取交易对最新价格
+func (c *Client) GetSymbolPrice(symbol string) (float64, error) {
+	var ticker struct {
+		Symbol string `json:"symbol"`
+		Price  string `json:"price"`
+	}
+
+	params := url.Values{}
+	params.Set("symbol", ToBinanceSymbol(symbol))
+	if err := c.doRequest(http.MethodGet, "/api/v3/ticker/price", params, false, &ticker); err != nil {
+		return 0, err
+	}
+
+	price, err := strconv.ParseFloat(ticker.Price, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析价格失败: %w", err)
+	}
+	return price, nil
+}
+
+// GetOrderBook 获取交易对盘口深度
+func (c *Client) GetOrderBook(symbol string, depth int) (*types.OrderBook, error) {
+	var book struct {
+		Bids [][]string `json:"bids"`
+		Asks [][]string `json:"asks"`
+	}
+
+	params := url.Values{}
+	params.Set("symbol", ToBinanceSymbol(symbol))
+	params.Set("limit", strconv.Itoa(depth))
+	if err := c.doRequest(http.MethodGet, "/api/v3/depth", params, false, &book); err != nil {
+		return nil, err
+	}
+
+	bids, err := parseLevels(book.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseLevels(book.Asks)
+	if err != nil {
+		return nil, err
+	}
+	return &types.OrderBook{Symbol: symbol, Bids: bids, Asks: asks, Time: time.Now()}, nil
+}
+
+// parseLevels 解析盘口档位，每档为[价格, 数量]
+func parseLevels(raw [][]string) ([]types.PriceLevel, error) {
+	levels := make([]types.PriceLevel, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 2 {
+			continue
+		}
+		price, err := strconv.ParseFloat(item[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("解析盘口价格失败: %w", err)
+		}
+		amount, err := strconv.ParseFloat(item[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("解析盘口数量失败: %w", err)
+		}
+		levels = append(levels, types.PriceLevel{Price: price, Amount: amount})
+	}
+	return levels, nil
+}
+
+// maxKlineLimit Binance单次最多返回的K线数量
+const maxKlineLimit = 1000
+
+// GetKlines 获取K线，按开盘时间从早到晚排列；since为零值时返回最近limit根，否则从since开始
+// 统一的K线周期与Binance的周期参数相同
+func (c *Client) GetKlines(symbol, interval string, since time.Time, limit int) ([]types.Kline, error) {
+	if _, ok := types.IntervalDuration(interval); !ok {
+		return nil, fmt.Errorf("不支持的K线周期: %s", interval)
+	}
+	if limit <= 0 || limit > maxKlineLimit {
+		limit = maxKlineLimit
+	}
+
+	// 每根K线为[开盘时间(毫秒), 开盘价, 最高价, 最低价, 收盘价, 成交量, 收盘时间, 成交额, ...]
+	var rows [][]interface{}
+	params := url.Values{}
+	params.Set("symbol", ToBinanceSymbol(symbol))
+	params.Set("interval", interval)
+	params.Set("limit", strconv.Itoa(limit))
+	if !since.IsZero() {
+		params.Set("startTime", strconv.FormatInt(since.UnixMilli(), 10))
+	}
+	if err := c.doRequest(http.MethodGet, "/api/v3/klines", params, false, &rows); err != nil {
+		return nil, err
+	}
+
+	klines := make([]types.Kline, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 8 {
+			continue
+		}
+		openTime, ok := row[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("解析K线时间失败: %v", row[0])
+		}
+		values := make([]float64, 0, 6)
+		for _, i := range []int{1, 2, 3, 4, 5, 7} {
+			s, _ := row[i].(string)
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("解析K线失败: %w", err)
+			}
+			values = append(values, v)
+		}
+		klines = append(klines, types.NewKline(interval, time.UnixMilli(int64(openTime)), values[0], values[1], values[2], values[3], values[4], values[5]))
+	}
+	return klines, nil
+}
+
+// binanceOrder Binance订单结构
+type binanceOrder struct {
+	Symbol              string `json:"symbol"`
+	OrderID             int64  `json:"orderId"`
+	ClientOrderID       string `json:"clientOrderId"`
+	Price               string `json:"price"`
+	OrigQty             string `json:"origQty"`
+	ExecutedQty         string `json:"executedQty"`
+	CummulativeQuoteQty string `json:"cummulativeQuoteQty"`
+	Status              string `json:"status"`
+	Type                string `json:"type"`
+	Side                string `json:"side"`
+}
+
+// binanceTrade Binance成交明细
+type binanceTrade struct {
+	Price           string `json:"price"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+}
+
+// CreateOrder 创建订单，支持市价、限价及IOC/FOK/只做maker
+func (c *Client) CreateOrder(order *types.Order) (*types.OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", ToBinanceSymbol(order.Symbol))
+	params.Set("side", strings.ToUpper(string(order.Side)))
+	params.Set("quantity", strconv.FormatFloat(order.Amount, 'f', -1, 64))
+
+	switch {
+	case order.Type == types.OrderTypeMarket:
+		// 市价单按基础币数量成交，不传价格
+		params.Set("type", "MARKET")
+	case order.TimeInForce == types.TimeInForcePOC:
+		// 只做maker使用LIMIT_MAKER类型，不支持timeInForce参数
+		params.Set("type", "LIMIT_MAKER")
+		params.Set("price", strconv.FormatFloat(order.Price, 'f', -1, 64))
+	default:
+		timeInForce := strings.ToUpper(string(order.TimeInForce))
+		if timeInForce == "" {
+			timeInForce = "GTC"
+		}
+		params.Set("type", "LIMIT")
+		params.Set("timeInForce", timeInForce)
+		params.Set("price", strconv.FormatFloat(order.Price, 'f', -1, 64))
+	}
+	if order.ClientID != "" {
+		params.Set("newClientOrderId", order.ClientID)
+	}
+
+	var result binanceOrder
+	if err := c.doRequest(http.MethodPost, "/api/v3/order", params, true, &result); err != nil {
+		return nil, err
+	}
+
+	filledQty, _ := strconv.ParseFloat(result.ExecutedQty, 64)
+
+	return &types.OrderResponse{
+		OrderID:   strconv.FormatInt(result.OrderID, 10),
+		Status:    mapOrderStatus(result.Status),
+		FilledQty: filledQty,
+	}, nil
+}
+
+// CancelOrder 取消订单
+func (c *Client) CancelOrder(symbol, orderID string) error {
+	params := url.Values{}
+	params.Set("symbol", ToBinanceSymbol(symbol))
+	params.Set("orderId", orderID)
+	return c.doRequest(http.MethodDelete, "/api/v3/order", params, true, nil)
+}
+
+// GetOrderStatus 获取订单状态
+func (c *Client) GetOrderStatus(symbol, orderID string) (*types.OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", ToBinanceSymbol(symbol))
+	params.Set("orderId", orderID)
+	return c.getOrder(symbol, params)
+}
+
+// GetOrderByClientID 按下单时的newClientOrderId查询订单
+func (c *Client) GetOrderByClientID(symbol, clientID string) (*types.OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", ToBinanceSymbol(symbol))
+	params.Set("origClientOrderId", clientID)
+	return c.getOrder(symbol, params)
+}
+
+// getOrder 查询订单并汇总手续费
+func (c *Client) getOrder(symbol string, params url.Values) (*types.OrderResponse, error) {
+	var order binanceOrder
+	if err := c.doRequest(http.MethodGet, "/api/v3/order", params, true, &order); err != nil {
+		return nil, err
+	}
+
+	filledQty, _ := strconv.ParseFloat(order.ExecutedQty, 64)
+	quoteQty, _ := strconv.ParseFloat(order.CummulativeQuoteQty, 64)
+
+	// Binance订单不直接返回成交均价，用成交额/成交量计算
+	filledPrice := 0.0
+	if filledQty > 0 {
+		filledPrice = quoteQty / filledQty
+	}
+
+	resp := &types.OrderResponse{
+		OrderID:     strconv.FormatInt(order.OrderID, 10),
+		Status:      mapOrderStatus(order.Status),
+		FilledQty:   filledQty,
+		FilledPrice: filledPrice,
+	}
+
+	// 订单接口不返回手续费，需要从成交明细中汇总
+	if filledQty > 0 {
+		fees, feeCurrency, err := c.getOrderFee(symbol, resp.OrderID)
+		if err != nil {
+			config.Logger.Warnw("获取Binance订单手续费失败",
+				"order_id", resp.OrderID,
+				"error", err.Error(),
+			)
+		} else {
+			resp.Fee = fees[feeCurrency]
+			resp.FeeCurrency = feeCurrency
+			resp.Fees = fees
+		}
+	}
+
+	return resp, nil
+}
+
+// getOrderFee 按币种汇总订单成交明细中的手续费，返回各币种的手续费和记入订单的手续费币种
+// 不同成交可能用不同币种支付手续费（例如BNB抵扣用完后改用基础币），不同币种不能相加。
+// 基础币和计价币的手续费会改变实际到账数量或成交额，持仓账本需要据此计算，优先记入订单；
+// 只用其他币种支付时记入按计价币价值最大的币种
+func (c *Client) getOrderFee(symbol, orderID string) (map[string]float64, string, error) {
+	params := url.Values{}
+	params.Set("symbol", ToBinanceSymbol(symbol))
+	params.Set("orderId", orderID)
+
+	var trades []binanceTrade
+	if err := c.doRequest(http.MethodGet, "/api/v3/myTrades", params, true, &trades); err != nil {
+		return nil, "", err
+	}
+
+	base, quote, _ := strings.Cut(symbol, "_")
+	fees := make(map[string]float64)
+	var assets []string
+	for _, trade := range trades {
+		commission, _ := strconv.ParseFloat(trade.Commission, 64)
+		if _, ok := fees[trade.CommissionAsset]; !ok {
+			assets = append(assets, trade.CommissionAsset)
+		}
+		fees[trade.CommissionAsset] += commission
+	}
+
+	feeCurrency := ""
+	switch {
+	case fees[base] > 0:
+		feeCurrency = base
+	case fees[quote] > 0:
+		feeCurrency = quote
+	default:
+		// 其他币种按该币种对计价币的最新价估值，获取失败时估值为0
+		maxValue := -1.0
+		for _, asset := range assets {
+			value := 0.0
+			if price, err := c.GetSymbolPrice(asset + "_" + quote); err == nil {
+				value = fees[asset] * price
+			}
+			if value > maxValue {
+				feeCurrency, maxValue = asset, value
+			}
+		}
+	}
+
+	if len(assets) > 1 {
+		config.Logger.Warnw("Binance订单手续费使用了多个币种，订单只记录其中一个币种",
+			"symbol", symbol,
+			"order_id", orderID,
+			"fees", fees,
+			"fee_currency", feeCurrency,
+		)
+	}
+	return fees, feeCurrency, nil
+}
+
+// mapOrderStatus 将Binance订单状态映射为系统统一的订单状态
+func mapOrderStatus(status string) string {
+	switch status {
+	case "NEW":
+		return "open"
+	case "PARTIALLY_FILLED":
+		return "partially_filled"
+	case "FILLED":
+		return "filled"
+	case "CANCELED", "EXPIRED", "EXPIRED_IN_MATCH", "REJECTED":
+		return "canceled"
+	default:
+		return strings.ToLower(status)
+	}
+}
+
+// GetAccountBalance 获取账户余额
+// 返回格式与Gate.io客户端一致：币种.available / 币种.locked / 币种.total
+func (c *Client) GetAccountBalance(currency string) (map[string]float64, error) {
+	var account struct {
+		Balances []struct {
+			Asset  string `json:"asset"`
+			Free   string `json:"free"`
+			Locked string `json:"locked"`
+		} `json:"balances"`
+	}
+
+	params := url.Values{}
+	params.Set("omitZeroBalances", "true")
+	if err := c.doRequest(http.MethodGet, "/api/v3/account", params, true, &account); err != nil {
+		return nil, fmt.Errorf("获取Binance账户余额失败: %w", err)
+	}
+
+	balances := make(map[string]float64)
+	for _, balance := range account.Balances {
+		if currency != "" && balance.Asset != currency {
+			continue
+		}
+
+		available, _ := strconv.ParseFloat(balance.Free, 64)
+		locked, _ := strconv.ParseFloat(balance.Locked, 64)
+
+		// 只返回有余额的币种
+		if available > 0 || locked > 0 {
+			balances[balance.Asset+".available"] = available
+			balances[balance.Asset+".locked"] = locked
+			balances[balance.Asset+".total"] = available + locked
+		}
+	}
+
+	return balances, nil
+}
+
+// GetBalance 获取指定币种的可用余额和总余额
+func (c *Client) GetBalance(currency string) (float64, float64, error) {
+	balances, err := c.GetAccountBalance(currency)
+	if err != nil {
+		return 0, 0, err
+	}
+	return balances[currency+".available"], balances[currency+".total"], nil
+}
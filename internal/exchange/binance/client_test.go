@@ -0,0 +1,82 @@
+package binance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order_go/internal/utils/config"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	config.Logger = zap.NewNop().Sugar()
+	os.Exit(m.Run())
+}
+
+// newTestClient 启动返回固定订单和成交明细的Binance接口，BNB对USDT的价格为600
+func newTestClient(t *testing.T, trades []binanceTrade) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/order", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(binanceOrder{
+			Symbol:              "BTCUSDT",
+			OrderID:             42,
+			ExecutedQty:         "0.02",
+			CummulativeQuoteQty: "1200",
+			Status:              "FILLED",
+			Side:                "BUY",
+		})
+	})
+	mux.HandleFunc("/api/v3/myTrades", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(trades)
+	})
+	mux.HandleFunc("/api/v3/ticker/price", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"symbol": r.URL.Query().Get("symbol"), "price": "600"})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return NewClient(&config.ExchangeConfig{ApiKey: "key", ApiSecret: "secret", BaseURL: srv.URL})
+}
+
+// TestOrderFeeMixedAssets BNB抵扣用完后改用基础币支付，订单记录基础币手续费，各币种手续费都返回
+func TestOrderFeeMixedAssets(t *testing.T) {
+	c := newTestClient(t, []binanceTrade{
+		{Price: "60000", Commission: "0.01", CommissionAsset: "BNB"},
+		{Price: "60000", Commission: "0.00001", CommissionAsset: "BTC"},
+	})
+
+	resp, err := c.GetOrderStatus("BTC_USDT", "42")
+	if err != nil {
+		t.Fatalf("查询订单失败: %v", err)
+	}
+	// BNB手续费价值6 USDT，高于基础币手续费的0.6 USDT，但只有基础币手续费会减少到账数量
+	if resp.FeeCurrency != "BTC" || resp.Fee != 0.00001 {
+		t.Fatalf("应记录基础币手续费, got %v %s", resp.Fee, resp.FeeCurrency)
+	}
+	if resp.Fees["BNB"] != 0.01 || resp.Fees["BTC"] != 0.00001 || len(resp.Fees) != 2 {
+		t.Fatalf("应返回各币种的手续费, got %v", resp.Fees)
+	}
+}
+
+// TestOrderFeeOtherAsset 只用BNB支付手续费时记录BNB
+func TestOrderFeeOtherAsset(t *testing.T) {
+	c := newTestClient(t, []binanceTrade{
+		{Price: "60000", Commission: "0.004", CommissionAsset: "BNB"},
+		{Price: "60000", Commission: "0.006", CommissionAsset: "BNB"},
+	})
+
+	resp, err := c.GetOrderStatus("BTC_USDT", "42")
+	if err != nil {
+		t.Fatalf("查询订单失败: %v", err)
+	}
+	if resp.FeeCurrency != "BNB" || resp.Fee != 0.01 {
+		t.Fatalf("应记录BNB手续费, got %v %s", resp.Fee, resp.FeeCurrency)
+	}
+	if resp.FilledQty != 0.02 || resp.FilledPrice != 60000 {
+		t.Fatalf("成交数量或均价错误, got %v %v", resp.FilledQty, resp.FilledPrice)
+	}
+}
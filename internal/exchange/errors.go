@@ -0,0 +1,44 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"order_go/internal/exchange/types"
+)
+
+// 交易所错误分类，适配器返回的错误均可用errors.Is与以下错误比较
+var (
+	ErrOrderNotFound     = types.ErrOrderNotFound
+	ErrOrderClosed       = types.ErrOrderClosed
+	ErrOrderRejected     = types.ErrOrderRejected
+	ErrInsufficientFunds = types.ErrInsufficientFunds
+	ErrInvalidSymbol     = types.ErrInvalidSymbol
+	ErrMinNotional       = types.ErrMinNotional
+	ErrInvalidOrder      = types.ErrInvalidOrder
+	ErrRateLimited       = types.ErrRateLimited
+	ErrUnavailable       = types.ErrUnavailable
+	ErrAuth              = types.ErrAuth
+)
+
+// IsOrderOutcomeUnknown 下单返回错误时，判断订单是否仍可能已在交易所创建
+// 只有请求超时、连接中断、交易所5xx和服务繁忙类错误无法确认结果，需要按客户端订单ID查询；
+// 交易所返回了明确错误标签或本地校验失败时，订单一定没有创建
+func IsOrderOutcomeUnknown(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrUnavailable) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var httpErr *types.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500
+	}
+
+	// 请求已发出但连接中断或读取响应失败
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
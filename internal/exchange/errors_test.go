@@ -0,0 +1,39 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"order_go/internal/exchange/gateio"
+	"order_go/internal/exchange/types"
+	"testing"
+)
+
+// TestIsOrderOutcomeUnknown 只有传输错误、超时、5xx和服务繁忙时下单结果不确定
+func TestIsOrderOutcomeUnknown(t *testing.T) {
+	timeout := &url.Error{Op: "Post", URL: "https://api.gateio.ws", Err: context.DeadlineExceeded}
+	reset := &url.Error{Op: "Post", URL: "https://api.gateio.ws", Err: &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}}
+
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"超时", fmt.Errorf("创建订单失败: %w", timeout), true},
+		{"连接中断", fmt.Errorf("请求OKX接口失败: %w", reset), true},
+		{"5xx", &types.HTTPError{Exchange: "gate", StatusCode: 502}, true},
+		{"服务繁忙", &gateio.APIError{Label: "TOO_BUSY"}, true},
+		{"4xx", &types.HTTPError{Exchange: "gate", StatusCode: 404}, false},
+		{"限流", &gateio.APIError{Label: "TOO_MANY_REQUESTS"}, false},
+		{"余额不足", &gateio.APIError{Label: "BALANCE_NOT_ENOUGH"}, false},
+		{"未识别的标签", &gateio.APIError{Label: "INVALID_TEXT"}, false},
+		{"本地校验失败", errors.New("下单数量必须大于0"), false},
+	}
+	for _, c := range cases {
+		if got := IsOrderOutcomeUnknown(c.err); got != c.want {
+			t.Errorf("%s: IsOrderOutcomeUnknown(%v) = %v, want %v", c.name, c.err, got, c.want)
+		}
+	}
+}
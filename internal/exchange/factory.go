@@ -0,0 +1,70 @@
+package exchange
+
+import (
+	"fmt"
+	"order_go/internal/constants"
+	"order_go/internal/exchange/binance"
+	"order_go/internal/exchange/okx"
+	"order_go/internal/utils/config"
+)
+
+// 交易所适配器类型，对应exchanges表的type字段
+const (
+	KindGateIO        = "gateio"
+	KindGateIOFutures = "gateio_futures"
+	KindBinance       = "binance"
+	KindOKX           = "okx"
+	KindPaper         = "paper"
+)
+
+// IsSupportedKind 判断是否为支持的适配器类型
+func IsSupportedKind(kind string) bool {
+	switch kind {
+	case KindGateIO, KindGateIOFutures, KindBinance, KindOKX, KindPaper:
+		return true
+	}
+	return false
+}
+
+// MarketOf 返回适配器类型对应的市场类型，目前只有Gate.io永续合约属于futures
+func MarketOf(kind string) string {
+	if kind == KindGateIOFutures {
+		return constants.ExchangeTypeFutures
+	}
+	return constants.ExchangeTypeSpot
+}
+
+// LookupConfig 查找交易所账户使用的配置
+// 依次按交易所代码、适配器类型查找，Gate.io永续合约最后复用gateio配置；都不存在时返回空配置
+func LookupConfig(code, kind string) *config.ExchangeConfig {
+	if cfg, ok := config.GetExchangeConfig(code); ok {
+		return cfg
+	}
+	if cfg, ok := config.GetExchangeConfig(kind); ok {
+		return cfg
+	}
+	if kind == KindGateIOFutures {
+		if cfg, ok := config.GetExchangeConfig(KindGateIO); ok {
+			return cfg
+		}
+	}
+	return &config.ExchangeConfig{}
+}
+
+// New 按适配器类型和配置创建交易所实例，返回的实例未经过限流装饰
+func New(kind string, cfg *config.ExchangeConfig) (Exchange, error) {
+	switch kind {
+	case KindGateIO:
+		return newGateIO(cfg, "spot"), nil
+	case KindGateIOFutures:
+		return newGateIO(cfg, "futures"), nil
+	case KindBinance:
+		return &Binance{client: binance.NewClient(cfg)}, nil
+	case KindOKX:
+		return &OKX{client: okx.NewClient(cfg)}, nil
+	case KindPaper:
+		return newPaper(cfg), nil
+	default:
+		return nil, fmt.Errorf("不支持的交易所类型: %s", kind)
+	}
+}
@@ -3,9 +3,13 @@ package gateio
 import (
 	"context"
 	"fmt"
+	"math"
+	"net/http"
 	"order_go/internal/exchange/types"
 	"order_go/internal/utils/config"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/antihax/optional"
 	"github.com/gateio/gateapi-go/v6"
@@ -14,12 +18,23 @@ import (
 type Client struct {
 	client      *gateapi.APIClient
 	ctx         context.Context
-	accountType string // 账户类型：spot(现货)
+	accountType string // 账户类型：spot(现货)、futures(永续合约)
+	settle      string // 永续合约结算币种，仅futures账户使用
+
+	// 永续合约乘数缓存，合约名 -> 每张合约对应的基础币数量
+	multipliers   map[string]float64
+	multipliersMu sync.RWMutex
+
+	// 现货交易对价格精度缓存，交易对 -> 价格（计价币）小数位数
+	precisions   map[string]int
+	precisionsMu sync.RWMutex
 }
 
 // NewClient 创建Gate.io客户端
 func NewClient(cfg *config.ExchangeConfig) *Client {
 	configuration := gateapi.NewConfiguration()
+	// 默认的HTTP客户端没有超时，请求无响应时会一直阻塞
+	configuration.HTTPClient = &http.Client{Timeout: cfg.RequestTimeout()}
 	
 	// 创建API客户端
 	client := gateapi.NewAPIClient(configuration)
@@ -39,16 +54,25 @@ func NewClient(cfg *config.ExchangeConfig) *Client {
 		},
 	)
 	
-	// 设置账户类型，默认为spot(现货)
+	// 设置账户类型，支持spot(现货)和futures(永续合约)，默认为spot
 	accountType := cfg.AccountType
-	if accountType == "" || accountType != "spot" {
+	if accountType != "futures" {
 		accountType = "spot"
 	}
 	
+	// 永续合约默认使用USDT结算
+	settle := strings.ToLower(cfg.Settle)
+	if settle == "" {
+		settle = "usdt"
+	}
+	
 	return &Client{
 		client:      client,
 		ctx:         ctx,
 		accountType: accountType,
+		settle:      settle,
+		multipliers: make(map[string]float64),
+		precisions:  make(map[string]int),
 	}
 }
 
@@ -57,8 +81,18 @@ func (c *Client) GetAccountType() string {
 	return c.accountType
 }
 
+// IsFutures 当前客户端是否为永续合约账户
+func (c *Client) IsFutures() bool {
+	return c.accountType == "futures"
+}
+
 // GetPositionDetail 获取资产详细信息，包括可用余额、总余额、锁定余额
 func (c *Client) GetPositionDetail(currency string) (float64, float64, float64, error) {
+	// 永续合约账户只有结算币种的保证金余额
+	if c.IsFutures() {
+		return c.getFuturesAccountDetail(currency)
+	}
+	
 	// 调用Gate.io API获取账户余额
 	balances, _, err := c.client.SpotApi.ListSpotAccounts(c.ctx, &gateapi.ListSpotAccountsOpts{
 		Currency: optional.NewString(currency),
@@ -106,6 +140,12 @@ func (c *Client) GetPositionDetail(currency string) (float64, float64, float64,
 
 // GetBalance 获取资产余额
 func (c *Client) GetBalance(currency string) (float64, float64, error) {
+	// 永续合约账户只有结算币种的保证金余额
+	if c.IsFutures() {
+		available, total, _, err := c.getFuturesAccountDetail(currency)
+		return available, total, err
+	}
+	
 	// 调用Gate.io API获取账户余额
 	balances, _, err := c.client.SpotApi.ListSpotAccounts(c.ctx, &gateapi.ListSpotAccountsOpts{
 		Currency: optional.NewString(currency),
@@ -153,6 +193,11 @@ func (c *Client) GetBalance(currency string) (float64, float64, error) {
 
 // GetSymbolPrice 获取交易对价格
 func (c *Client) GetSymbolPrice(symbol string) (float64, error) {
+	// 永续合约使用合约行情
+	if c.IsFutures() {
+		return c.getFuturesPrice(symbol)
+	}
+	
 	// 使用ListTickers API获取价格
 	opts := &gateapi.ListTickersOpts{
 		CurrencyPair: optional.NewString(symbol),
@@ -160,10 +205,7 @@ func (c *Client) GetSymbolPrice(symbol string) (float64, error) {
 	
 	tickers, _, err := c.client.SpotApi.ListTickers(c.ctx, opts)
 	if err != nil {
-		if e, ok := err.(gateapi.GateAPIError); ok {
-			return 0, fmt.Errorf("gate api error: %s - %s", e.Label, e.Message)
-		}
-		return 0, fmt.Errorf("获取价格失败: %w", err)
+		return 0, wrapError(err, "获取价格失败")
 	}
 	
 	if len(tickers) == 0 {
@@ -178,14 +220,48 @@ func (c *Client) GetSymbolPrice(symbol string) (float64, error) {
 	return price, nil
 }
 
+// GetAllPrices 一次获取所有交易对的最新价，永续合约账户返回合约行情
+func (c *Client) GetAllPrices() (map[string]float64, error) {
+	prices := make(map[string]float64)
+	
+	if c.IsFutures() {
+		tickers, _, err := c.client.FuturesApi.ListFuturesTickers(c.ctx, c.settle, nil)
+		if err != nil {
+			return nil, wrapError(err, "获取合约行情失败")
+		}
+		for _, ticker := range tickers {
+			if price, err := strconv.ParseFloat(ticker.Last, 64); err == nil && price > 0 {
+				prices[ticker.Contract] = price
+			}
+		}
+		return prices, nil
+	}
+	
+	tickers, _, err := c.client.SpotApi.ListTickers(c.ctx, nil)
+	if err != nil {
+		return nil, wrapError(err, "获取行情失败")
+	}
+	for _, ticker := range tickers {
+		if price, err := strconv.ParseFloat(ticker.Last, 64); err == nil && price > 0 {
+			prices[ticker.CurrencyPair] = price
+		}
+	}
+	return prices, nil
+}
+
 // CreateOrder 创建订单
 func (c *Client) CreateOrder(order *types.Order) (*types.OrderResponse, error) {
-	// 只支持现货账户
+	// 根据账户类型选择现货或永续合约接口
+	if c.IsFutures() {
+		return c.createFuturesOrder(order)
+	}
 	return c.createSpotOrder(order)
 }
 
 // createSpotOrder 创建现货订单
 func (c *Client) createSpotOrder(order *types.Order) (*types.OrderResponse, error) {
+	orderType, timeInForce := spotOrderMode(order)
+	
 	// 构建订单请求
 	req := gateapi.Order{
 		Text:         order.ClientID,                    // 客户端订单ID
@@ -193,31 +269,87 @@ func (c *Client) createSpotOrder(order *types.Order) (*types.OrderResponse, erro
 		Side:         string(order.Side),                // buy 或 sell
 		Amount:       fmt.Sprintf("%.8f", order.Amount), // 数量
 		Price:        fmt.Sprintf("%.8f", order.Price),  // 价格
-		Type:         "limit",                           // 限价单
+		Type:         orderType,                         // 订单类型
+		TimeInForce:  timeInForce,                       // 有效方式
+	}
+	
+	// 市价单不传价格；市价买单的数量为计价币金额，需按最新价换算
+	if orderType == string(types.OrderTypeMarket) {
+		req.Price = ""
+		if order.Side == types.OrderSideBuy {
+			price, err := c.GetSymbolPrice(order.Symbol)
+			if err != nil || price <= 0 {
+				// 获取最新价失败时使用下单参考价
+				price = order.Price
+			}
+			// 计价币金额的小数位数不能超过交易对的价格精度，向下取整避免超出可用余额
+			precision, err := c.quotePrecision(order.Symbol)
+			if err != nil {
+				return nil, err
+			}
+			factor := math.Pow10(precision)
+			quoteAmount := math.Floor(order.Amount*price*factor+1e-6) / factor
+			req.Amount = strconv.FormatFloat(quoteAmount, 'f', precision, 64)
+		}
 	}
 
 	// 创建订单，不需要额外的可选参数
 	result, _, err := c.client.SpotApi.CreateOrder(c.ctx, req, nil)
 	if err != nil {
-		if e, ok := err.(gateapi.GateAPIError); ok {
-			return nil, fmt.Errorf("gate api error: %s - %s", e.Label, e.Message)
-		}
-		return nil, fmt.Errorf("创建订单失败: %w", err)
+		return nil, wrapError(err, "创建订单失败")
 	}
 
-	// 解析已成交数量
-	filledAmount, _ := strconv.ParseFloat(result.FilledTotal, 64)
+	// 市价单和IOC/FOK订单在响应中可能已经成交，按查询订单的方式解析成交数量和状态
+	return spotOrderResponse(&result), nil
+}
 
-	return &types.OrderResponse{
-		OrderID:   result.Id,
-		Status:    result.Status,
-		FilledQty: filledAmount,
-	}, nil
+// quotePrecision 获取现货交易对的价格精度，也是市价买单计价币金额允许的小数位数
+func (c *Client) quotePrecision(pair string) (int, error) {
+	c.precisionsMu.RLock()
+	precision, ok := c.precisions[pair]
+	c.precisionsMu.RUnlock()
+	if ok {
+		return precision, nil
+	}
+
+	info, _, err := c.client.SpotApi.GetCurrencyPair(c.ctx, pair)
+	if err != nil {
+		return 0, wrapError(err, fmt.Sprintf("获取交易对%s信息失败", pair))
+	}
+	precision = int(info.Precision)
+
+	c.precisionsMu.Lock()
+	c.precisions[pair] = precision
+	c.precisionsMu.Unlock()
+
+	return precision, nil
+}
+
+// spotOrderMode 获取现货订单类型和有效方式
+// Gate.io市价单只支持ioc和fok，未指定时使用ioc
+func spotOrderMode(order *types.Order) (string, string) {
+	orderType := string(order.Type)
+	if orderType == "" {
+		orderType = string(types.OrderTypeLimit)
+	}
+	
+	timeInForce := string(order.TimeInForce)
+	if timeInForce == "" {
+		timeInForce = string(types.TimeInForceGTC)
+	}
+	if orderType == string(types.OrderTypeMarket) && timeInForce != string(types.TimeInForceFOK) {
+		timeInForce = string(types.TimeInForceIOC)
+	}
+	
+	return orderType, timeInForce
 }
 
 // CancelOrder 取消订单
 func (c *Client) CancelOrder(symbol, orderID string) error {
-	// 只支持现货账户
+	// 根据账户类型选择现货或永续合约接口
+	if c.IsFutures() {
+		return c.cancelFuturesOrder(symbol, orderID)
+	}
 	return c.cancelSpotOrder(symbol, orderID)
 }
 
@@ -226,38 +358,51 @@ func (c *Client) cancelSpotOrder(symbol, orderID string) error {
 	// 取消订单，不需要额外的可选参数
 	_, _, err := c.client.SpotApi.CancelOrder(c.ctx, orderID, symbol, nil)
 	if err != nil {
-		if e, ok := err.(gateapi.GateAPIError); ok {
-			return fmt.Errorf("gate api error: %s - %s", e.Label, e.Message)
-		}
-		return fmt.Errorf("取消订单失败: %w", err)
+		return wrapError(err, "取消订单失败")
 	}
 	return nil
 }
 
 // GetOrderStatus 获取订单状态
 func (c *Client) GetOrderStatus(symbol, orderID string) (*types.OrderResponse, error) {
-	// 只支持现货账户
+	// 根据账户类型选择现货或永续合约接口
+	if c.IsFutures() {
+		return c.getFuturesOrderStatus(symbol, orderID)
+	}
 	return c.getSpotOrderStatus(symbol, orderID)
 }
 
+// GetOrderByClientID 按自定义订单ID(text)查询订单
+// Gate.io的订单查询接口同时接受订单ID和text，但text只能查询挂单中或刚结束不久的订单
+func (c *Client) GetOrderByClientID(symbol, text string) (*types.OrderResponse, error) {
+	return c.GetOrderStatus(symbol, text)
+}
+
 // getSpotOrderStatus 获取现货订单状态
 func (c *Client) getSpotOrderStatus(symbol, orderID string) (*types.OrderResponse, error) {
 	// 获取订单状态，不需要额外的可选参数
 	order, _, err := c.client.SpotApi.GetOrder(c.ctx, orderID, symbol, nil)
 	if err != nil {
-		if e, ok := err.(gateapi.GateAPIError); ok {
-			return nil, fmt.Errorf("gate api error: %s - %s", e.Label, e.Message)
-		}
-		return nil, fmt.Errorf("获取订单状态失败: %w", err)
+		return nil, wrapError(err, "获取订单状态失败")
 	}
 
 	// 不在此处输出订单信息日志，避免日志过多
+	return spotOrderResponse(&order), nil
+}
 
+// spotOrderResponse 将Gate.io现货订单转换为统一的订单响应
+// 成交数量使用基础币的filled_amount，filled_total是计价币成交额，市价买单的amount和left也是计价币金额
+func spotOrderResponse(order *gateapi.Order) *types.OrderResponse {
 	// 获取成交数量（直接使用Gate.io API提供的FilledAmount字段）
 	filledQty, _ := strconv.ParseFloat(order.FilledAmount, 64)
 	
 	// 获取成交均价
 	filledPrice, _ := strconv.ParseFloat(order.AvgDealPrice, 64)
+	if filledPrice == 0 && filledQty > 0 {
+		// 没有成交均价时按成交额折算
+		filledTotal, _ := strconv.ParseFloat(order.FilledTotal, 64)
+		filledPrice = filledTotal / filledQty
+	}
 	if filledPrice == 0 && filledQty > 0 {
 		// 如果没有成交均价但有成交数量，尝试使用下单价格
 		filledPrice, _ = strconv.ParseFloat(order.Price, 64)
@@ -268,13 +413,17 @@ func (c *Client) getSpotOrderStatus(symbol, orderID string) (*types.OrderRespons
 	
 	// 正确映射订单状态
 	status := order.Status
-	if status == "closed" {
-		// Gate.io的closed状态需要根据实际情况映射
-		if filledQty > 0 {
+	switch status {
+	case "closed":
+		// Gate.io的closed状态需要根据实际情况映射，IOC等订单未全部成交时finish_as不是filled
+		if filledQty > 0 && (order.FinishAs == "" || order.FinishAs == "filled") {
 			status = "filled"
 		} else {
 			status = "canceled"
 		}
+	case "cancelled":
+		// 部分成交后撤销的订单由订单状态机记为部分成交
+		status = "canceled"
 	}
 	
 	return &types.OrderResponse{
@@ -284,22 +433,26 @@ func (c *Client) getSpotOrderStatus(symbol, orderID string) (*types.OrderRespons
 		FilledPrice:  filledPrice,
 		Fee:          fee,
 		FeeCurrency:  order.FeeCurrency,
-	}, nil
+	}
 }
 
 // GetAccountBalance 获取账户余额
 func (c *Client) GetAccountBalance(currency string) (map[string]float64, error) {
-	// 只支持现货账户
-	return c.GetAccountBalanceByType("spot", currency)
+	return c.GetAccountBalanceByType(c.accountType, currency)
 }
 
 // GetAccountBalanceByType 根据指定的账户类型获取账户余额
 func (c *Client) GetAccountBalanceByType(accountType, currency string) (map[string]float64, error) {
 	balances := make(map[string]float64)
 	
-	// 只支持现货账户
+	// 永续合约账户只返回结算币种余额
+	if accountType == "futures" {
+		return c.getFuturesAccountBalance(currency)
+	}
+	
+	// 仅支持现货和永续合约账户
 	if accountType != "spot" && accountType != "" {
-		return nil, fmt.Errorf("不支持的账户类型: %s，目前只支持现货账户", accountType)
+		return nil, fmt.Errorf("不支持的账户类型: %s，目前只支持现货和永续合约账户", accountType)
 	}
 	
 	// 构建可选参数
@@ -313,10 +466,7 @@ func (c *Client) GetAccountBalanceByType(accountType, currency string) (map[stri
 	// 调用API获取现货账户余额
 	accounts, _, err := c.client.SpotApi.ListSpotAccounts(c.ctx, opts)
 	if err != nil {
-		if e, ok := err.(gateapi.GateAPIError); ok {
-			return nil, fmt.Errorf("gate api error: %s - %s", e.Label, e.Message)
-		}
-		return nil, fmt.Errorf("获取现货账户余额失败: %w", err)
+		return nil, wrapError(err, "获取现货账户余额失败")
 	}
 	
 	// 如果没有余额，返回空结果
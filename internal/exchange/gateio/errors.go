@@ -0,0 +1,84 @@
+package gateio
+
+import (
+	"errors"
+	"fmt"
+	"order_go/internal/exchange/types"
+	"strconv"
+	"strings"
+
+	"github.com/gateio/gateapi-go/v6"
+)
+
+// labelKinds Gate.io错误标签对应的错误分类
+var labelKinds = map[string]error{
+	"ORDER_NOT_FOUND":           types.ErrOrderNotFound,
+	"ORDER_CLOSED":              types.ErrOrderClosed,
+	"ORDER_CANCELLED":           types.ErrOrderClosed,
+	"ORDER_FINISHED":            types.ErrOrderClosed,
+	"POC_FILL_IMMEDIATELY":      types.ErrOrderRejected,
+	"FOK_NOT_FILL":              types.ErrOrderRejected,
+	"BALANCE_NOT_ENOUGH":        types.ErrInsufficientFunds,
+	"MARGIN_BALANCE_NOT_ENOUGH": types.ErrInsufficientFunds,
+	"INSUFFICIENT_AVAILABLE":    types.ErrInsufficientFunds,
+	"QUANTITY_NOT_ENOUGH":       types.ErrInsufficientFunds,
+	"INVALID_CURRENCY_PAIR":     types.ErrInvalidSymbol,
+	"INVALID_CURRENCY":          types.ErrInvalidSymbol,
+	"CONTRACT_NOT_FOUND":        types.ErrInvalidSymbol,
+	"AMOUNT_TOO_LITTLE":         types.ErrMinNotional,
+	"ORDER_SIZE_TOO_SMALL":      types.ErrMinNotional,
+	"INVALID_PRECISION":         types.ErrInvalidOrder,
+	"INVALID_PARAM_VALUE":       types.ErrInvalidOrder,
+	"AMOUNT_TOO_MUCH":           types.ErrInvalidOrder,
+	"TOO_MANY_REQUESTS":         types.ErrRateLimited,
+	"SERVER_ERROR":              types.ErrUnavailable,
+	"TOO_BUSY":                  types.ErrUnavailable,
+	"INVALID_KEY":               types.ErrAuth,
+	"INVALID_SIGNATURE":         types.ErrAuth,
+	"INVALID_CREDENTIALS":       types.ErrAuth,
+	"IP_FORBIDDEN":              types.ErrAuth,
+	"READ_ONLY":                 types.ErrAuth,
+	"FORBIDDEN":                 types.ErrAuth,
+	"REQUEST_EXPIRED":           types.ErrAuth,
+}
+
+// APIError Gate.io接口返回的错误
+type APIError struct {
+	Label   string
+	Message string
+}
+
+// Error 实现error接口
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gate api error: %s - %s", e.Label, e.Message)
+}
+
+// Unwrap 返回错误标签对应的错误分类，未识别的标签返回nil
+func (e *APIError) Unwrap() error {
+	return labelKinds[e.Label]
+}
+
+// wrapError 将SDK返回的错误转换为可用errors.Is判断分类的错误
+// 非接口业务错误时附加action说明，保留原始错误
+func wrapError(err error, action string) error {
+	var apiErr gateapi.GateAPIError
+	if errors.As(err, &apiErr) {
+		return &APIError{Label: apiErr.Label, Message: apiErr.Message}
+	}
+
+	// SDK对无法解析的响应返回HTTP状态文本，例如"502 Bad Gateway"
+	var httpErr gateapi.GenericOpenAPIError
+	if errors.As(err, &httpErr) {
+		if fields := strings.Fields(httpErr.Error()); len(fields) > 0 {
+			if status, convErr := strconv.Atoi(fields[0]); convErr == nil {
+				return fmt.Errorf("%s: %w", action, &types.HTTPError{
+					Exchange:   "gate",
+					StatusCode: status,
+					Body:       string(httpErr.Body()),
+				})
+			}
+		}
+	}
+
+	return fmt.Errorf("%s: %w", action, err)
+}
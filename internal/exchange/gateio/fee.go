@@ -0,0 +1,47 @@
+package gateio
+
+import (
+	"order_go/internal/exchange/types"
+	"strconv"
+
+	"github.com/antihax/optional"
+	"github.com/gateio/gateapi-go/v6"
+)
+
+// Gate.io手续费抵扣方式，对应debit_fee字段
+const debitFeePointCard = 2
+
+// GetFeeRates 获取账户在交易对上的手续费率
+// 现货开启GT抵扣时返回抵扣后的费率；使用点卡抵扣时费率不变，但手续费不从成交币种中扣除
+func (c *Client) GetFeeRates(symbol string) (*types.FeeRates, error) {
+	opts := &gateapi.GetTradeFeeOpts{}
+	if c.IsFutures() {
+		opts.Settle = optional.NewString(c.settle)
+	} else {
+		opts.CurrencyPair = optional.NewString(symbol)
+	}
+
+	fee, _, err := c.client.WalletApi.GetTradeFee(c.ctx, opts)
+	if err != nil {
+		return nil, wrapError(err, "获取手续费率失败")
+	}
+
+	if c.IsFutures() {
+		maker, _ := strconv.ParseFloat(fee.FuturesMakerFee, 64)
+		taker, _ := strconv.ParseFloat(fee.FuturesTakerFee, 64)
+		return &types.FeeRates{Maker: maker, Taker: taker}, nil
+	}
+
+	rates := &types.FeeRates{}
+	switch {
+	case fee.GtDiscount:
+		rates.Maker, _ = strconv.ParseFloat(fee.GtMakerFee, 64)
+		rates.Taker, _ = strconv.ParseFloat(fee.GtTakerFee, 64)
+		rates.Deducted = true
+	default:
+		rates.Maker, _ = strconv.ParseFloat(fee.MakerFee, 64)
+		rates.Taker, _ = strconv.ParseFloat(fee.TakerFee, 64)
+		rates.Deducted = fee.DebitFee == debitFeePointCard
+	}
+	return rates, nil
+}
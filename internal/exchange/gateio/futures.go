@@ -0,0 +1,307 @@
+package gateio
+
+import (
+	"fmt"
+	"math"
+	"order_go/internal/exchange/types"
+	"order_go/internal/utils/config"
+	"strconv"
+	"strings"
+
+	"github.com/antihax/optional"
+	"github.com/gateio/gateapi-go/v6"
+)
+
+// FuturesPosition 永续合约持仓信息
+type FuturesPosition struct {
+	Contract   string  // 合约名称
+	Size       float64 // 持仓数量（基础币数量，正数为多仓，负数为空仓）
+	EntryPrice float64 // 开仓均价
+	Leverage   int     // 杠杆倍数，全仓模式下为全仓杠杆上限
+	MarginType string  // 保证金模式 (isolated/cross)
+}
+
+// GetSettle 获取永续合约结算币种
+func (c *Client) GetSettle() string {
+	return c.settle
+}
+
+// GetContractMultiplier 获取合约乘数（每张合约对应的基础币数量）
+func (c *Client) GetContractMultiplier(contract string) (float64, error) {
+	c.multipliersMu.RLock()
+	multiplier, ok := c.multipliers[contract]
+	c.multipliersMu.RUnlock()
+	if ok {
+		return multiplier, nil
+	}
+
+	info, _, err := c.client.FuturesApi.GetFuturesContract(c.ctx, c.settle, contract)
+	if err != nil {
+		return 0, wrapError(err, fmt.Sprintf("获取合约%s信息失败", contract))
+	}
+
+	multiplier, err = strconv.ParseFloat(info.QuantoMultiplier, 64)
+	if err != nil || multiplier <= 0 {
+		return 0, fmt.Errorf("解析合约%s乘数失败: %s", contract, info.QuantoMultiplier)
+	}
+
+	c.multipliersMu.Lock()
+	c.multipliers[contract] = multiplier
+	c.multipliersMu.Unlock()
+
+	return multiplier, nil
+}
+
+// getFuturesPrice 获取永续合约最新成交价
+func (c *Client) getFuturesPrice(contract string) (float64, error) {
+	tickers, _, err := c.client.FuturesApi.ListFuturesTickers(c.ctx, c.settle, &gateapi.ListFuturesTickersOpts{
+		Contract: optional.NewString(contract),
+	})
+	if err != nil {
+		return 0, wrapError(err, "获取合约价格失败")
+	}
+
+	if len(tickers) == 0 {
+		return 0, fmt.Errorf("未找到合约 %s 的行情数据", contract)
+	}
+
+	price, err := strconv.ParseFloat(tickers[0].Last, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析价格失败: %w", err)
+	}
+
+	return price, nil
+}
+
+// createFuturesOrder 创建永续合约订单
+// 下单数量以基础币计，按合约乘数换算为张数；卖出方向张数为负
+func (c *Client) createFuturesOrder(order *types.Order) (*types.OrderResponse, error) {
+	multiplier, err := c.GetContractMultiplier(order.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// 换算为合约张数，向下取整避免超出计算数量
+	contracts := int64(math.Floor(order.Amount/multiplier + 1e-9))
+	if contracts <= 0 {
+		return nil, fmt.Errorf("下单数量%.8f不足一张合约（合约乘数%.8f）", order.Amount, multiplier)
+	}
+	if order.Side == types.OrderSideSell {
+		contracts = -contracts
+	}
+
+	orderType, timeInForce := spotOrderMode(order)
+
+	req := gateapi.FuturesOrder{
+		Contract:   order.Symbol,
+		Size:       contracts,
+		Price:      fmt.Sprintf("%.8f", order.Price),
+		Tif:        timeInForce,
+		Text:       order.ClientID,
+		ReduceOnly: order.ReduceOnly || order.PositionSide == "close", // 平仓单只减仓，避免反向开仓
+	}
+
+	// 合约市价单价格传0
+	if orderType == string(types.OrderTypeMarket) {
+		req.Price = "0"
+	}
+
+	result, _, err := c.client.FuturesApi.CreateFuturesOrder(c.ctx, c.settle, req, nil)
+	if err != nil {
+		return nil, wrapError(err, "创建合约订单失败")
+	}
+
+	return &types.OrderResponse{
+		OrderID:   strconv.FormatInt(result.Id, 10),
+		Status:    result.Status,
+		FilledQty: float64(absInt64(result.Size)-absInt64(result.Left)) * multiplier,
+	}, nil
+}
+
+// cancelFuturesOrder 取消永续合约订单
+func (c *Client) cancelFuturesOrder(contract, orderID string) error {
+	_, _, err := c.client.FuturesApi.CancelFuturesOrder(c.ctx, c.settle, orderID, nil)
+	if err != nil {
+		return wrapError(err, "取消合约订单失败")
+	}
+	return nil
+}
+
+// getFuturesOrderStatus 获取永续合约订单状态
+func (c *Client) getFuturesOrderStatus(contract, orderID string) (*types.OrderResponse, error) {
+	order, _, err := c.client.FuturesApi.GetFuturesOrder(c.ctx, c.settle, orderID)
+	if err != nil {
+		return nil, wrapError(err, "获取合约订单状态失败")
+	}
+
+	multiplier, err := c.GetContractMultiplier(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	// 成交张数 = 总张数 - 未成交张数，换算为基础币数量
+	filledQty := float64(absInt64(order.Size)-absInt64(order.Left)) * multiplier
+	filledPrice, _ := strconv.ParseFloat(order.FillPrice, 64)
+
+	// 合约订单状态为open/finished，结束原因在finish_as中，映射为与现货一致的状态
+	status := order.Status
+	if status == "finished" {
+		if order.FinishAs == "filled" {
+			status = "filled"
+		} else {
+			status = "canceled"
+		}
+	}
+
+	// 合约手续费从保证金中扣除
+	fee := 0.0
+	if filledQty > 0 {
+		fee = c.futuresOrderFee(&order, filledQty, filledPrice)
+	}
+
+	return &types.OrderResponse{
+		OrderID:     strconv.FormatInt(order.Id, 10),
+		Status:      status,
+		FilledQty:   filledQty,
+		FilledPrice: filledPrice,
+		Fee:         fee,
+		FeeCurrency: strings.ToUpper(c.settle),
+	}, nil
+}
+
+// futuresOrderFee 按成交记录汇总合约订单的实际手续费，maker返佣时为负数
+// 查询成交记录失败时按费率估算：只挂单(poc)的订单按maker费率，其他订单无法区分按taker费率
+func (c *Client) futuresOrderFee(order *gateapi.FuturesOrder, filledQty, filledPrice float64) float64 {
+	trades, _, err := c.client.FuturesApi.GetMyTrades(c.ctx, c.settle, &gateapi.GetMyTradesOpts{
+		Order: optional.NewInt64(order.Id),
+		Limit: optional.NewInt32(1000),
+	})
+	if err == nil && len(trades) > 0 {
+		fee := 0.0
+		for _, trade := range trades {
+			tradeFee, _ := strconv.ParseFloat(trade.Fee, 64)
+			fee += tradeFee
+		}
+		return fee
+	}
+	if err != nil {
+		config.Logger.Warnw("获取合约订单成交记录失败，按费率估算手续费",
+			"order_id", order.Id,
+			"error", err.Error(),
+		)
+	}
+
+	feeRate := order.Tkfr
+	if order.Tif == string(types.TimeInForcePOC) {
+		feeRate = order.Mkfr
+	}
+	rate, _ := strconv.ParseFloat(feeRate, 64)
+	return filledQty * filledPrice * rate
+}
+
+// GetFuturesPosition 获取永续合约持仓（单向持仓模式）
+func (c *Client) GetFuturesPosition(contract string) (*FuturesPosition, error) {
+	position, _, err := c.client.FuturesApi.GetPosition(c.ctx, c.settle, contract)
+	if err != nil {
+		// 从未开过仓的合约返回POSITION_NOT_FOUND，视为空仓
+		if e, ok := err.(gateapi.GateAPIError); ok && e.Label == "POSITION_NOT_FOUND" {
+			return &FuturesPosition{Contract: contract, MarginType: "cross"}, nil
+		}
+		return nil, wrapError(err, fmt.Sprintf("获取合约%s持仓失败", contract))
+	}
+
+	multiplier, err := c.GetContractMultiplier(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	entryPrice, _ := strconv.ParseFloat(position.EntryPrice, 64)
+
+	// Gate.io中杠杆为0表示全仓模式，此时实际杠杆上限为cross_leverage_limit
+	marginType := "isolated"
+	leverage, _ := strconv.ParseFloat(position.Leverage, 64)
+	if leverage == 0 {
+		marginType = "cross"
+		leverage, _ = strconv.ParseFloat(position.CrossLeverageLimit, 64)
+	}
+
+	config.Logger.Debugw("获取到永续合约持仓",
+		"contract", contract,
+		"size", position.Size,
+		"entry_price", entryPrice,
+		"leverage", leverage,
+		"margin_type", marginType,
+	)
+
+	return &FuturesPosition{
+		Contract:   contract,
+		Size:       float64(position.Size) * multiplier,
+		EntryPrice: entryPrice,
+		Leverage:   int(leverage),
+		MarginType: marginType,
+	}, nil
+}
+
+// getFuturesAccountDetail 获取永续合约账户的可用、总额和占用保证金
+// 总额包含未实现盈亏
+func (c *Client) getFuturesAccountDetail(currency string) (float64, float64, float64, error) {
+	// 合约账户只有结算币种余额
+	if !strings.EqualFold(currency, c.settle) {
+		return 0, 0, 0, nil
+	}
+
+	account, _, err := c.client.FuturesApi.ListFuturesAccounts(c.ctx, c.settle)
+	if err != nil {
+		return 0, 0, 0, wrapError(err, "获取合约账户余额失败")
+	}
+
+	available, _ := strconv.ParseFloat(account.Available, 64)
+	total, _ := strconv.ParseFloat(account.Total, 64)
+	unrealisedPnl, _ := strconv.ParseFloat(account.UnrealisedPnl, 64)
+	total += unrealisedPnl
+
+	locked := total - available
+	if locked < 0 {
+		locked = 0
+	}
+
+	config.Logger.Debugw("解析后的合约账户余额信息",
+		"settle", c.settle,
+		"available", available,
+		"locked", locked,
+		"total", total,
+	)
+
+	return available, total, locked, nil
+}
+
+// getFuturesAccountBalance 以与现货相同的键格式返回合约账户余额
+func (c *Client) getFuturesAccountBalance(currency string) (map[string]float64, error) {
+	balances := make(map[string]float64)
+	settle := strings.ToUpper(c.settle)
+
+	if currency != "" && !strings.EqualFold(currency, settle) {
+		return nil, fmt.Errorf("合约账户不存在币种 %s 的余额", currency)
+	}
+
+	available, total, locked, err := c.getFuturesAccountDetail(settle)
+	if err != nil {
+		return nil, err
+	}
+
+	if total > 0 {
+		balances[settle+".available"] = available
+		balances[settle+".locked"] = locked
+		balances[settle+".total"] = total
+	}
+
+	return balances, nil
+}
+
+// absInt64 取int64绝对值
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,627 @@
+// Package gatetest 提供本地运行的Gate.io v4现货REST接口替身，用于在不访问真实交易所的情况下验证下单、监控等完整流程
+package gatetest
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 可注入故障的接口
+const (
+	RouteAccounts     = "accounts"
+	RouteTickers      = "tickers"
+	RouteCurrencyPair = "currency_pair"
+	RouteOrderBook    = "order_book"
+	RouteCreateOrder  = "create_order"
+	RouteGetOrder     = "get_order"
+	RouteCancelOrder  = "cancel_order"
+)
+
+// Fault 注入的故障，每次请求消耗一个
+type Fault struct {
+	Status  int    // HTTP状态码
+	Label   string // 不为空时按Gate.io错误格式返回label和message，否则返回纯文本响应体
+	Message string
+	Delay   time.Duration // 返回前等待的时间，配合客户端超时模拟请求超时
+	Apply   bool          // 先正常处理请求再返回故障，模拟订单已创建但响应丢失
+}
+
+// defaultPrecision 未设置交易对精度时使用的价格和数量小数位数
+const defaultPrecision = 8
+
+// Order 替身服务器中保存的订单
+// 与Gate.io一致，市价买单的Amount为计价币金额，FilledAmount始终为基础币数量
+type Order struct {
+	ID           string
+	Text         string
+	CurrencyPair string
+	Type         string
+	Side         string
+	TimeInForce  string
+	Price        float64
+	Amount       float64
+	Status       string // open/closed/cancelled
+	FinishAs     string // 为空时按Status推断
+	FilledAmount float64
+	FilledTotal  float64
+	Fee          float64
+	FeeCurrency  string
+	CreateTime   time.Time
+}
+
+// Pair 交易对的下单精度
+type Pair struct {
+	Precision       int // 价格小数位数，也是市价买单金额的小数位数
+	AmountPrecision int // 数量小数位数
+}
+
+// Level 盘口的一档价格
+type Level struct {
+	Price  float64
+	Amount float64
+}
+
+// orderBook 交易对的盘口
+type orderBook struct {
+	bids []Level
+	asks []Level
+}
+
+// Balance 币种余额
+type Balance struct {
+	Available float64
+	Locked    float64
+}
+
+// Server Gate.io现货REST接口替身
+type Server struct {
+	*httptest.Server
+
+	APIKey    string
+	APISecret string
+
+	mu       sync.Mutex
+	prices   map[string]float64
+	pairs    map[string]Pair
+	books    map[string]orderBook
+	balances map[string]Balance
+	orders   map[string]*Order
+	faults   map[string][]Fault
+	requests map[string]int
+	nextID   int64
+}
+
+// NewServer 启动REST接口替身，使用给定的密钥校验私有接口签名
+func NewServer(apiKey, apiSecret string) *Server {
+	s := &Server{
+		APIKey:    apiKey,
+		APISecret: apiSecret,
+		prices:    make(map[string]float64),
+		pairs:     make(map[string]Pair),
+		books:     make(map[string]orderBook),
+		balances:  make(map[string]Balance),
+		orders:    make(map[string]*Order),
+		faults:    make(map[string][]Fault),
+		requests:  make(map[string]int),
+		nextID:    100000,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/spot/accounts", s.handleAccounts)
+	mux.HandleFunc("/api/v4/spot/tickers", s.handleTickers)
+	mux.HandleFunc("/api/v4/spot/currency_pairs/", s.handleCurrencyPair)
+	mux.HandleFunc("/api/v4/spot/order_book", s.handleOrderBook)
+	mux.HandleFunc("/api/v4/spot/orders", s.handleOrders)
+	mux.HandleFunc("/api/v4/spot/orders/", s.handleOrder)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// BaseURL 返回替身的接口地址，可直接配置为base_url
+func (s *Server) BaseURL() string {
+	return s.URL + "/api/v4"
+}
+
+// SetPrice 设置交易对最新价，pair格式为BTC_USDT
+func (s *Server) SetPrice(pair string, price float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prices[pair] = price
+}
+
+// SetPair 设置交易对的下单精度，未设置时价格和数量都允许8位小数
+func (s *Server) SetPair(pair string, precision, amountPrecision int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pairs[pair] = Pair{Precision: precision, AmountPrecision: amountPrecision}
+}
+
+// SetOrderBook 设置交易对盘口，买盘按价格从高到低、卖盘从低到高传入
+func (s *Server) SetOrderBook(pair string, bids, asks []Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.books[pair] = orderBook{bids: bids, asks: asks}
+}
+
+// SetBalance 设置币种余额
+func (s *Server) SetBalance(currency string, available, locked float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[currency] = Balance{Available: available, Locked: locked}
+}
+
+// InjectFault 为接口追加故障，之后的请求依次返回这些故障，用完后恢复正常
+func (s *Server) InjectFault(route string, faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[route] = append(s.faults[route], faults...)
+}
+
+// Requests 返回接口收到的请求次数，包括返回故障的请求
+func (s *Server) Requests(route string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[route]
+}
+
+// FillOrder 按价格成交订单的部分数量，成交量达到委托量时订单关闭
+func (s *Server) FillOrder(id string, amount, price, fee float64, feeCurrency string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.findOrder(id)
+	if !ok || order.Status != "open" {
+		return
+	}
+	order.FilledAmount += amount
+	order.FilledTotal += amount * price
+	order.Fee += fee
+	order.FeeCurrency = feeCurrency
+	if order.FilledAmount >= order.Amount {
+		order.FilledAmount = order.Amount
+		order.Status = "closed"
+	}
+}
+
+// CloseOrder 按当前成交结束订单，例如IOC订单未全部成交时由交易所撤销剩余部分
+func (s *Server) CloseOrder(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if order, ok := s.findOrder(id); ok && order.Status == "open" {
+		order.Status = "cancelled"
+		if order.FilledAmount > 0 {
+			order.Status = "closed"
+			order.FinishAs = "ioc"
+		}
+	}
+}
+
+// GetOrder 按订单ID或text获取替身中保存的订单
+func (s *Server) GetOrder(id string) (Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.findOrder(id)
+	if !ok {
+		return Order{}, false
+	}
+	return *order, true
+}
+
+// Orders 返回替身中保存的全部订单
+func (s *Server) Orders() []Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]Order, 0, len(s.orders))
+	for _, order := range s.orders {
+		orders = append(orders, *order)
+	}
+	return orders
+}
+
+// findOrder 按订单ID或text查找订单，调用方需持有锁
+func (s *Server) findOrder(id string) (*Order, bool) {
+	if order, ok := s.orders[id]; ok {
+		return order, true
+	}
+	for _, order := range s.orders {
+		if order.Text != "" && order.Text == id {
+			return order, true
+		}
+	}
+	return nil, false
+}
+
+// takeFault 记录请求次数并取出接口的下一个故障
+func (s *Server) takeFault(route string) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[route]++
+	queue := s.faults[route]
+	if len(queue) == 0 {
+		return Fault{}, false
+	}
+	s.faults[route] = queue[1:]
+	return queue[0], true
+}
+
+// serve 按注入的故障处理请求，没有故障时直接调用handler
+func (s *Server) serve(route string, w http.ResponseWriter, handler func(w http.ResponseWriter)) {
+	fault, ok := s.takeFault(route)
+	if !ok {
+		handler(w)
+		return
+	}
+
+	if fault.Apply {
+		handler(httptest.NewRecorder())
+	}
+	if fault.Delay > 0 {
+		time.Sleep(fault.Delay)
+	}
+	if fault.Label != "" {
+		writeError(w, fault.Status, fault.Label, fault.Message)
+		return
+	}
+	w.WriteHeader(fault.Status)
+	io.WriteString(w, fault.Message)
+}
+
+// writeJSON 返回JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 按Gate.io格式返回错误
+func writeError(w http.ResponseWriter, status int, label, message string) {
+	writeJSON(w, status, map[string]string{"label": label, "message": message})
+}
+
+// checkAuth 校验APIv4签名请求头，失败时写入错误响应
+func (s *Server) checkAuth(w http.ResponseWriter, r *http.Request, body string) bool {
+	query, _ := url.QueryUnescape(r.URL.RawQuery)
+	expected := sign(s.APISecret, r.Method, r.URL.Path, query, body, r.Header.Get("Timestamp"))
+
+	if r.Header.Get("KEY") != s.APIKey || r.Header.Get("SIGN") != expected {
+		writeError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "Signature mismatch")
+		return false
+	}
+	return true
+}
+
+// sign 计算Gate.io APIv4签名：HMAC-SHA512(方法\n路径\n查询参数\nSHA512(请求体)\n时间戳)
+func sign(secret, method, path, query, body, timestamp string) string {
+	payload := sha512.Sum512([]byte(body))
+	msg := strings.Join([]string{method, path, query, hex.EncodeToString(payload[:]), timestamp}, "\n")
+
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write([]byte(msg))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Server) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAuth(w, r, "") {
+		return
+	}
+
+	currency := r.URL.Query().Get("currency")
+	s.serve(RouteAccounts, w, func(w http.ResponseWriter) {
+		s.mu.Lock()
+		accounts := make([]map[string]string, 0, len(s.balances))
+		for ccy, balance := range s.balances {
+			if currency != "" && ccy != currency {
+				continue
+			}
+			accounts = append(accounts, map[string]string{
+				"currency":  ccy,
+				"available": formatFloat(balance.Available),
+				"locked":    formatFloat(balance.Locked),
+			})
+		}
+		s.mu.Unlock()
+
+		writeJSON(w, http.StatusOK, accounts)
+	})
+}
+
+func (s *Server) handleTickers(w http.ResponseWriter, r *http.Request) {
+	pair := r.URL.Query().Get("currency_pair")
+	s.serve(RouteTickers, w, func(w http.ResponseWriter) {
+		s.mu.Lock()
+		_, known := s.prices[pair]
+		tickers := make([]map[string]string, 0, len(s.prices))
+		for p, price := range s.prices {
+			if pair != "" && p != pair {
+				continue
+			}
+			tickers = append(tickers, map[string]string{
+				"currency_pair": p,
+				"last":          formatFloat(price),
+			})
+		}
+		s.mu.Unlock()
+
+		if pair != "" && !known {
+			writeError(w, http.StatusBadRequest, "INVALID_CURRENCY_PAIR", "Invalid currency pair "+pair)
+			return
+		}
+		writeJSON(w, http.StatusOK, tickers)
+	})
+}
+
+// handleCurrencyPair 处理/spot/currency_pairs/{currency_pair}，设置过最新价的交易对都可以查询
+func (s *Server) handleCurrencyPair(w http.ResponseWriter, r *http.Request) {
+	pair := strings.TrimPrefix(r.URL.Path, "/api/v4/spot/currency_pairs/")
+	s.serve(RouteCurrencyPair, w, func(w http.ResponseWriter) {
+		s.mu.Lock()
+		_, known := s.prices[pair]
+		p := s.pair(pair)
+		s.mu.Unlock()
+
+		if !known {
+			writeError(w, http.StatusBadRequest, "INVALID_CURRENCY_PAIR", "Invalid currency pair "+pair)
+			return
+		}
+		base, quote, _ := strings.Cut(pair, "_")
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":               pair,
+			"base":             base,
+			"quote":            quote,
+			"precision":        p.Precision,
+			"amount_precision": p.AmountPrecision,
+			"trade_status":     "tradable",
+		})
+	})
+}
+
+// pair 获取交易对的下单精度，调用方需持有锁
+func (s *Server) pair(pair string) Pair {
+	if p, ok := s.pairs[pair]; ok {
+		return p
+	}
+	return Pair{Precision: defaultPrecision, AmountPrecision: defaultPrecision}
+}
+
+func (s *Server) handleOrderBook(w http.ResponseWriter, r *http.Request) {
+	pair := r.URL.Query().Get("currency_pair")
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+	s.serve(RouteOrderBook, w, func(w http.ResponseWriter) {
+		s.mu.Lock()
+		book, known := s.books[pair]
+		if _, priced := s.prices[pair]; priced {
+			known = true
+		}
+		s.mu.Unlock()
+
+		if !known {
+			writeError(w, http.StatusBadRequest, "INVALID_CURRENCY_PAIR", "Invalid currency pair "+pair)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"current": time.Now().UnixMilli(),
+			"update":  time.Now().UnixMilli(),
+			"bids":    levelsJSON(book.bids, limit),
+			"asks":    levelsJSON(book.asks, limit),
+		})
+	})
+}
+
+// levelsJSON 按Gate.io格式输出盘口档位，每档为[价格, 数量]
+func levelsJSON(levels []Level, limit int) [][]string {
+	if len(levels) > limit {
+		levels = levels[:limit]
+	}
+	out := make([][]string, 0, len(levels))
+	for _, l := range levels {
+		out = append(out, []string{formatFloat(l.Price), formatFloat(l.Amount)})
+	}
+	return out
+}
+
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, _ := readBody(r)
+	if !s.checkAuth(w, r, body) {
+		return
+	}
+
+	var req map[string]string
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAM_VALUE", "Invalid request body")
+		return
+	}
+
+	s.serve(RouteCreateOrder, w, func(w http.ResponseWriter) {
+		price, _ := strconv.ParseFloat(req["price"], 64)
+		amount, _ := strconv.ParseFloat(req["amount"], 64)
+
+		s.mu.Lock()
+		last, ok := s.prices[req["currency_pair"]]
+		if !ok {
+			s.mu.Unlock()
+			writeError(w, http.StatusBadRequest, "INVALID_CURRENCY_PAIR", "Invalid currency pair "+req["currency_pair"])
+			return
+		}
+		// 市价买单的数量是计价币金额，按价格精度检查
+		market := req["type"] == "market"
+		p := s.pair(req["currency_pair"])
+		amountPrecision := p.AmountPrecision
+		if market && req["side"] == "buy" {
+			amountPrecision = p.Precision
+		}
+		if decimals(req["amount"]) > amountPrecision || (!market && decimals(req["price"]) > p.Precision) {
+			s.mu.Unlock()
+			writeError(w, http.StatusBadRequest, "INVALID_PRECISION", "Invalid precision")
+			return
+		}
+		// 与Gate.io一致，不校验text唯一，重复下单会创建两个订单
+		s.nextID++
+		order := &Order{
+			ID:           strconv.FormatInt(s.nextID, 10),
+			Text:         req["text"],
+			CurrencyPair: req["currency_pair"],
+			Type:         req["type"],
+			Side:         req["side"],
+			TimeInForce:  req["time_in_force"],
+			Price:        price,
+			Amount:       amount,
+			Status:       "open",
+			CreateTime:   time.Now(),
+		}
+		// 市价单按最新价立即全部成交
+		if market {
+			order.Price = 0
+			if order.Side == "buy" {
+				order.FilledAmount = amount / last
+				order.FilledTotal = amount
+			} else {
+				order.FilledAmount = amount
+				order.FilledTotal = amount * last
+			}
+			order.Status = "closed"
+		}
+		s.orders[order.ID] = order
+		resp := orderJSON(order)
+		s.mu.Unlock()
+
+		writeJSON(w, http.StatusCreated, resp)
+	})
+}
+
+// handleOrder 处理/spot/orders/{order_id}的查询和撤单
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAuth(w, r, "") {
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/v4/spot/orders/")
+	switch r.Method {
+	case http.MethodGet:
+		s.serve(RouteGetOrder, w, func(w http.ResponseWriter) {
+			s.mu.Lock()
+			order, ok := s.findOrder(id)
+			var resp map[string]string
+			if ok {
+				resp = orderJSON(order)
+			}
+			s.mu.Unlock()
+
+			if !ok {
+				writeError(w, http.StatusNotFound, "ORDER_NOT_FOUND", "Order not found")
+				return
+			}
+			writeJSON(w, http.StatusOK, resp)
+		})
+	case http.MethodDelete:
+		s.serve(RouteCancelOrder, w, func(w http.ResponseWriter) {
+			s.mu.Lock()
+			order, ok := s.findOrder(id)
+			open := ok && order.Status == "open"
+			var resp map[string]string
+			if open {
+				order.Status = "cancelled"
+				resp = orderJSON(order)
+			}
+			s.mu.Unlock()
+
+			switch {
+			case !ok:
+				writeError(w, http.StatusNotFound, "ORDER_NOT_FOUND", "Order not found")
+			case !open:
+				writeError(w, http.StatusBadRequest, "ORDER_CLOSED", "Order "+id+" has been closed")
+			default:
+				writeJSON(w, http.StatusOK, resp)
+			}
+		})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// orderJSON 按Gate.io格式输出订单，调用方需持有锁
+func orderJSON(order *Order) map[string]string {
+	// 市价买单的剩余数量同样以计价币金额表示
+	left := order.Amount - order.FilledAmount
+	if order.Type == "market" && order.Side == "buy" {
+		left = order.Amount - order.FilledTotal
+	}
+	avgDealPrice := ""
+	if order.FilledAmount > 0 {
+		avgDealPrice = formatFloat(order.FilledTotal / order.FilledAmount)
+	}
+	finishAs := order.FinishAs
+	switch {
+	case finishAs != "":
+	case order.Status == "closed":
+		finishAs = "filled"
+	case order.Status == "cancelled":
+		finishAs = "cancelled"
+	}
+
+	return map[string]string{
+		"id":             order.ID,
+		"text":           order.Text,
+		"create_time":    strconv.FormatInt(order.CreateTime.Unix(), 10),
+		"status":         order.Status,
+		"currency_pair":  order.CurrencyPair,
+		"type":           order.Type,
+		"side":           order.Side,
+		"time_in_force":  order.TimeInForce,
+		"amount":         formatFloat(order.Amount),
+		"price":          formatFloat(order.Price),
+		"left":           formatFloat(left),
+		"filled_amount":  formatFloat(order.FilledAmount),
+		"filled_total":   formatFloat(order.FilledTotal),
+		"avg_deal_price": avgDealPrice,
+		"fee":            formatFloat(order.Fee),
+		"fee_currency":   order.FeeCurrency,
+		"finish_as":      finishAs,
+	}
+}
+
+// formatFloat 按Gate.io的字符串数字格式输出
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// decimals 返回字符串数字的有效小数位数，末尾的0不计入
+func decimals(v string) int {
+	_, frac, ok := strings.Cut(v, ".")
+	if !ok {
+		return 0
+	}
+	return len(strings.TrimRight(frac, "0"))
+}
+
+// readBody 读取请求体
+func readBody(r *http.Request) (string, error) {
+	if r.Body == nil {
+		return "", nil
+	}
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	return string(body), err
+}
@@ -0,0 +1,289 @@
+package gatetest_test
+
+import (
+	"errors"
+	"math"
+	"order_go/internal/exchange/gateio"
+	"order_go/internal/exchange/gateio/gatetest"
+	"order_go/internal/exchange/types"
+	"order_go/internal/utils/config"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+const (
+	testKey    = "test-key"
+	testSecret = "test-secret"
+)
+
+func TestMain(m *testing.M) {
+	config.Logger = zap.NewNop().Sugar()
+	os.Exit(m.Run())
+}
+
+// newClient 启动替身并创建指向它的现货客户端
+func newClient(t *testing.T) (*gatetest.Server, *gateio.Client) {
+	t.Helper()
+	srv := gatetest.NewServer(testKey, testSecret)
+	t.Cleanup(srv.Close)
+
+	client := gateio.NewClient(&config.ExchangeConfig{
+		ApiKey:    testKey,
+		ApiSecret: testSecret,
+		BaseURL:   srv.BaseURL(),
+	})
+	return srv, client
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// TestLimitOrderLifecycle 限价单下单、部分成交后按订单ID和text查询、撤单
+func TestLimitOrderLifecycle(t *testing.T) {
+	srv, client := newClient(t)
+	srv.SetPrice("BTC_USDT", 50000)
+
+	created, err := client.CreateOrder(&types.Order{
+		Symbol:   "BTC_USDT",
+		Side:     types.OrderSideBuy,
+		Type:     types.OrderTypeLimit,
+		Price:    49000,
+		Amount:   0.01,
+		ClientID: "t-lifecycle",
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	order, ok := srv.GetOrder(created.OrderID)
+	if !ok || order.Text != "t-lifecycle" || order.TimeInForce != "gtc" || order.Amount != 0.01 || order.Price != 49000 {
+		t.Fatalf("替身中的订单不正确: %+v", order)
+	}
+
+	srv.FillOrder(created.OrderID, 0.004, 49000, 0.000008, "BTC")
+	status, err := client.GetOrderStatus("BTC_USDT", created.OrderID)
+	if err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	if status.Status != "open" || !almostEqual(status.FilledQty, 0.004) || !almostEqual(status.FilledPrice, 49000) {
+		t.Fatalf("部分成交后状态不正确: %+v", status)
+	}
+	if !almostEqual(status.Fee, 0.000008) || status.FeeCurrency != "BTC" {
+		t.Fatalf("手续费不正确: %+v", status)
+	}
+
+	byText, err := client.GetOrderByClientID("BTC_USDT", "t-lifecycle")
+	if err != nil || byText.OrderID != created.OrderID {
+		t.Fatalf("GetOrderByClientID: %+v, %v", byText, err)
+	}
+
+	if err := client.CancelOrder("BTC_USDT", created.OrderID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	status, err = client.GetOrderStatus("BTC_USDT", created.OrderID)
+	if err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	// Gate.io的cancelled映射为canceled，保留已成交数量
+	if status.Status != "canceled" || !almostEqual(status.FilledQty, 0.004) {
+		t.Fatalf("撤单后状态不正确: %+v", status)
+	}
+
+	err = client.CancelOrder("BTC_USDT", created.OrderID)
+	if !errors.Is(err, types.ErrOrderClosed) {
+		t.Fatalf("重复撤单应返回ErrOrderClosed, got %v", err)
+	}
+}
+
+// TestErrorLabels Gate.io错误标签转换为可用errors.Is判断的分类
+func TestErrorLabels(t *testing.T) {
+	srv, client := newClient(t)
+	srv.SetPrice("BTC_USDT", 50000)
+
+	_, err := client.CreateOrder(&types.Order{Symbol: "NOPE_USDT", Side: types.OrderSideBuy, Price: 1, Amount: 1})
+	if !errors.Is(err, types.ErrInvalidSymbol) {
+		t.Fatalf("未知交易对应返回ErrInvalidSymbol, got %v", err)
+	}
+
+	_, err = client.GetOrderStatus("BTC_USDT", "404")
+	if !errors.Is(err, types.ErrOrderNotFound) {
+		t.Fatalf("不存在的订单应返回ErrOrderNotFound, got %v", err)
+	}
+
+	srv.InjectFault(gatetest.RouteCreateOrder, gatetest.Fault{Status: 400, Label: "BALANCE_NOT_ENOUGH", Message: "Not enough balance"})
+	_, err = client.CreateOrder(&types.Order{Symbol: "BTC_USDT", Side: types.OrderSideBuy, Price: 49000, Amount: 1})
+	if !errors.Is(err, types.ErrInsufficientFunds) {
+		t.Fatalf("余额不足应返回ErrInsufficientFunds, got %v", err)
+	}
+	if len(srv.Orders()) != 0 {
+		t.Fatalf("被拒绝的订单不应保存, got %+v", srv.Orders())
+	}
+
+	wrong := gateio.NewClient(&config.ExchangeConfig{ApiKey: testKey, ApiSecret: "wrong-secret", BaseURL: srv.BaseURL()})
+	if _, err := wrong.GetOrderStatus("BTC_USDT", "404"); !errors.Is(err, types.ErrAuth) {
+		t.Fatalf("签名错误应返回ErrAuth, got %v", err)
+	}
+}
+
+// TestFaultInjection 注入的故障按顺序消耗，用完后接口恢复正常
+func TestFaultInjection(t *testing.T) {
+	srv, client := newClient(t)
+	srv.SetPrice("BTC_USDT", 50000)
+
+	srv.InjectFault(gatetest.RouteTickers, gatetest.Fault{Status: 502, Message: "Bad Gateway"})
+	_, err := client.GetSymbolPrice("BTC_USDT")
+	var httpErr *types.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != 502 {
+		t.Fatalf("应返回HTTP 502错误, got %v", err)
+	}
+
+	price, err := client.GetSymbolPrice("BTC_USDT")
+	if err != nil || price != 50000 {
+		t.Fatalf("故障用完后应恢复正常, got %v, %v", price, err)
+	}
+	if n := srv.Requests(gatetest.RouteTickers); n != 2 {
+		t.Fatalf("行情接口应收到2次请求, got %d", n)
+	}
+}
+
+// TestLostCreateResponse Apply故障先创建订单再返回错误，订单可以按text找回
+func TestLostCreateResponse(t *testing.T) {
+	srv, client := newClient(t)
+	srv.SetPrice("BTC_USDT", 50000)
+
+	srv.InjectFault(gatetest.RouteCreateOrder, gatetest.Fault{Status: 500, Label: "SERVER_ERROR", Message: "Internal error", Apply: true})
+	_, err := client.CreateOrder(&types.Order{
+		Symbol:   "BTC_USDT",
+		Side:     types.OrderSideSell,
+		Price:    51000,
+		Amount:   0.02,
+		ClientID: "t-lost",
+	})
+	if !errors.Is(err, types.ErrUnavailable) {
+		t.Fatalf("服务端错误应返回ErrUnavailable, got %v", err)
+	}
+
+	found, err := client.GetOrderByClientID("BTC_USDT", "t-lost")
+	if err != nil {
+		t.Fatalf("响应丢失的订单应能按text找回: %v", err)
+	}
+	if order, ok := srv.GetOrder(found.OrderID); !ok || order.Side != "sell" || order.Amount != 0.02 {
+		t.Fatalf("找回的订单不正确: %+v", order)
+	}
+}
+
+// TestMarketBuyQuoteRounding 市价买单的计价币金额按交易对价格精度向下取整，精度只查询一次
+func TestMarketBuyQuoteRounding(t *testing.T) {
+	srv, client := newClient(t)
+	srv.SetPrice("BTC_USDT", 30000)
+	srv.SetPair("BTC_USDT", 2, 6)
+
+	for i, amount := range []float64{0.0123457, 0.0001} {
+		created, err := client.CreateOrder(&types.Order{
+			Symbol: "BTC_USDT",
+			Side:   types.OrderSideBuy,
+			Type:   types.OrderTypeMarket,
+			Price:  30000,
+			Amount: amount,
+		})
+		if err != nil {
+			t.Fatalf("第%d笔市价买单: %v", i+1, err)
+		}
+		order, _ := srv.GetOrder(created.OrderID)
+		// 0.0123457 * 30000 = 370.371，0.0001 * 30000 = 3
+		want := []float64{370.37, 3}[i]
+		if order.Type != "market" || order.TimeInForce != "ioc" || !almostEqual(order.Amount, want) {
+			t.Fatalf("第%d笔市价买单应按计价币金额%v下单, got %+v", i+1, want, order)
+		}
+	}
+	if n := srv.Requests(gatetest.RouteCurrencyPair); n != 1 {
+		t.Fatalf("交易对精度应缓存, got %d次查询", n)
+	}
+
+	// 卖单数量为基础币，不需要查询价格精度
+	if _, err := client.CreateOrder(&types.Order{Symbol: "BTC_USDT", Side: types.OrderSideSell, Type: types.OrderTypeMarket, Amount: 0.5}); err != nil {
+		t.Fatalf("市价卖单: %v", err)
+	}
+}
+
+// TestPrecisionRejected 价格小数位数超过交易对精度时返回ErrInvalidOrder
+func TestPrecisionRejected(t *testing.T) {
+	srv, client := newClient(t)
+	srv.SetPrice("BTC_USDT", 30000)
+	srv.SetPair("BTC_USDT", 2, 6)
+
+	_, err := client.CreateOrder(&types.Order{Symbol: "BTC_USDT", Side: types.OrderSideBuy, Price: 29999.123, Amount: 0.01})
+	if !errors.Is(err, types.ErrInvalidOrder) {
+		t.Fatalf("价格精度超出应返回ErrInvalidOrder, got %v", err)
+	}
+}
+
+// TestMarketBuyFilledOnCreate 市价买单下单即成交，下单响应中的成交数量为基础币数量
+func TestMarketBuyFilledOnCreate(t *testing.T) {
+	srv, client := newClient(t)
+	srv.SetPrice("BTC_USDT", 30000)
+	srv.SetPair("BTC_USDT", 2, 6)
+
+	created, err := client.CreateOrder(&types.Order{
+		Symbol: "BTC_USDT",
+		Side:   types.OrderSideBuy,
+		Type:   types.OrderTypeMarket,
+		Price:  30000,
+		Amount: 0.0123457,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	// filled_total为计价币金额370.37，不能当作成交数量
+	if created.Status != "filled" || !almostEqual(created.FilledQty, 370.37/30000) || !almostEqual(created.FilledPrice, 30000) {
+		t.Fatalf("市价买单下单响应不正确: %+v", created)
+	}
+}
+
+// TestMarketSellFilledOnCreate 市价卖单的数量为基础币
+func TestMarketSellFilledOnCreate(t *testing.T) {
+	srv, client := newClient(t)
+	srv.SetPrice("ETH_USDT", 2000)
+
+	created, err := client.CreateOrder(&types.Order{
+		Symbol: "ETH_USDT",
+		Side:   types.OrderSideSell,
+		Type:   types.OrderTypeMarket,
+		Amount: 0.5,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if created.Status != "filled" || !almostEqual(created.FilledQty, 0.5) || !almostEqual(created.FilledPrice, 2000) {
+		t.Fatalf("市价卖单下单响应不正确: %+v", created)
+	}
+}
+
+// TestIOCPartialFill IOC订单部分成交后结束，状态为closed但finish_as不是filled，不能记为全部成交
+func TestIOCPartialFill(t *testing.T) {
+	srv, client := newClient(t)
+	srv.SetPrice("BTC_USDT", 50000)
+
+	created, err := client.CreateOrder(&types.Order{
+		Symbol:      "BTC_USDT",
+		Side:        types.OrderSideBuy,
+		Price:       50000,
+		Amount:      0.01,
+		TimeInForce: types.TimeInForceIOC,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	srv.FillOrder(created.OrderID, 0.003, 50000, 0, "")
+	srv.CloseOrder(created.OrderID)
+
+	status, err := client.GetOrderStatus("BTC_USDT", created.OrderID)
+	if err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	if status.Status != "canceled" || !almostEqual(status.FilledQty, 0.003) {
+		t.Fatalf("IOC部分成交后状态不正确: %+v", status)
+	}
+}
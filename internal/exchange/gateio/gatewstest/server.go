@@ -0,0 +1,216 @@
+// Package gatewstest 提供本地运行的Gate.io v4现货WebSocket替身，用于在不访问真实交易所的情况下验证订单推送
+package gatewstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order_go/internal/exchange/gateio"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Order spot.orders频道推送的订单，字段与Gate.io一致
+type Order struct {
+	ID           string `json:"id"`
+	Text         string `json:"text"`
+	CurrencyPair string `json:"currency_pair"`
+	Event        string `json:"event"` // put/update/finish
+	Amount       string `json:"amount"`
+	Price        string `json:"price"`
+	Left         string `json:"left"`
+	FilledAmount string `json:"filled_amount"`
+	AvgDealPrice string `json:"avg_deal_price"`
+	Fee          string `json:"fee"`
+	FeeCurrency  string `json:"fee_currency"`
+	FinishAs     string `json:"finish_as"`
+}
+
+// Trade spot.usertrades频道推送的成交，字段与Gate.io一致
+type Trade struct {
+	ID           int64  `json:"id"`
+	OrderID      string `json:"order_id"`
+	Text         string `json:"text"`
+	CurrencyPair string `json:"currency_pair"`
+	Amount       string `json:"amount"`
+	Price        string `json:"price"`
+	Fee          string `json:"fee"`
+	FeeCurrency  string `json:"fee_currency"`
+}
+
+// request 客户端请求
+type request struct {
+	Time    int64    `json:"time"`
+	Channel string   `json:"channel"`
+	Event   string   `json:"event"`
+	Payload []string `json:"payload"`
+	Auth    *struct {
+		Method string `json:"method"`
+		Key    string `json:"KEY"`
+		Sign   string `json:"SIGN"`
+	} `json:"auth"`
+}
+
+// client 已连接的客户端及其订阅的频道
+type client struct {
+	conn     *websocket.Conn
+	writeMu  sync.Mutex
+	channels map[string]bool
+}
+
+// send 向客户端发送消息，写操作串行执行
+func (c *client) send(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+// Server Gate.io现货WebSocket替身
+type Server struct {
+	*httptest.Server
+
+	APIKey    string
+	APISecret string
+
+	upgrader websocket.Upgrader
+	mu       sync.Mutex
+	clients  map[*client]bool
+}
+
+// NewServer 启动WebSocket替身，使用给定的密钥校验私有频道订阅签名
+func NewServer(apiKey, apiSecret string) *Server {
+	s := &Server{
+		APIKey:    apiKey,
+		APISecret: apiSecret,
+		clients:   make(map[*client]bool),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// WSURL 返回替身的WebSocket地址，可直接配置为ws_url
+func (s *Server) WSURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http") + "/ws/v4/"
+}
+
+// Subscribers 返回订阅了指定频道的连接数
+func (s *Server) Subscribers(channel string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for c := range s.clients {
+		if c.channels[channel] {
+			n++
+		}
+	}
+	return n
+}
+
+// PushOrder 向订阅spot.orders的连接推送订单更新
+func (s *Server) PushOrder(orders ...Order) {
+	s.push("spot.orders", orders)
+}
+
+// PushTrade 向订阅spot.usertrades的连接推送成交
+func (s *Server) PushTrade(trades ...Trade) {
+	s.push("spot.usertrades", trades)
+}
+
+// DropConnections 断开所有客户端连接，用于验证断线重连和轮询兜底
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.clients {
+		c.conn.Close()
+		delete(s.clients, c)
+	}
+}
+
+// push 按Gate.io格式推送频道更新
+func (s *Server) push(channel string, result interface{}) {
+	msg := map[string]interface{}{
+		"time":    time.Now().Unix(),
+		"channel": channel,
+		"event":   "update",
+		"result":  result,
+	}
+
+	s.mu.Lock()
+	var targets []*client
+	for c := range s.clients {
+		if c.channels[channel] {
+			targets = append(targets, c)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, c := range targets {
+		c.send(msg)
+	}
+}
+
+// handle 处理WebSocket连接
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &client{conn: conn, channels: make(map[string]bool)}
+	s.mu.Lock()
+	s.clients[c] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req request
+		if err := json.Unmarshal(data, &req); err != nil {
+			continue
+		}
+
+		switch {
+		case req.Channel == "spot.ping":
+			c.send(map[string]interface{}{"time": time.Now().Unix(), "channel": "spot.pong"})
+		case req.Event == "subscribe":
+			c.send(s.subscribe(c, &req))
+		}
+	}
+}
+
+// subscribe 校验签名并登记订阅，返回订阅结果
+func (s *Server) subscribe(c *client, req *request) map[string]interface{} {
+	resp := map[string]interface{}{
+		"time":    time.Now().Unix(),
+		"channel": req.Channel,
+		"event":   "subscribe",
+	}
+
+	if req.Auth == nil || req.Auth.Key != s.APIKey ||
+		req.Auth.Sign != gateio.SignWS(s.APISecret, req.Channel, req.Event, req.Time) {
+		resp["error"] = map[string]interface{}{"code": 2, "message": "Invalid key provided"}
+		return resp
+	}
+
+	s.mu.Lock()
+	c.channels[req.Channel] = true
+	s.mu.Unlock()
+
+	resp["result"] = map[string]string{"status": "success"}
+	return resp
+}
@@ -0,0 +1,162 @@
+package gatewstest_test
+
+import (
+	"math"
+	"order_go/internal/exchange/gateio"
+	"order_go/internal/exchange/gateio/gatewstest"
+	"order_go/internal/exchange/types"
+	"order_go/internal/utils/config"
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	testKey    = "test-key"
+	testSecret = "test-secret"
+)
+
+func TestMain(m *testing.M) {
+	config.Logger = zap.NewNop().Sugar()
+	os.Exit(m.Run())
+}
+
+// startStream 启动替身和连接到它的订单推送，等待订阅完成
+func startStream(t *testing.T) (*gatewstest.Server, *gateio.OrderStream, <-chan *types.OrderUpdate) {
+	t.Helper()
+	srv := gatewstest.NewServer(testKey, testSecret)
+	t.Cleanup(srv.Close)
+
+	stream := gateio.NewOrderStream(&config.ExchangeConfig{
+		ApiKey:    testKey,
+		ApiSecret: testSecret,
+		WSURL:     srv.WSURL(),
+	})
+	t.Cleanup(stream.Close)
+
+	updates := make(chan *types.OrderUpdate, 16)
+	stream.Start(func(u *types.OrderUpdate) { updates <- u })
+	waitFor(t, stream.Connected, "订单推送未能连接")
+	return srv, stream, updates
+}
+
+// waitFor 等待条件成立
+func waitFor(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// nextUpdate 等待下一条推送
+func nextUpdate(t *testing.T, updates <-chan *types.OrderUpdate) *types.OrderUpdate {
+	t.Helper()
+	select {
+	case u := <-updates:
+		return u
+	case <-time.After(5 * time.Second):
+		t.Fatal("没有收到订单推送")
+		return nil
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// TestTradesAccumulateUntilFinish 成交推送按订单累计，订单结束推送给出最终状态
+func TestTradesAccumulateUntilFinish(t *testing.T) {
+	srv, _, updates := startStream(t)
+
+	srv.PushTrade(gatewstest.Trade{ID: 1, OrderID: "1001", Text: "t-1", CurrencyPair: "BTC_USDT",
+		Amount: "0.004", Price: "49000", Fee: "0.000008", FeeCurrency: "BTC"})
+	u := nextUpdate(t, updates)
+	if u.OrderID != "1001" || u.Status != "open" || !almostEqual(u.FilledQty, 0.004) || !almostEqual(u.FilledPrice, 49000) {
+		t.Fatalf("第一笔成交推送不正确: %+v", u)
+	}
+
+	srv.PushTrade(gatewstest.Trade{ID: 2, OrderID: "1001", Text: "t-1", CurrencyPair: "BTC_USDT",
+		Amount: "0.006", Price: "49500", Fee: "0.000012", FeeCurrency: "BTC"})
+	u = nextUpdate(t, updates)
+	if !almostEqual(u.FilledQty, 0.01) || !almostEqual(u.FilledPrice, 49300) || !almostEqual(u.Fee, 0.00002) {
+		t.Fatalf("成交推送应按订单累计: %+v", u)
+	}
+
+	srv.PushOrder(gatewstest.Order{ID: "1001", Text: "t-1", CurrencyPair: "BTC_USDT", Event: "finish",
+		Amount: "0.01", Price: "50000", Left: "0", FilledAmount: "0.01", AvgDealPrice: "49300",
+		Fee: "0.00002", FeeCurrency: "BTC", FinishAs: "filled"})
+	u = nextUpdate(t, updates)
+	if u.Status != "filled" || !almostEqual(u.FilledQty, 0.01) || u.ClientID != "t-1" || u.Symbol != "BTC_USDT" {
+		t.Fatalf("订单结束推送不正确: %+v", u)
+	}
+}
+
+// TestFinishAsCancelled 非filled结束的订单推送为canceled，保留已成交数量
+func TestFinishAsCancelled(t *testing.T) {
+	srv, _, updates := startStream(t)
+
+	for _, finishAs := range []string{"cancelled", "ioc"} {
+		srv.PushOrder(gatewstest.Order{ID: "3001", CurrencyPair: "ETH_USDT", Event: "finish",
+			Amount: "1", Price: "2000", Left: "0.6", FilledAmount: "0.4", FinishAs: finishAs})
+		u := nextUpdate(t, updates)
+		if u.Status != "canceled" || !almostEqual(u.FilledQty, 0.4) || !almostEqual(u.FilledPrice, 2000) {
+			t.Fatalf("finish_as=%s的订单推送不正确: %+v", finishAs, u)
+		}
+	}
+}
+
+// TestMarketBuyFilledAmount 市价买单的amount和left为计价币金额，成交数量取filled_amount
+func TestMarketBuyFilledAmount(t *testing.T) {
+	srv, _, updates := startStream(t)
+
+	srv.PushOrder(gatewstest.Order{ID: "2001", CurrencyPair: "BTC_USDT", Event: "finish",
+		Amount: "370.37", Left: "0", FilledAmount: "0.012345", AvgDealPrice: "30001.62",
+		Fee: "0.000024", FeeCurrency: "BTC", FinishAs: "filled"})
+	u := nextUpdate(t, updates)
+	if u.Status != "filled" || !almostEqual(u.FilledQty, 0.012345) || !almostEqual(u.FilledPrice, 30001.62) {
+		t.Fatalf("市价买单成交数量应为基础币数量: %+v", u)
+	}
+}
+
+// TestReconnectAfterDrop 连接断开后自动重连并重新订阅
+func TestReconnectAfterDrop(t *testing.T) {
+	srv, stream, updates := startStream(t)
+
+	srv.DropConnections()
+	waitFor(t, func() bool { return !stream.Connected() }, "断线后仍显示已连接")
+	waitFor(t, func() bool { return stream.Connected() && srv.Subscribers("spot.orders") == 1 }, "断线后未能重连")
+
+	srv.PushOrder(gatewstest.Order{ID: "4001", CurrencyPair: "BTC_USDT", Event: "update",
+		Amount: "0.01", Price: "50000", Left: "0.01"})
+	if u := nextUpdate(t, updates); u.OrderID != "4001" || u.Status != "open" {
+		t.Fatalf("重连后的推送不正确: %+v", u)
+	}
+}
+
+// TestInvalidKeyNotConnected 签名错误时订阅失败，推送不可用
+func TestInvalidKeyNotConnected(t *testing.T) {
+	srv := gatewstest.NewServer(testKey, testSecret)
+	t.Cleanup(srv.Close)
+
+	stream := gateio.NewOrderStream(&config.ExchangeConfig{
+		ApiKey:    testKey,
+		ApiSecret: "wrong-secret",
+		WSURL:     srv.WSURL(),
+	})
+	t.Cleanup(stream.Close)
+	stream.Start(func(*types.OrderUpdate) {})
+
+	time.Sleep(200 * time.Millisecond)
+	if stream.Connected() {
+		t.Fatal("签名错误时不应连接成功")
+	}
+	if n := srv.Subscribers("spot.orders"); n != 0 {
+		t.Fatalf("签名错误时不应登记订阅, got %d", n)
+	}
+}
@@ -0,0 +1,124 @@
+package gateio
+
+import (
+	"fmt"
+	"order_go/internal/exchange/types"
+	"strconv"
+	"time"
+
+	"github.com/antihax/optional"
+	"github.com/gateio/gateapi-go/v6"
+)
+
+// gateIntervals 统一K线周期对应的Gate.io周期参数
+var gateIntervals = map[string]string{
+	types.Interval1m:  "1m",
+	types.Interval5m:  "5m",
+	types.Interval15m: "15m",
+	types.Interval30m: "30m",
+	types.Interval1h:  "1h",
+	types.Interval4h:  "4h",
+	types.Interval1d:  "1d",
+	types.Interval1w:  "7d",
+}
+
+// maxKlineLimit Gate.io单次最多返回的K线数量
+const maxKlineLimit = 1000
+
+// GetKlines 获取K线，按开盘时间从早到晚排列
+// since为零值时返回最近limit根；否则返回从since开始的limit根，Gate.io不允许limit与时间范围同时使用，按周期换算为结束时间
+func (c *Client) GetKlines(symbol, interval string, since time.Time, limit int) ([]types.Kline, error) {
+	gateInterval, ok := gateIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("不支持的K线周期: %s", interval)
+	}
+	if limit <= 0 || limit > maxKlineLimit {
+		limit = maxKlineLimit
+	}
+
+	var from, to optional.Int64
+	var limitOpt optional.Int32
+	if since.IsZero() {
+		limitOpt = optional.NewInt32(int32(limit))
+	} else {
+		d, _ := types.IntervalDuration(interval)
+		end := since.Add(d * time.Duration(limit-1))
+		if end.After(time.Now()) {
+			end = time.Now()
+		}
+		from = optional.NewInt64(since.Unix())
+		to = optional.NewInt64(end.Unix())
+	}
+
+	if c.IsFutures() {
+		return c.getFuturesKlines(symbol, interval, gateInterval, from, to, limitOpt)
+	}
+
+	rows, _, err := c.client.SpotApi.ListCandlesticks(c.ctx, symbol, &gateapi.ListCandlesticksOpts{
+		Interval: optional.NewString(gateInterval),
+		Limit:    limitOpt,
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		return nil, wrapError(err, "获取K线失败")
+	}
+
+	// 每根K线为[时间(秒), 成交额, 收盘价, 最高价, 最低价, 开盘价, 成交量, 是否收盘]
+	klines := make([]types.Kline, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 7 {
+			continue
+		}
+		values, err := parseFloats(row[:7])
+		if err != nil {
+			return nil, fmt.Errorf("解析K线失败: %w", err)
+		}
+		openTime := time.Unix(int64(values[0]), 0)
+		klines = append(klines, types.NewKline(interval, openTime, values[5], values[3], values[4], values[2], values[6], values[1]))
+	}
+	return klines, nil
+}
+
+// getFuturesKlines 获取永续合约K线，成交张数按合约乘数换算为基础币数量
+func (c *Client) getFuturesKlines(contract, interval, gateInterval string, from, to optional.Int64, limit optional.Int32) ([]types.Kline, error) {
+	multiplier, err := c.GetContractMultiplier(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, _, err := c.client.FuturesApi.ListFuturesCandlesticks(c.ctx, c.settle, contract, &gateapi.ListFuturesCandlesticksOpts{
+		Interval: optional.NewString(gateInterval),
+		Limit:    limit,
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		return nil, wrapError(err, "获取合约K线失败")
+	}
+
+	klines := make([]types.Kline, 0, len(rows))
+	for _, row := range rows {
+		values, err := parseFloats([]string{row.O, row.H, row.L, row.C})
+		if err != nil {
+			return nil, fmt.Errorf("解析合约K线失败: %w", err)
+		}
+		quoteVolume, _ := strconv.ParseFloat(row.Sum, 64)
+		openTime := time.Unix(int64(row.T), 0)
+		klines = append(klines, types.NewKline(interval, openTime, values[0], values[1], values[2], values[3], float64(row.V)*multiplier, quoteVolume))
+	}
+	return klines, nil
+}
+
+// parseFloats 将字符串依次解析为浮点数
+func parseFloats(raw []string) ([]float64, error) {
+	values := make([]float64, len(raw))
+	for i, s := range raw {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
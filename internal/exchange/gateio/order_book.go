@@ -0,0 +1,114 @@
+package gateio
+
+import (
+	"fmt"
+	"order_go/internal/exchange/types"
+	"strconv"
+	"time"
+
+	"github.com/antihax/optional"
+	"github.com/gateio/gateapi-go/v6"
+)
+
+// GetOrderBook 获取交易对盘口深度，永续合约的挂单张数按合约乘数换算为基础币数量
+func (c *Client) GetOrderBook(symbol string, depth int) (*types.OrderBook, error) {
+	if c.IsFutures() {
+		return c.getFuturesOrderBook(symbol, depth)
+	}
+
+	book, _, err := c.client.SpotApi.ListOrderBook(c.ctx, symbol, &gateapi.ListOrderBookOpts{
+		Limit: optional.NewInt32(int32(depth)),
+	})
+	if err != nil {
+		return nil, wrapError(err, "获取盘口失败")
+	}
+
+	bids, err := parseSpotLevels(book.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseSpotLevels(book.Asks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.OrderBook{
+		Symbol: symbol,
+		Bids:   bids,
+		Asks:   asks,
+		Time:   bookTime(book.Current),
+	}, nil
+}
+
+// getFuturesOrderBook 获取永续合约盘口深度
+func (c *Client) getFuturesOrderBook(contract string, depth int) (*types.OrderBook, error) {
+	multiplier, err := c.GetContractMultiplier(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	book, _, err := c.client.FuturesApi.ListFuturesOrderBook(c.ctx, c.settle, contract, &gateapi.ListFuturesOrderBookOpts{
+		Limit: optional.NewInt32(int32(depth)),
+	})
+	if err != nil {
+		return nil, wrapError(err, "获取合约盘口失败")
+	}
+
+	bids, err := parseFuturesLevels(book.Bids, multiplier)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseFuturesLevels(book.Asks, multiplier)
+	if err != nil {
+		return nil, err
+	}
+
+	// 合约盘口时间为秒，带小数
+	return &types.OrderBook{
+		Symbol: contract,
+		Bids:   bids,
+		Asks:   asks,
+		Time:   bookTime(int64(book.Current * 1000)),
+	}, nil
+}
+
+// parseSpotLevels 解析现货盘口，每档为[价格, 数量]
+func parseSpotLevels(raw [][]string) ([]types.PriceLevel, error) {
+	levels := make([]types.PriceLevel, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 2 {
+			continue
+		}
+		price, err := strconv.ParseFloat(item[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("解析盘口价格失败: %w", err)
+		}
+		amount, err := strconv.ParseFloat(item[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("解析盘口数量失败: %w", err)
+		}
+		levels = append(levels, types.PriceLevel{Price: price, Amount: amount})
+	}
+	return levels, nil
+}
+
+// parseFuturesLevels 解析合约盘口，挂单数量为张数
+func parseFuturesLevels(raw []gateapi.FuturesOrderBookItem, multiplier float64) ([]types.PriceLevel, error) {
+	levels := make([]types.PriceLevel, 0, len(raw))
+	for _, item := range raw {
+		price, err := strconv.ParseFloat(item.P, 64)
+		if err != nil {
+			return nil, fmt.Errorf("解析盘口价格失败: %w", err)
+		}
+		levels = append(levels, types.PriceLevel{Price: price, Amount: float64(item.S) * multiplier})
+	}
+	return levels, nil
+}
+
+// bookTime 将毫秒时间戳转换为盘口时间，为0时使用当前时间
+func bookTime(ms int64) time.Time {
+	if ms <= 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(ms)
+}
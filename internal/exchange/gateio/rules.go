@@ -0,0 +1,79 @@
+package gateio
+
+import (
+	"order_go/internal/exchange/types"
+	"strconv"
+	"strings"
+)
+
+// GetSymbolRules 获取所有交易对的下单规则，永续合约账户返回合约规则
+func (c *Client) GetSymbolRules() ([]types.SymbolRule, error) {
+	if c.IsFutures() {
+		return c.getFuturesSymbolRules()
+	}
+
+	pairs, _, err := c.client.SpotApi.ListCurrencyPairs(c.ctx)
+	if err != nil {
+		return nil, wrapError(err, "获取交易对列表失败")
+	}
+
+	rules := make([]types.SymbolRule, 0, len(pairs))
+	for _, pair := range pairs {
+		minBase, _ := strconv.ParseFloat(pair.MinBaseAmount, 64)
+		minQuote, _ := strconv.ParseFloat(pair.MinQuoteAmount, 64)
+		c.precisionsMu.Lock()
+		c.precisions[pair.Id] = int(pair.Precision)
+		c.precisionsMu.Unlock()
+		rules = append(rules, types.SymbolRule{
+			Symbol:          pair.Id,
+			MinBaseAmount:   minBase,
+			MinQuoteAmount:  minQuote,
+			AmountPrecision: int(pair.AmountPrecision),
+			PricePrecision:  int(pair.Precision),
+			Status:          pair.TradeStatus,
+		})
+	}
+	return rules, nil
+}
+
+// getFuturesSymbolRules 获取永续合约的下单规则
+// 合约以张为单位下单，最小数量按合约乘数换算为基础币，数量精度取乘数的小数位数
+func (c *Client) getFuturesSymbolRules() ([]types.SymbolRule, error) {
+	contracts, _, err := c.client.FuturesApi.ListFuturesContracts(c.ctx, c.settle, nil)
+	if err != nil {
+		return nil, wrapError(err, "获取合约列表失败")
+	}
+
+	rules := make([]types.SymbolRule, 0, len(contracts))
+	for _, contract := range contracts {
+		multiplier, _ := strconv.ParseFloat(contract.QuantoMultiplier, 64)
+		if multiplier > 0 {
+			c.multipliersMu.Lock()
+			c.multipliers[contract.Name] = multiplier
+			c.multipliersMu.Unlock()
+		}
+
+		status := types.SymbolStatusTradable
+		if contract.InDelisting {
+			status = types.SymbolStatusDelisting
+		}
+
+		rules = append(rules, types.SymbolRule{
+			Symbol:          contract.Name,
+			MinBaseAmount:   float64(contract.OrderSizeMin) * multiplier,
+			AmountPrecision: decimalPlaces(contract.QuantoMultiplier),
+			PricePrecision:  decimalPlaces(contract.OrderPriceRound),
+			Status:          status,
+		})
+	}
+	return rules, nil
+}
+
+// decimalPlaces 计算最小变动单位（如"0.001"）的小数位数
+func decimalPlaces(step string) int {
+	step = strings.TrimRight(step, "0")
+	if i := strings.IndexByte(step, '.'); i >= 0 {
+		return len(step) - i - 1
+	}
+	return 0
+}
@@ -0,0 +1,395 @@
+package gateio
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"order_go/internal/exchange/types"
+	"order_go/internal/utils/config"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// 默认的Gate.io现货WebSocket地址
+const defaultWSURL = "wss://api.gateio.ws/ws/v4/"
+
+const (
+	channelOrders     = "spot.orders"
+	channelUserTrades = "spot.usertrades"
+
+	wsPingInterval   = 10 * time.Second
+	wsReadTimeout    = 30 * time.Second
+	wsMaxReconnectIn = 30 * time.Second
+	wsFillRetention  = time.Hour // 成交累计数据的保留时间，订单结束后的迟到成交不会一直占用内存
+)
+
+// SignWS 计算WebSocket私有频道的认证签名
+// 签名内容为 channel=<channel>&event=<event>&time=<time>，使用HMAC-SHA512后十六进制编码
+func SignWS(secret, channel, event string, t int64) string {
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("channel=%s&event=%s&time=%d", channel, event, t)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// wsRequest WebSocket请求
+type wsRequest struct {
+	Time    int64    `json:"time"`
+	Channel string   `json:"channel"`
+	Event   string   `json:"event"`
+	Payload []string `json:"payload,omitempty"`
+	Auth    *wsAuth  `json:"auth,omitempty"`
+}
+
+// wsAuth 私有频道认证信息
+type wsAuth struct {
+	Method string `json:"method"`
+	Key    string `json:"KEY"`
+	Sign   string `json:"SIGN"`
+}
+
+// wsMessage WebSocket推送消息
+type wsMessage struct {
+	Time    int64           `json:"time"`
+	Channel string          `json:"channel"`
+	Event   string          `json:"event"`
+	Error   *wsError        `json:"error"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// wsError WebSocket错误信息
+type wsError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// wsOrder spot.orders频道推送的订单
+type wsOrder struct {
+	ID           string `json:"id"`
+	Text         string `json:"text"`
+	CurrencyPair string `json:"currency_pair"`
+	Event        string `json:"event"` // put/update/finish
+	Amount       string `json:"amount"`
+	Price        string `json:"price"`
+	Left         string `json:"left"`
+	FilledAmount string `json:"filled_amount"` // 已成交的基础币数量，市价买单的amount和left为计价币金额，不能相减得到
+	AvgDealPrice string `json:"avg_deal_price"`
+	Fee          string `json:"fee"`
+	FeeCurrency  string `json:"fee_currency"`
+	FinishAs     string `json:"finish_as"` // filled/cancelled/ioc/stp...
+}
+
+// wsTrade spot.usertrades频道推送的成交
+type wsTrade struct {
+	ID           int64  `json:"id"`
+	OrderID      string `json:"order_id"`
+	Text         string `json:"text"`
+	CurrencyPair string `json:"currency_pair"`
+	Amount       string `json:"amount"`
+	Price        string `json:"price"`
+	Fee          string `json:"fee"`
+	FeeCurrency  string `json:"fee_currency"`
+}
+
+// fillState 按成交推送累计的订单成交情况
+type fillState struct {
+	qty         float64
+	quote       float64
+	fee         float64
+	feeCurrency string
+	updatedAt   time.Time
+}
+
+// OrderStream Gate.io现货订单推送
+// 订阅spot.orders和spot.usertrades频道，断线后自动重连
+type OrderStream struct {
+	url       string
+	apiKey    string
+	apiSecret string
+
+	connected atomic.Bool
+	stopOnce  sync.Once
+	stop      chan struct{}
+
+	mu    sync.Mutex
+	conn  *websocket.Conn
+	fills map[string]*fillState
+}
+
+// NewOrderStream 创建订单推送客户端
+func NewOrderStream(cfg *config.ExchangeConfig) *OrderStream {
+	wsURL := cfg.WSURL
+	if wsURL == "" {
+		wsURL = defaultWSURL
+	}
+
+	return &OrderStream{
+		url:       wsURL,
+		apiKey:    cfg.ApiKey,
+		apiSecret: cfg.ApiSecret,
+		stop:      make(chan struct{}),
+		fills:     make(map[string]*fillState),
+	}
+}
+
+// Start 在后台连接并持续接收订单推送，handler在接收协程中调用
+func (s *OrderStream) Start(handler func(*types.OrderUpdate)) {
+	go s.run(handler)
+}
+
+// Connected 推送连接是否已建立且订阅成功
+func (s *OrderStream) Connected() bool {
+	return s.connected.Load()
+}
+
+// Close 关闭推送连接，不再重连
+func (s *OrderStream) Close() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		s.mu.Lock()
+		if s.conn != nil {
+			s.conn.Close()
+		}
+		s.mu.Unlock()
+	})
+}
+
+// run 连接循环，断线后按指数退避重连
+func (s *OrderStream) run(handler func(*types.OrderUpdate)) {
+	backoff := time.Second
+	for {
+		start := time.Now()
+		err := s.serve(handler)
+		s.connected.Store(false)
+
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		// 连接稳定运行过一段时间后重置退避时间
+		if time.Since(start) > wsMaxReconnectIn {
+			backoff = time.Second
+		}
+		config.Logger.Warnw("Gate.io订单推送连接断开，稍后重连",
+			"error", err,
+			"retry_in", backoff.String(),
+		)
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > wsMaxReconnectIn {
+			backoff = wsMaxReconnectIn
+		}
+	}
+}
+
+// serve 建立连接、订阅频道并处理推送，连接断开时返回
+func (s *OrderStream) serve(handler func(*types.OrderUpdate)) error {
+	conn, _, err := websocket.DefaultDialer.Dial(s.url, nil)
+	if err != nil {
+		return fmt.Errorf("连接WebSocket失败: %w", err)
+	}
+
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+	defer conn.Close()
+
+	// 写操作不能并发，心跳协程与订阅请求共用写锁
+	var writeMu sync.Mutex
+	send := func(req wsRequest) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.SetWriteDeadline(time.Now().Add(wsReadTimeout))
+		return conn.WriteJSON(req)
+	}
+
+	for _, channel := range []string{channelOrders, channelUserTrades} {
+		if err := send(s.subscribeRequest(channel)); err != nil {
+			return fmt.Errorf("订阅%s失败: %w", channel, err)
+		}
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(wsPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := send(wsRequest{Time: time.Now().Unix(), Channel: "spot.ping"}); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	subscribed := make(map[string]bool)
+	for {
+		conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			config.Logger.Warnw("解析Gate.io推送消息失败",
+				"error", err.Error(),
+				"message", string(data),
+			)
+			continue
+		}
+
+		switch msg.Event {
+		case "subscribe":
+			if msg.Error != nil {
+				return fmt.Errorf("订阅%s失败: %d - %s", msg.Channel, msg.Error.Code, msg.Error.Message)
+			}
+			subscribed[msg.Channel] = true
+			if subscribed[channelOrders] && subscribed[channelUserTrades] && !s.connected.Load() {
+				s.connected.Store(true)
+				config.Logger.Infow("Gate.io订单推送已连接", "url", s.url)
+			}
+		case "update":
+			s.handleUpdate(&msg, handler)
+		}
+	}
+}
+
+// subscribeRequest 构造带认证信息的订阅请求
+func (s *OrderStream) subscribeRequest(channel string) wsRequest {
+	now := time.Now().Unix()
+	return wsRequest{
+		Time:    now,
+		Channel: channel,
+		Event:   "subscribe",
+		Payload: []string{"!all"},
+		Auth: &wsAuth{
+			Method: "api_key",
+			Key:    s.apiKey,
+			Sign:   SignWS(s.apiSecret, channel, "subscribe", now),
+		},
+	}
+}
+
+// handleUpdate 将频道推送转换为统一的订单更新
+func (s *OrderStream) handleUpdate(msg *wsMessage, handler func(*types.OrderUpdate)) {
+	switch msg.Channel {
+	case channelOrders:
+		var orders []wsOrder
+		if err := json.Unmarshal(msg.Result, &orders); err != nil {
+			config.Logger.Warnw("解析Gate.io订单推送失败", "error", err.Error())
+			return
+		}
+		for i := range orders {
+			handler(s.orderUpdate(&orders[i]))
+		}
+	case channelUserTrades:
+		var trades []wsTrade
+		if err := json.Unmarshal(msg.Result, &trades); err != nil {
+			config.Logger.Warnw("解析Gate.io成交推送失败", "error", err.Error())
+			return
+		}
+		for i := range trades {
+			handler(s.tradeUpdate(&trades[i]))
+		}
+	}
+}
+
+// orderUpdate 订单推送携带累计成交信息，直接作为订单的最新状态
+func (s *OrderStream) orderUpdate(o *wsOrder) *types.OrderUpdate {
+	filledQty, _ := strconv.ParseFloat(o.FilledAmount, 64)
+
+	filledPrice, _ := strconv.ParseFloat(o.AvgDealPrice, 64)
+	if filledPrice == 0 && filledQty > 0 {
+		filledPrice, _ = strconv.ParseFloat(o.Price, 64)
+	}
+	fee, _ := strconv.ParseFloat(o.Fee, 64)
+
+	status := "open"
+	if o.Event == "finish" {
+		if o.FinishAs == "filled" {
+			status = "filled"
+		} else {
+			status = "canceled"
+		}
+
+		s.mu.Lock()
+		delete(s.fills, o.ID)
+		s.mu.Unlock()
+	}
+
+	return &types.OrderUpdate{
+		OrderResponse: types.OrderResponse{
+			OrderID:     o.ID,
+			Status:      status,
+			FilledQty:   filledQty,
+			FilledPrice: filledPrice,
+			Fee:         fee,
+			FeeCurrency: o.FeeCurrency,
+		},
+		Symbol:   o.CurrencyPair,
+		ClientID: o.Text,
+	}
+}
+
+// tradeUpdate 成交推送只包含单笔成交，累计后作为未完成订单的最新成交情况
+func (s *OrderStream) tradeUpdate(t *wsTrade) *types.OrderUpdate {
+	amount, _ := strconv.ParseFloat(t.Amount, 64)
+	price, _ := strconv.ParseFloat(t.Price, 64)
+	fee, _ := strconv.ParseFloat(t.Fee, 64)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, f := range s.fills {
+		if now.Sub(f.updatedAt) > wsFillRetention {
+			delete(s.fills, id)
+		}
+	}
+
+	f, ok := s.fills[t.OrderID]
+	if !ok {
+		f = &fillState{}
+		s.fills[t.OrderID] = f
+	}
+	f.qty += amount
+	f.quote += amount * price
+	f.fee += fee
+	f.feeCurrency = t.FeeCurrency
+	f.updatedAt = now
+
+	filledPrice := 0.0
+	if f.qty > 0 {
+		filledPrice = f.quote / f.qty
+	}
+
+	return &types.OrderUpdate{
+		OrderResponse: types.OrderResponse{
+			OrderID:     t.OrderID,
+			Status:      "open",
+			FilledQty:   f.qty,
+			FilledPrice: filledPrice,
+			Fee:         f.fee,
+			FeeCurrency: f.feeCurrency,
+		},
+		Symbol:   t.CurrencyPair,
+		ClientID: t.Text,
+	}
+}
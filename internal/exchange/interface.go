@@ -6,6 +6,7 @@ import (
 	"order_go/internal/exchange/types"
 	"order_go/internal/models"
 	"order_go/internal/utils/config"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -19,6 +20,9 @@ type OrderRequest struct {
 	Side         string  `json:"side"`          // 买卖方向 (buy/sell)
 	Type         string  `json:"type"`          // 订单类型 (limit/market)
 	PositionSide string  `json:"position_side"` // 持仓方向 (open/close)
+	ReduceOnly   bool    `json:"reduce_only"`   // 只减仓，仅永续合约有效
+	TimeInForce  string  `json:"time_in_force"` // 有效方式 (gtc/ioc/fok/poc)
+	ClientID     string  `json:"client_id"`     // 客户端订单ID，通常为系统订单号，各交易所实现按自身格式加前缀；为空时自动生成且下单不重试
 }
 
 // OrderResponse 下单响应
@@ -31,11 +35,50 @@ type OrderResponse struct {
 	FeeCurrency string  `json:"fee_currency"`  // 手续费币种
 }
 
+// NormalizeOrderMode 校验并规范化订单类型和有效方式
+// 未指定时默认为限价GTC；市价单只支持IOC和FOK，未指定时使用IOC；只做maker(poc)只能用于限价单
+func NormalizeOrderMode(orderType, timeInForce string) (string, string, error) {
+	orderType = strings.ToLower(orderType)
+	timeInForce = strings.ToLower(timeInForce)
+	
+	if orderType == "" {
+		orderType = string(types.OrderTypeLimit)
+	}
+	if orderType != string(types.OrderTypeLimit) && orderType != string(types.OrderTypeMarket) {
+		return "", "", fmt.Errorf("不支持的订单类型: %s", orderType)
+	}
+	
+	switch types.TimeInForce(timeInForce) {
+	case "":
+		if orderType == string(types.OrderTypeMarket) {
+			timeInForce = string(types.TimeInForceIOC)
+		} else {
+			timeInForce = string(types.TimeInForceGTC)
+		}
+	case types.TimeInForceGTC, types.TimeInForcePOC:
+		if orderType == string(types.OrderTypeMarket) {
+			return "", "", fmt.Errorf("市价单不支持有效方式: %s", timeInForce)
+		}
+	case types.TimeInForceIOC, types.TimeInForceFOK:
+	default:
+		return "", "", fmt.Errorf("不支持的有效方式: %s", timeInForce)
+	}
+	
+	return orderType, timeInForce, nil
+}
+
 // Exchange 交易所接口
 type Exchange interface {
 	// GetSymbolPrice 获取交易对价格
 	GetSymbolPrice(symbol string) (float64, error)
 	
+	// GetOrderBook 获取交易对盘口深度，depth为买卖盘各自返回的档位数
+	GetOrderBook(symbol string, depth int) (*OrderBook, error)
+	
+	// GetKlines 获取K线，按开盘时间从早到晚排列
+	// interval为统一的K线周期；since为零值时返回最近limit根，否则返回从since开始的至多limit根
+	GetKlines(symbol, interval string, since time.Time, limit int) ([]Kline, error)
+	
 	// CreateOrder 创建订单
 	CreateOrder(order *OrderRequest) (*OrderResponse, error)
 	
@@ -49,31 +92,149 @@ type Exchange interface {
 	// 返回可用余额、总余额和错误
 	GetBalance(currency string) (float64, float64, error)
 	
+	// GetAllBalances 获取账户中所有有余额的币种
+	GetAllBalances() ([]Balance, error)
+	
 	// GetPosition 获取持仓信息
 	GetPosition(symbol string) (*models.Position, error)
 }
 
-// NewGateIO 创建GateIO交易所实例
+// Balance 单个币种的账户余额
+type Balance = types.Balance
+
+// OrderBook 交易对的盘口深度
+type OrderBook = types.OrderBook
+
+// Kline 一根K线
+type Kline = types.Kline
+
+// balancesFromMap 将交易所客户端返回的"币种.available/币种.locked/币种.total"格式余额转换为按币种排序的列表
+func balancesFromMap(m map[string]float64) []Balance {
+	byCurrency := make(map[string]*Balance)
+	for key, value := range m {
+		i := strings.LastIndex(key, ".")
+		if i <= 0 {
+			continue
+		}
+		currency := key[:i]
+		b, ok := byCurrency[currency]
+		if !ok {
+			b = &Balance{Currency: currency}
+			byCurrency[currency] = b
+		}
+		switch key[i+1:] {
+		case "available":
+			b.Available = value
+		case "locked":
+			b.Locked = value
+		case "total":
+			b.Total = value
+		}
+	}
+	
+	balances := make([]Balance, 0, len(byCurrency))
+	for _, b := range byCurrency {
+		if b.Total == 0 {
+			b.Total = b.Available + b.Locked
+		}
+		balances = append(balances, *b)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Currency < balances[j].Currency
+	})
+	return balances
+}
+
+// OrderStreamer 支持通过WebSocket推送订单更新的交易所
+type OrderStreamer interface {
+	// SubscribeOrders 订阅订单更新，handler在推送协程中调用
+	SubscribeOrders(handler func(symbol string, update *OrderResponse)) error
+	
+	// OrderStreamConnected 推送连接是否可用，不可用时需要回退到轮询
+	OrderStreamConnected() bool
+	
+	// CloseOrderStream 关闭订单推送连接，交易所实例被替换或停用时调用
+	CloseOrderStream()
+}
+
+// OrderFinder 支持按客户端订单ID查询订单的交易所
+// 下单请求超时等结果不确定的情况下，用于确认订单是否已在交易所创建
+type OrderFinder interface {
+	// FindOrderByClientID 按下单时传入的客户端订单ID查询订单，订单不存在时返回ErrOrderNotFound
+	FindOrderByClientID(symbol, clientID string) (*OrderResponse, error)
+}
+
+// TickerProvider 支持一次获取全部交易对行情的交易所
+type TickerProvider interface {
+	// GetAllPrices 获取所有交易对的最新价，键为BTC_USDT格式的交易对
+	GetAllPrices() (map[string]float64, error)
+}
+
+// SymbolRule 交易所公布的交易对下单规则
+type SymbolRule = types.SymbolRule
+
+// SymbolRuleProvider 支持查询交易对下单规则的交易所
+type SymbolRuleProvider interface {
+	// GetSymbolRules 获取交易所全部交易对的下单规则
+	GetSymbolRules() ([]SymbolRule, error)
+}
+
+// FeeRates 账户的手续费率
+type FeeRates = types.FeeRates
+
+// FeeRateProvider 支持查询账户手续费率的交易所
+type FeeRateProvider interface {
+	// GetFeeRates 获取账户在交易对上的手续费率
+	GetFeeRates(symbol string) (*FeeRates, error)
+}
+
+// NewGateIO 创建GateIO现货交易所实例
 func NewGateIO() Exchange {
+	return newGateIOWithAccountType("spot")
+}
+
+// NewGateIOFutures 创建GateIO永续合约交易所实例
+// 优先使用gateio_futures配置，不存在时复用gateio配置
+func NewGateIOFutures() Exchange {
+	return newGateIOWithAccountType("futures")
+}
+
+// newGateIOWithAccountType 根据账户类型创建GateIO交易所实例
+func newGateIOWithAccountType(accountType string) Exchange {
 	// 从配置中读取API密钥等信息
-	gateCfg, exists := config.GetExchangeConfig("gateio")
+	gateCfg, exists := config.GetExchangeConfig("gateio_" + accountType)
+	if !exists {
+		gateCfg, exists = config.GetExchangeConfig("gateio")
+	}
 	if !exists {
 		// 如果配置不存在，使用空配置创建客户端
 		// 在实际生产环境中应该处理这种情况
-		emptyCfg := &config.ExchangeConfig{
-			ApiKey:      "",
-			ApiSecret:   "",
-			BaseURL:     "",
-			AccountType: "spot", // 默认使用现货账户
+		gateCfg = &config.ExchangeConfig{
+			ApiKey:    "",
+			ApiSecret: "",
+			BaseURL:   "",
 		}
-		return &GateIO{client: gateio.NewClient(emptyCfg)}
 	}
-	return &GateIO{client: gateio.NewClient(gateCfg)}
+	
+	return newGateIO(gateCfg, accountType)
+}
+
+// newGateIO 按给定配置创建GateIO交易所实例，配置中的账户类型会被覆盖
+func newGateIO(gateCfg *config.ExchangeConfig, accountType string) *GateIO {
+	gateCfg.AccountType = accountType
+	g := &GateIO{client: gateio.NewClient(gateCfg)}
+	
+	// 订单推送目前只支持现货，且需要API密钥认证
+	if accountType == "spot" && gateCfg.ApiKey != "" {
+		g.stream = gateio.NewOrderStream(gateCfg)
+	}
+	return g
 }
 
 // GateIO Gate.io交易所实现
 type GateIO struct {
 	client *gateio.Client
+	stream *gateio.OrderStream // 现货订单推送，未配置时为nil
 }
 
 // GetClient 获取内部的Gate.io客户端
@@ -86,15 +247,34 @@ func (g *GateIO) GetSymbolPrice(symbol string) (float64, error) {
 	return g.client.GetSymbolPrice(symbol)
 }
 
+// GetOrderBook 获取交易对盘口深度
+func (g *GateIO) GetOrderBook(symbol string, depth int) (*OrderBook, error) {
+	return g.client.GetOrderBook(symbol, depth)
+}
+
+// GetKlines 获取K线
+func (g *GateIO) GetKlines(symbol, interval string, since time.Time, limit int) ([]Kline, error) {
+	return g.client.GetKlines(symbol, interval, since, limit)
+}
+
 // CreateOrder 创建订单
 func (g *GateIO) CreateOrder(order *OrderRequest) (*OrderResponse, error) {
 	// 转换为gateio.Client使用的Order类型
 	gateOrder := &types.Order{
 		Symbol:   order.Symbol,
 		Side:     types.OrderSide(order.Side),
+		Type:     types.OrderType(order.Type),
 		Amount:   order.Amount,
 		Price:    order.Price,
-		ClientID: "t-" + strconv.FormatInt(time.Now().UnixNano(), 10), // 使用t-前缀加时间戳作为客户端ID
+		ClientID: gateClientID(order.ClientID),
+		
+		TimeInForce:  types.TimeInForce(order.TimeInForce),
+		PositionSide: order.PositionSide,
+		ReduceOnly:   order.ReduceOnly,
+	}
+	
+	if order.ClientID == "" {
+		gateOrder.ClientID = "t-" + strconv.FormatInt(time.Now().UnixNano(), 10) // 使用t-前缀加时间戳作为客户端ID
 	}
 	
 	// 调用gateio.Client的CreateOrder方法
@@ -114,6 +294,77 @@ func (g *GateIO) CreateOrder(order *OrderRequest) (*OrderResponse, error) {
 	}, nil
 }
 
+// gateClientID Gate.io要求自定义订单ID(text)以t-开头
+func gateClientID(clientID string) string {
+	if clientID == "" || strings.HasPrefix(clientID, "t-") {
+		return clientID
+	}
+	return "t-" + clientID
+}
+
+// FindOrderByClientID 按客户端订单ID查询订单
+func (g *GateIO) FindOrderByClientID(symbol, clientID string) (*OrderResponse, error) {
+	resp, err := g.client.GetOrderByClientID(symbol, gateClientID(clientID))
+	if err != nil {
+		return nil, err
+	}
+	
+	return &OrderResponse{
+		OrderID:     resp.OrderID,
+		Status:      resp.Status,
+		FilledQty:   resp.FilledQty,
+		FilledPrice: resp.FilledPrice,
+		Fee:         resp.Fee,
+		FeeCurrency: resp.FeeCurrency,
+	}, nil
+}
+
+// GetAllPrices 获取所有交易对的最新价
+func (g *GateIO) GetAllPrices() (map[string]float64, error) {
+	return g.client.GetAllPrices()
+}
+
+// GetSymbolRules 获取全部交易对的下单规则
+func (g *GateIO) GetSymbolRules() ([]SymbolRule, error) {
+	return g.client.GetSymbolRules()
+}
+
+// GetFeeRates 获取账户在交易对上的手续费率
+func (g *GateIO) GetFeeRates(symbol string) (*FeeRates, error) {
+	return g.client.GetFeeRates(symbol)
+}
+
+// SubscribeOrders 订阅现货订单推送
+func (g *GateIO) SubscribeOrders(handler func(symbol string, update *OrderResponse)) error {
+	if g.stream == nil {
+		return fmt.Errorf("当前账户不支持订单推送")
+	}
+	
+	g.stream.Start(func(update *types.OrderUpdate) {
+		handler(update.Symbol, &OrderResponse{
+			OrderID:     update.OrderID,
+			Status:      update.Status,
+			FilledQty:   update.FilledQty,
+			FilledPrice: update.FilledPrice,
+			Fee:         update.Fee,
+			FeeCurrency: update.FeeCurrency,
+		})
+	})
+	return nil
+}
+
+// OrderStreamConnected 订单推送连接是否可用
+func (g *GateIO) OrderStreamConnected() bool {
+	return g.stream != nil && g.stream.Connected()
+}
+
+// CloseOrderStream 关闭订单推送连接
+func (g *GateIO) CloseOrderStream() {
+	if g.stream != nil {
+		g.stream.Close()
+	}
+}
+
 // CancelOrder 取消订单
 func (g *GateIO) CancelOrder(symbol, orderID string) error {
 	return g.client.CancelOrder(symbol, orderID)
@@ -180,8 +431,22 @@ func (g *GateIO) GetBalance(currency string) (float64, float64, error) {
 	return available, total, nil
 }
 
+// GetAllBalances 获取账户中所有有余额的币种，永续合约账户只有结算币种
+func (g *GateIO) GetAllBalances() ([]Balance, error) {
+	balances, err := g.client.GetAccountBalance("")
+	if err != nil {
+		return nil, err
+	}
+	return balancesFromMap(balances), nil
+}
+
 // GetPosition 获取持仓信息
 func (g *GateIO) GetPosition(symbol string) (*models.Position, error) {
+	// 永续合约直接读取合约持仓
+	if g.client.IsFutures() {
+		return g.getFuturesPosition(symbol)
+	}
+	
 	// 从交易对中提取资产名称，例如HYPE_USDT中的HYPE
 	parts := strings.Split(symbol, "_")
 	if len(parts) < 1 {
@@ -234,4 +499,29 @@ func (g *GateIO) GetPosition(symbol string) (*models.Position, error) {
 		Leverage:   1, // 现货没有杠杆概念
 		MarginType: "spot", // 标记为现货
 	}, nil
-}
\ No newline at end of file
+}
+
+// getFuturesPosition 获取永续合约持仓信息
+// 空仓时也返回持仓对象（Size为0），以便调用方获取杠杆等设置
+func (g *GateIO) getFuturesPosition(symbol string) (*models.Position, error) {
+	position, err := g.client.GetFuturesPosition(symbol)
+	if err != nil {
+		return nil, err
+	}
+	
+	config.Logger.Infow("当前合约持仓状态",
+		"symbol", symbol,
+		"size", position.Size,
+		"entry_price", position.EntryPrice,
+		"leverage", position.Leverage,
+		"margin_type", position.MarginType,
+	)
+	
+	return &models.Position{
+		Symbol:     symbol,
+		Size:       position.Size,
+		EntryPrice: position.EntryPrice,
+		Leverage:   position.Leverage,
+		MarginType: position.MarginType,
+	}, nil
+}
@@ -0,0 +1,178 @@
+package exchange
+
+import (
+	"fmt"
+	"order_go/internal/exchange/okx"
+	"order_go/internal/exchange/types"
+	"order_go/internal/models"
+	"order_go/internal/utils/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewOKX 创建OKX现货交易所实例
+func NewOKX() Exchange {
+	// 从配置中读取API密钥、Passphrase等信息
+	okxCfg, exists := config.GetExchangeConfig("okx")
+	if !exists {
+		// 如果配置不存在，使用空配置创建客户端
+		okxCfg = &config.ExchangeConfig{}
+	}
+	return &OKX{client: okx.NewClient(okxCfg)}
+}
+
+// OKX OKX现货交易所实现
+type OKX struct {
+	client *okx.Client
+}
+
+// GetClient 获取内部的OKX客户端
+func (o *OKX) GetClient() *okx.Client {
+	return o.client
+}
+
+// GetSymbolPrice 获取交易对价格
+func (o *OKX) GetSymbolPrice(symbol string) (float64, error) {
+	return o.client.GetSymbolPrice(symbol)
+}
+
+// GetOrderBook 获取交易对盘口深度
+func (o *OKX) GetOrderBook(symbol string, depth int) (*OrderBook, error) {
+	return o.client.GetOrderBook(symbol, depth)
+}
+
+// GetKlines 获取K线
+func (o *OKX) GetKlines(symbol, interval string, since time.Time, limit int) ([]Kline, error) {
+	return o.client.GetKlines(symbol, interval, since, limit)
+}
+
+// GetAllPrices 获取所有现货交易对的最新价
+func (o *OKX) GetAllPrices() (map[string]float64, error) {
+	return o.client.GetAllPrices()
+}
+
+// CreateOrder 创建订单
+func (o *OKX) CreateOrder(order *OrderRequest) (*OrderResponse, error) {
+	clientID := okxClientID(order.ClientID)
+	if clientID == "" {
+		clientID = "t" + strconv.FormatInt(time.Now().UnixNano(), 10) // OKX客户端ID只允许字母和数字
+	}
+
+	resp, err := o.client.CreateOrder(&types.Order{
+		Symbol:      order.Symbol,
+		Side:        types.OrderSide(order.Side),
+		Type:        types.OrderType(order.Type),
+		Amount:      order.Amount,
+		Price:       order.Price,
+		ClientID:    clientID,
+		TimeInForce: types.TimeInForce(order.TimeInForce),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderResponse{
+		OrderID:     resp.OrderID,
+		Status:      resp.Status,
+		FilledQty:   resp.FilledQty,
+		FilledPrice: resp.FilledPrice,
+		Fee:         resp.Fee,
+		FeeCurrency: resp.FeeCurrency,
+	}, nil
+}
+
+// CancelOrder 取消订单
+func (o *OKX) CancelOrder(symbol, orderID string) error {
+	return o.client.CancelOrder(symbol, orderID)
+}
+
+// GetOrderStatus 获取订单状态
+func (o *OKX) GetOrderStatus(symbol, orderID string) (*OrderResponse, error) {
+	resp, err := o.client.GetOrderStatus(symbol, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderResponse{
+		OrderID:     resp.OrderID,
+		Status:      resp.Status,
+		FilledQty:   resp.FilledQty,
+		FilledPrice: resp.FilledPrice,
+		Fee:         resp.Fee,
+		FeeCurrency: resp.FeeCurrency,
+	}, nil
+}
+
+// FindOrderByClientID 按客户端订单ID查询订单
+func (o *OKX) FindOrderByClientID(symbol, clientID string) (*OrderResponse, error) {
+	resp, err := o.client.GetOrderByClientID(symbol, okxClientID(clientID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderResponse{
+		OrderID:     resp.OrderID,
+		Status:      resp.Status,
+		FilledQty:   resp.FilledQty,
+		FilledPrice: resp.FilledPrice,
+		Fee:         resp.Fee,
+		FeeCurrency: resp.FeeCurrency,
+	}, nil
+}
+
+// okxClientID 为系统订单号加上前缀作为客户端订单ID，OKX的clOrdId只允许字母和数字
+func okxClientID(clientID string) string {
+	if clientID == "" {
+		return ""
+	}
+	return "t" + clientID
+}
+
+// GetBalance 获取账户余额
+func (o *OKX) GetBalance(currency string) (float64, float64, error) {
+	return o.client.GetBalance(currency)
+}
+
+// GetAllBalances 获取账户中所有有余额的币种
+func (o *OKX) GetAllBalances() ([]Balance, error) {
+	balances, err := o.client.GetAccountBalance("")
+	if err != nil {
+		return nil, err
+	}
+	return balancesFromMap(balances), nil
+}
+
+// GetPosition 获取持仓信息
+// 现货持仓即基础币的总余额
+func (o *OKX) GetPosition(symbol string) (*models.Position, error) {
+	parts := strings.Split(symbol, "_")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("无效的交易对格式: %s", symbol)
+	}
+	asset := parts[0]
+
+	_, total, err := o.client.GetBalance(asset)
+	if err != nil {
+		return nil, fmt.Errorf("获取%s余额失败: %w", asset, err)
+	}
+
+	config.Logger.Infow("当前资产持仓状态",
+		"exchange", "okx",
+		"asset", asset,
+		"total", total,
+	)
+
+	// 如果没有持仓，返回null
+	if total <= 0 {
+		return nil, nil
+	}
+
+	return &models.Position{
+		Symbol:     symbol,
+		Size:       total,
+		EntryPrice: 0,      // 现货没有入场价格概念
+		Leverage:   1,      // 现货没有杠杆概念
+		MarginType: "spot", // 标记为现货
+	}, nil
+}
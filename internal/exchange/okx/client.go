@@ -0,0 +1,532 @@
+package okx
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"order_go/internal/exchange/types"
+	"order_go/internal/utils/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 默认的OKX API地址
+const defaultBaseURL = "https://www.okx.com"
+
+// APIError OKX接口返回的错误
+type APIError struct {
+	Code    string
+	Message string
+}
+
+// Error 实现error接口
+func (e *APIError) Error() string {
+	return fmt.Sprintf("okx api error: %s - %s", e.Code, e.Message)
+}
+
+// Unwrap 返回错误码对应的错误分类，未识别的错误码返回nil
+func (e *APIError) Unwrap() error {
+	switch e.Code {
+	case "51603":
+		return types.ErrOrderNotFound
+	case "51400", "51401", "51402": // 撤单失败：订单已成交、已撤销或不存在
+		return types.ErrOrderClosed
+	case "51008":
+		return types.ErrInsufficientFunds
+	case "51001", "51002", "51014":
+		return types.ErrInvalidSymbol
+	case "51020":
+		return types.ErrMinNotional
+	case "51000", "51006", "51121":
+		return types.ErrInvalidOrder
+	case "51016": // 客户端订单ID重复
+		return types.ErrOrderRejected
+	case "50011", "50061":
+		return types.ErrRateLimited
+	case "50001", "50004", "50013", "50026":
+		return types.ErrUnavailable
+	case "50100", "50101", "50102", "50103", "50104", "50105", "50111", "50113":
+		return types.ErrAuth
+	default:
+		return nil
+	}
+}
+
+// response OKX接口统一响应结构
+type response struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Client OKX现货客户端
+type Client struct {
+	apiKey     string
+	apiSecret  string
+	passphrase string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient 创建OKX客户端
+func NewClient(cfg *config.ExchangeConfig) *Client {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Client{
+		apiKey:     cfg.ApiKey,
+		apiSecret:  cfg.ApiSecret,
+		passphrase: cfg.Passphrase,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: cfg.RequestTimeout()},
+	}
+}
+
+// ToInstID 将系统交易对格式(BTC_USDT)转换为OKX产品ID(BTC-USDT)
+func ToInstID(symbol string) string {
+	return strings.ToUpper(strings.ReplaceAll(symbol, "_", "-"))
+}
+
+// Sign 计算OKX请求签名
+// 签名内容为 timestamp + method + requestPath + body，使用HMAC-SHA256后Base64编码
+func Sign(secret, timestamp, method, requestPath, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + method + requestPath + body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// doRequest 发送请求并将data字段解析到out中
+func (c *Client) doRequest(method, path string, params url.Values, payload interface{}, signed bool, out interface{}) error {
+	requestPath := path
+	if len(params) > 0 {
+		requestPath += "?" + params.Encode()
+	}
+
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("序列化请求参数失败: %w", err)
+		}
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+requestPath, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if signed {
+		timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+		req.Header.Set("OK-ACCESS-KEY", c.apiKey)
+		req.Header.Set("OK-ACCESS-SIGN", Sign(c.apiSecret, timestamp, method, requestPath, string(body)))
+		req.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+		req.Header.Set("OK-ACCESS-PASSPHRASE", c.passphrase)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求OKX接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取OKX响应失败: %w", err)
+	}
+
+	var result response
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return &types.HTTPError{Exchange: "okx", StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	if result.Code != "0" {
+		// 下单、撤单失败时code为1，具体原因在data的sCode和sMsg中
+		var results []orderResult
+		if json.Unmarshal(result.Data, &results) == nil && len(results) > 0 &&
+			results[0].SCode != "" && results[0].SCode != "0" {
+			return &APIError{Code: results[0].SCode, Message: results[0].SMsg}
+		}
+		return &APIError{Code: result.Code, Message: result.Msg}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("解析OKX响应失败: %w", err)
+	}
+	return nil
+}
+
+// GetSymbolPrice 获取交易对最新价格
+func (c *Client) GetSymbolPrice(symbol string) (float64, error) {
+	var tickers []struct {
+		InstID string `json:"instId"`
+		Last   string `json:"last"`
+	}
+
+	params := url.Values{}
+	params.Set("instId", ToInstID(symbol))
+	if err := c.doRequest(http.MethodGet, "/api/v5/market/ticker", params, nil, false, &tickers); err != nil {
+		return 0, err
+	}
+
+	if len(tickers) == 0 {
+		return 0, fmt.Errorf("未找到交易对 %s 的行情数据", symbol)
+	}
+
+	price, err := strconv.ParseFloat(tickers[0].Last, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析价格失败: %w", err)
+	}
+	return price, nil
+}
+
+// GetAllPrices 一次获取所有现货产品的最新价，交易对统一为BTC_USDT格式
+func (c *Client) GetAllPrices() (map[string]float64, error) {
+	var tickers []struct {
+		InstID string `json:"instId"`
+		Last   string `json:"last"`
+	}
+
+	params := url.Values{}
+	params.Set("instType", "SPOT")
+	if err := c.doRequest(http.MethodGet, "/api/v5/market/tickers", params, nil, false, &tickers); err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]float64, len(tickers))
+	for _, ticker := range tickers {
+		if price, err := strconv.ParseFloat(ticker.Last, 64); err == nil && price > 0 {
+			prices[strings.ReplaceAll(ticker.InstID, "-", "_")] = price
+		}
+	}
+	return prices, nil
+}
+
+// maxBookDepth OKX盘口接口单次最多返回的档位数
+const maxBookDepth = 400
+
+// GetOrderBook 获取交易对盘口深度
+func (c *Client) GetOrderBook(symbol string, depth int) (*types.OrderBook, error) {
+	var books []struct {
+		Bids [][]string `json:"bids"`
+		Asks [][]string `json:"asks"`
+		Ts   string     `json:"ts"`
+	}
+
+	if depth > maxBookDepth {
+		depth = maxBookDepth
+	}
+	params := url.Values{}
+	params.Set("instId", ToInstID(symbol))
+	params.Set("sz", strconv.Itoa(depth))
+	if err := c.doRequest(http.MethodGet, "/api/v5/market/books", params, nil, false, &books); err != nil {
+		return nil, err
+	}
+
+	if len(books) == 0 {
+		return nil, fmt.Errorf("未找到交易对 %s 的盘口数据", symbol)
+	}
+
+	bids, err := parseLevels(books[0].Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseLevels(books[0].Asks)
+	if err != nil {
+		return nil, err
+	}
+
+	bookTime := time.Now()
+	if ms, err := strconv.ParseInt(books[0].Ts, 10, 64); err == nil && ms > 0 {
+		bookTime = time.UnixMilli(ms)
+	}
+	return &types.OrderBook{Symbol: symbol, Bids: bids, Asks: asks, Time: bookTime}, nil
+}
+
+// parseLevels 解析盘口档位，每档为[价格, 数量, 已废弃字段, 订单数]
+func parseLevels(raw [][]string) ([]types.PriceLevel, error) {
+	levels := make([]types.PriceLevel, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 2 {
+			continue
+		}
+		price, err := strconv.ParseFloat(item[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("解析盘口价格失败: %w", err)
+		}
+		amount, err := strconv.ParseFloat(item[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("解析盘口数量失败: %w", err)
+		}
+		levels = append(levels, types.PriceLevel{Price: price, Amount: amount})
+	}
+	return levels, nil
+}
+
+// okxBars 统一K线周期对应的OKX周期参数，日线和周线使用UTC时间对齐
+var okxBars = map[string]string{
+	types.Interval1m:  "1m",
+	types.Interval5m:  "5m",
+	types.Interval15m: "15m",
+	types.Interval30m: "30m",
+	types.Interval1h:  "1H",
+	types.Interval4h:  "4H",
+	types.Interval1d:  "1Dutc",
+	types.Interval1w:  "1Wutc",
+}
+
+// maxKlineLimit OKX单次最多返回的K线数量
+const maxKlineLimit = 300
+
+// GetKlines 获取K线，按开盘时间从早到晚排列；since为零值时返回最近limit根，否则从since开始
+// 指定起始时间时使用历史K线接口，以after(早于该时间)和before(晚于该时间)限定范围
+func (c *Client) GetKlines(symbol, interval string, since time.Time, limit int) ([]types.Kline, error) {
+	bar, ok := okxBars[interval]
+	if !ok {
+		return nil, fmt.Errorf("不支持的K线周期: %s", interval)
+	}
+	if limit <= 0 || limit > maxKlineLimit {
+		limit = maxKlineLimit
+	}
+
+	params := url.Values{}
+	params.Set("instId", ToInstID(symbol))
+	params.Set("bar", bar)
+	params.Set("limit", strconv.Itoa(limit))
+	path := "/api/v5/market/candles"
+	if !since.IsZero() {
+		d, _ := types.IntervalDuration(interval)
+		path = "/api/v5/market/history-candles"
+		params.Set("before", strconv.FormatInt(since.UnixMilli()-1, 10))
+		params.Set("after", strconv.FormatInt(since.Add(d*time.Duration(limit)).UnixMilli(), 10))
+	}
+
+	// 每根K线为[开盘时间(毫秒), 开盘价, 最高价, 最低价, 收盘价, 成交量(基础币), 成交额(计价币), 成交额(计价币), 是否收盘]，从晚到早排列
+	var rows [][]string
+	if err := c.doRequest(http.MethodGet, path, params, nil, false, &rows); err != nil {
+		return nil, err
+	}
+
+	klines := make([]types.Kline, len(rows))
+	for i, row := range rows {
+		if len(row) < 7 {
+			return nil, fmt.Errorf("K线数据格式错误: %v", row)
+		}
+		values := make([]float64, 7)
+		for j := 0; j < 7; j++ {
+			v, err := strconv.ParseFloat(row[j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("解析K线失败: %w", err)
+			}
+			values[j] = v
+		}
+		klines[len(rows)-1-i] = types.NewKline(interval, time.UnixMilli(int64(values[0])), values[1], values[2], values[3], values[4], values[5], values[6])
+	}
+	return klines, nil
+}
+
+// orderResult 下单/撤单结果
+type orderResult struct {
+	OrdID   string `json:"ordId"`
+	ClOrdID string `json:"clOrdId"`
+	SCode   string `json:"sCode"`
+	SMsg    string `json:"sMsg"`
+}
+
+// CreateOrder 创建现货订单，支持市价、限价及IOC/FOK/只做maker
+func (c *Client) CreateOrder(order *types.Order) (*types.OrderResponse, error) {
+	payload := map[string]string{
+		"instId":  ToInstID(order.Symbol),
+		"tdMode":  "cash", // 现货非保证金模式
+		"side":    string(order.Side),
+		"ordType": ordType(order),
+		"px":      strconv.FormatFloat(order.Price, 'f', -1, 64),
+		"sz":      strconv.FormatFloat(order.Amount, 'f', -1, 64),
+	}
+
+	// 市价单不传价格，并指定数量以基础币计（现货市价买单默认按计价币计）
+	if payload["ordType"] == "market" {
+		delete(payload, "px")
+		payload["tgtCcy"] = "base_ccy"
+	}
+	if order.ClientID != "" {
+		payload["clOrdId"] = order.ClientID
+	}
+
+	var results []orderResult
+	if err := c.doRequest(http.MethodPost, "/api/v5/trade/order", nil, payload, true, &results); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("OKX下单未返回订单信息")
+	}
+	if results[0].SCode != "0" {
+		return nil, &APIError{Code: results[0].SCode, Message: results[0].SMsg}
+	}
+
+	return &types.OrderResponse{
+		OrderID: results[0].OrdID,
+		Status:  "open",
+	}, nil
+}
+
+// ordType 将订单类型和有效方式映射为OKX的ordType
+func ordType(order *types.Order) string {
+	if order.Type == types.OrderTypeMarket {
+		return "market"
+	}
+
+	switch order.TimeInForce {
+	case types.TimeInForceIOC:
+		return "ioc"
+	case types.TimeInForceFOK:
+		return "fok"
+	case types.TimeInForcePOC:
+		return "post_only"
+	default:
+		return "limit"
+	}
+}
+
+// CancelOrder 取消订单
+func (c *Client) CancelOrder(symbol, orderID string) error {
+	payload := map[string]string{
+		"instId": ToInstID(symbol),
+		"ordId":  orderID,
+	}
+
+	var results []orderResult
+	if err := c.doRequest(http.MethodPost, "/api/v5/trade/cancel-order", nil, payload, true, &results); err != nil {
+		return err
+	}
+
+	if len(results) > 0 && results[0].SCode != "0" {
+		return &APIError{Code: results[0].SCode, Message: results[0].SMsg}
+	}
+	return nil
+}
+
+// GetOrderStatus 获取订单状态
+func (c *Client) GetOrderStatus(symbol, orderID string) (*types.OrderResponse, error) {
+	params := url.Values{}
+	params.Set("instId", ToInstID(symbol))
+	params.Set("ordId", orderID)
+	return c.getOrder(params, orderID)
+}
+
+// GetOrderByClientID 按下单时的clOrdId查询订单
+func (c *Client) GetOrderByClientID(symbol, clientID string) (*types.OrderResponse, error) {
+	params := url.Values{}
+	params.Set("instId", ToInstID(symbol))
+	params.Set("clOrdId", clientID)
+	return c.getOrder(params, clientID)
+}
+
+// getOrder 查询订单详情，id仅用于订单不存在时的错误信息
+func (c *Client) getOrder(params url.Values, id string) (*types.OrderResponse, error) {
+	var orders []struct {
+		OrdID     string `json:"ordId"`
+		State     string `json:"state"`
+		AccFillSz string `json:"accFillSz"`
+		AvgPx     string `json:"avgPx"`
+		Fee       string `json:"fee"`
+		FeeCcy    string `json:"feeCcy"`
+	}
+
+	if err := c.doRequest(http.MethodGet, "/api/v5/trade/order", params, nil, true, &orders); err != nil {
+		return nil, err
+	}
+
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("%w: %s", types.ErrOrderNotFound, id)
+	}
+
+	order := orders[0]
+	filledQty, _ := strconv.ParseFloat(order.AccFillSz, 64)
+	filledPrice, _ := strconv.ParseFloat(order.AvgPx, 64)
+	fee, _ := strconv.ParseFloat(order.Fee, 64)
+
+	return &types.OrderResponse{
+		OrderID:     order.OrdID,
+		Status:      MapOrderState(order.State),
+		FilledQty:   filledQty,
+		FilledPrice: filledPrice,
+		Fee:         -fee, // OKX手续费为负数表示扣除
+		FeeCurrency: order.FeeCcy,
+	}, nil
+}
+
+// MapOrderState 将OKX订单状态映射为系统统一的订单状态
+func MapOrderState(state string) string {
+	switch state {
+	case "live":
+		return "open"
+	case "partially_filled":
+		return "partially_filled"
+	case "filled":
+		return "filled"
+	case "canceled", "mmp_canceled":
+		return "canceled"
+	default:
+		return state
+	}
+}
+
+// GetAccountBalance 获取账户余额
+// 返回格式与Gate.io客户端一致：币种.available / 币种.locked / 币种.total
+func (c *Client) GetAccountBalance(currency string) (map[string]float64, error) {
+	var accounts []struct {
+		Details []struct {
+			Ccy       string `json:"ccy"`
+			AvailBal  string `json:"availBal"`
+			FrozenBal string `json:"frozenBal"`
+		} `json:"details"`
+	}
+
+	params := url.Values{}
+	if currency != "" {
+		params.Set("ccy", currency)
+	}
+	if err := c.doRequest(http.MethodGet, "/api/v5/account/balance", params, nil, true, &accounts); err != nil {
+		return nil, fmt.Errorf("获取OKX账户余额失败: %w", err)
+	}
+
+	balances := make(map[string]float64)
+	for _, account := range accounts {
+		for _, detail := range account.Details {
+			available, _ := strconv.ParseFloat(detail.AvailBal, 64)
+			locked, _ := strconv.ParseFloat(detail.FrozenBal, 64)
+
+			// 只返回有余额的币种
+			if available > 0 || locked > 0 {
+				balances[detail.Ccy+".available"] = available
+				balances[detail.Ccy+".locked"] = locked
+				balances[detail.Ccy+".total"] = available + locked
+			}
+		}
+	}
+
+	return balances, nil
+}
+
+// GetBalance 获取指定币种的可用余额和总余额
+func (c *Client) GetBalance(currency string) (float64, float64, error) {
+	balances, err := c.GetAccountBalance(currency)
+	if err != nil {
+		return 0, 0, err
+	}
+	return balances[currency+".available"], balances[currency+".total"], nil
+}
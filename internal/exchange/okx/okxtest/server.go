@@ -0,0 +1,335 @@
+// Package okxtest 提供本地运行的OKX v5接口替身，用于在不访问真实交易所的情况下验证OKX适配器
+package okxtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"order_go/internal/exchange/okx"
+	"strconv"
+	"sync"
+)
+
+// Order 替身服务器中保存的订单
+type Order struct {
+	OrdID     string
+	ClOrdID   string
+	InstID    string
+	Side      string
+	Price     float64
+	Size      float64
+	State     string // live/partially_filled/filled/canceled
+	FilledQty float64
+	Fee       float64
+	FeeCcy    string
+}
+
+// Balance 币种余额
+type Balance struct {
+	Available float64
+	Frozen    float64
+}
+
+// Server OKX接口替身
+type Server struct {
+	*httptest.Server
+
+	APIKey     string
+	APISecret  string
+	Passphrase string
+
+	mu       sync.Mutex
+	prices   map[string]float64
+	balances map[string]Balance
+	orders   map[string]*Order
+	nextID   int64
+}
+
+// NewServer 启动OKX接口替身，使用给定的密钥校验签名
+func NewServer(apiKey, apiSecret, passphrase string) *Server {
+	s := &Server{
+		APIKey:     apiKey,
+		APISecret:  apiSecret,
+		Passphrase: passphrase,
+		prices:     make(map[string]float64),
+		balances:   make(map[string]Balance),
+		orders:     make(map[string]*Order),
+		nextID:     1000,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v5/market/ticker", s.handleTicker)
+	mux.HandleFunc("/api/v5/market/tickers", s.handleTickers)
+	mux.HandleFunc("/api/v5/trade/order", s.handleOrder)
+	mux.HandleFunc("/api/v5/trade/cancel-order", s.handleCancel)
+	mux.HandleFunc("/api/v5/account/balance", s.handleBalance)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetPrice 设置产品最新价，instID格式为BTC-USDT
+func (s *Server) SetPrice(instID string, price float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prices[instID] = price
+}
+
+// SetBalance 设置币种余额
+func (s *Server) SetBalance(ccy string, available, frozen float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[ccy] = Balance{Available: available, Frozen: frozen}
+}
+
+// FillOrder 将订单成交指定数量，成交量达到委托量时订单变为filled
+func (s *Server) FillOrder(ordID string, qty, fee float64, feeCcy string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[ordID]
+	if !ok {
+		return
+	}
+	order.FilledQty += qty
+	order.Fee += fee
+	order.FeeCcy = feeCcy
+	if order.FilledQty >= order.Size {
+		order.FilledQty = order.Size
+		order.State = "filled"
+	} else {
+		order.State = "partially_filled"
+	}
+}
+
+// GetOrder 获取替身中保存的订单
+func (s *Server) GetOrder(ordID string) (Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[ordID]
+	if !ok {
+		return Order{}, false
+	}
+	return *order, true
+}
+
+// writeResult 按OKX统一格式返回结果
+func writeResult(w http.ResponseWriter, code, msg string, data interface{}) {
+	if data == nil {
+		data = []interface{}{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code": code,
+		"msg":  msg,
+		"data": data,
+	})
+}
+
+// checkAuth 校验签名请求头，失败时写入错误响应
+func (s *Server) checkAuth(w http.ResponseWriter, r *http.Request, body string) bool {
+	timestamp := r.Header.Get("OK-ACCESS-TIMESTAMP")
+	expected := okx.Sign(s.APISecret, timestamp, r.Method, r.URL.RequestURI(), body)
+
+	if r.Header.Get("OK-ACCESS-KEY") != s.APIKey ||
+		r.Header.Get("OK-ACCESS-PASSPHRASE") != s.Passphrase ||
+		r.Header.Get("OK-ACCESS-SIGN") != expected {
+		writeResult(w, "50113", "Invalid Sign", nil)
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleTicker(w http.ResponseWriter, r *http.Request) {
+	instID := r.URL.Query().Get("instId")
+
+	s.mu.Lock()
+	price, ok := s.prices[instID]
+	s.mu.Unlock()
+
+	if !ok {
+		writeResult(w, "51001", "Instrument ID does not exist", nil)
+		return
+	}
+	writeResult(w, "0", "", []map[string]string{{
+		"instId": instID,
+		"last":   strconv.FormatFloat(price, 'f', -1, 64),
+	}})
+}
+
+func (s *Server) handleTickers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data := make([]map[string]string, 0, len(s.prices))
+	for instID, price := range s.prices {
+		data = append(data, map[string]string{
+			"instId": instID,
+			"last":   strconv.FormatFloat(price, 'f', -1, 64),
+		})
+	}
+	s.mu.Unlock()
+
+	writeResult(w, "0", "", data)
+}
+
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		s.handleGetOrder(w, r)
+		return
+	}
+
+	var req map[string]string
+	body, _ := readBody(r)
+	if !s.checkAuth(w, r, body) {
+		return
+	}
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		writeResult(w, "50002", "Invalid JSON", nil)
+		return
+	}
+
+	price, _ := strconv.ParseFloat(req["px"], 64)
+	size, _ := strconv.ParseFloat(req["sz"], 64)
+
+	s.mu.Lock()
+	if _, known := s.prices[req["instId"]]; !known {
+		s.mu.Unlock()
+		writeResult(w, "1", "All operations failed", []map[string]string{{
+			"ordId":   "",
+			"clOrdId": req["clOrdId"],
+			"sCode":   "51001",
+			"sMsg":    "Instrument ID does not exist",
+		}})
+		return
+	}
+	s.nextID++
+	ordID := strconv.FormatInt(s.nextID, 10)
+	s.orders[ordID] = &Order{
+		OrdID:   ordID,
+		ClOrdID: req["clOrdId"],
+		InstID:  req["instId"],
+		Side:    req["side"],
+		Price:   price,
+		Size:    size,
+		State:   "live",
+	}
+	s.mu.Unlock()
+
+	writeResult(w, "0", "", []map[string]string{{
+		"ordId":   ordID,
+		"clOrdId": req["clOrdId"],
+		"sCode":   "0",
+		"sMsg":    "Order placed",
+	}})
+}
+
+func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAuth(w, r, "") {
+		return
+	}
+
+	s.mu.Lock()
+	order, ok := s.findOrder(r.URL.Query().Get("ordId"), r.URL.Query().Get("clOrdId"))
+	var data []map[string]string
+	if ok {
+		avgPx := ""
+		if order.FilledQty > 0 {
+			avgPx = strconv.FormatFloat(order.Price, 'f', -1, 64)
+		}
+		data = []map[string]string{{
+			"ordId":     order.OrdID,
+			"state":     order.State,
+			"accFillSz": strconv.FormatFloat(order.FilledQty, 'f', -1, 64),
+			"avgPx":     avgPx,
+			"fee":       strconv.FormatFloat(-order.Fee, 'f', -1, 64),
+			"feeCcy":    order.FeeCcy,
+		}}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeResult(w, "51603", "Order does not exist", nil)
+		return
+	}
+	writeResult(w, "0", "", data)
+}
+
+// findOrder 按ordId或clOrdId查找订单，调用方需持有锁
+func (s *Server) findOrder(ordID, clOrdID string) (*Order, bool) {
+	if ordID != "" || clOrdID == "" {
+		order, ok := s.orders[ordID]
+		return order, ok
+	}
+	for _, order := range s.orders {
+		if order.ClOrdID == clOrdID {
+			return order, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	var req map[string]string
+	body, _ := readBody(r)
+	if !s.checkAuth(w, r, body) {
+		return
+	}
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		writeResult(w, "50002", "Invalid JSON", nil)
+		return
+	}
+
+	s.mu.Lock()
+	order, ok := s.orders[req["ordId"]]
+	open := ok && (order.State == "live" || order.State == "partially_filled")
+	if open {
+		order.State = "canceled"
+	}
+	s.mu.Unlock()
+
+	// 与OKX一致，撤单失败时code为1，原因在sCode中
+	if !open {
+		writeResult(w, "1", "All operations failed", []map[string]string{{
+			"ordId": req["ordId"],
+			"sCode": "51400",
+			"sMsg":  "Order cancellation failed as the order has been filled, canceled or does not exist",
+		}})
+		return
+	}
+	writeResult(w, "0", "", []map[string]string{{"ordId": req["ordId"], "sCode": "0", "sMsg": ""}})
+}
+
+func (s *Server) handleBalance(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAuth(w, r, "") {
+		return
+	}
+
+	ccy := r.URL.Query().Get("ccy")
+
+	s.mu.Lock()
+	details := make([]map[string]string, 0, len(s.balances))
+	for currency, balance := range s.balances {
+		if ccy != "" && currency != ccy {
+			continue
+		}
+		details = append(details, map[string]string{
+			"ccy":       currency,
+			"availBal":  strconv.FormatFloat(balance.Available, 'f', -1, 64),
+			"frozenBal": strconv.FormatFloat(balance.Frozen, 'f', -1, 64),
+		})
+	}
+	s.mu.Unlock()
+
+	writeResult(w, "0", "", []map[string]interface{}{{"details": details}})
+}
+
+// readBody 读取请求体
+func readBody(r *http.Request) (string, error) {
+	if r.Body == nil {
+		return "", nil
+	}
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	return string(body), err
+}
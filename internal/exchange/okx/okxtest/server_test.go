@@ -0,0 +1,165 @@
+package okxtest_test
+
+import (
+	"errors"
+	"math"
+	"order_go/internal/exchange/okx"
+	"order_go/internal/exchange/okx/okxtest"
+	"order_go/internal/exchange/types"
+	"order_go/internal/utils/config"
+	"testing"
+)
+
+const (
+	testKey        = "test-key"
+	testSecret     = "test-secret"
+	testPassphrase = "test-passphrase"
+)
+
+// newClient 启动替身并创建指向它的客户端，passphrase为空时使用正确的passphrase
+func newClient(t *testing.T, passphrase string) (*okxtest.Server, *okx.Client) {
+	t.Helper()
+	srv := okxtest.NewServer(testKey, testSecret, testPassphrase)
+	t.Cleanup(srv.Close)
+
+	if passphrase == "" {
+		passphrase = testPassphrase
+	}
+	client := okx.NewClient(&config.ExchangeConfig{
+		ApiKey:     testKey,
+		ApiSecret:  testSecret,
+		Passphrase: passphrase,
+		BaseURL:    srv.URL,
+	})
+	return srv, client
+}
+
+// TestOrderLifecycle 下单时交易对转换为OKX产品ID，部分成交后查询，撤单后状态为canceled
+func TestOrderLifecycle(t *testing.T) {
+	srv, client := newClient(t, "")
+	srv.SetPrice("BTC-USDT", 50000)
+
+	created, err := client.CreateOrder(&types.Order{
+		Symbol:   "btc_usdt",
+		Side:     types.OrderSideBuy,
+		Price:    49000,
+		Amount:   0.01,
+		ClientID: "lifecycle1",
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	order, ok := srv.GetOrder(created.OrderID)
+	if !ok || order.InstID != "BTC-USDT" || order.ClOrdID != "lifecycle1" || order.Side != "buy" || order.Size != 0.01 {
+		t.Fatalf("替身中的订单不正确: %+v", order)
+	}
+
+	srv.FillOrder(created.OrderID, 0.004, 0.000008, "BTC")
+	status, err := client.GetOrderStatus("BTC_USDT", created.OrderID)
+	if err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	if status.Status != "partially_filled" || status.FilledQty != 0.004 || status.FilledPrice != 49000 {
+		t.Fatalf("部分成交后状态不正确: %+v", status)
+	}
+	// OKX以负数表示扣除的手续费，客户端返回正数
+	if math.Abs(status.Fee-0.000008) > 1e-12 || status.FeeCurrency != "BTC" {
+		t.Fatalf("手续费不正确: %+v", status)
+	}
+
+	if err := client.CancelOrder("BTC_USDT", created.OrderID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	status, err = client.GetOrderStatus("BTC_USDT", created.OrderID)
+	if err != nil {
+		t.Fatalf("GetOrderStatus: %v", err)
+	}
+	if status.Status != "canceled" || status.FilledQty != 0.004 {
+		t.Fatalf("撤单后状态不正确: %+v", status)
+	}
+}
+
+// TestCancelUnknownOrder 撤单失败的原因在data中的sCode里
+func TestCancelUnknownOrder(t *testing.T) {
+	_, client := newClient(t, "")
+
+	err := client.CancelOrder("BTC_USDT", "missing")
+	var apiErr *okx.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "51400" {
+		t.Fatalf("撤销不存在的订单应返回sCode 51400, got %v", err)
+	}
+}
+
+// TestWrongPassphrase 签名请求必须带上正确的passphrase
+func TestWrongPassphrase(t *testing.T) {
+	srv, client := newClient(t, "wrong-passphrase")
+	srv.SetBalance("USDT", 100, 0)
+
+	_, _, err := client.GetBalance("USDT")
+	var apiErr *okx.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "50113" {
+		t.Fatalf("passphrase错误应返回50113, got %v", err)
+	}
+}
+
+// TestGetBalance 余额按可用和冻结相加得到总额
+func TestGetBalance(t *testing.T) {
+	srv, client := newClient(t, "")
+	srv.SetBalance("USDT", 100, 25)
+	srv.SetBalance("BTC", 0.5, 0)
+
+	available, total, err := client.GetBalance("USDT")
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	if available != 100 || total != 125 {
+		t.Fatalf("USDT余额不正确: available=%v total=%v", available, total)
+	}
+}
+
+// TestMapOrderState OKX订单状态映射为OrderMonitor使用的状态
+func TestMapOrderState(t *testing.T) {
+	cases := map[string]string{
+		"live":             "open",
+		"partially_filled": "partially_filled",
+		"filled":           "filled",
+		"canceled":         "canceled",
+		"mmp_canceled":     "canceled",
+	}
+	for state, want := range cases {
+		if got := okx.MapOrderState(state); got != want {
+			t.Errorf("MapOrderState(%q) = %q, want %q", state, got, want)
+		}
+	}
+}
+
+// TestCancelClosedOrder 撤销已撤销的订单时顶层code为1，错误分类取自data中的sCode
+func TestCancelClosedOrder(t *testing.T) {
+	srv, client := newClient(t, "")
+	srv.SetPrice("BTC-USDT", 50000)
+
+	created, err := client.CreateOrder(&types.Order{Symbol: "BTC_USDT", Side: types.OrderSideSell, Price: 51000, Amount: 0.01})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if err := client.CancelOrder("BTC_USDT", created.OrderID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+
+	err = client.CancelOrder("BTC_USDT", created.OrderID)
+	var apiErr *okx.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "51400" || !errors.Is(err, types.ErrOrderClosed) {
+		t.Fatalf("重复撤单应返回sCode 51400和ErrOrderClosed, got %v", err)
+	}
+}
+
+// TestCreateUnknownInstrument 下单失败时顶层code为1，错误分类取自data中的sCode
+func TestCreateUnknownInstrument(t *testing.T) {
+	_, client := newClient(t, "")
+
+	_, err := client.CreateOrder(&types.Order{Symbol: "NOPE_USDT", Side: types.OrderSideBuy, Price: 1, Amount: 1})
+	var apiErr *okx.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "51001" || !errors.Is(err, types.ErrInvalidSymbol) {
+		t.Fatalf("未知产品应返回sCode 51001和ErrInvalidSymbol, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package exchange
+
+import (
+	"fmt"
+	"order_go/internal/exchange/paper"
+	"order_go/internal/exchange/types"
+	"order_go/internal/models"
+	"order_go/internal/utils/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewPaper 创建模拟盘交易所实例
+// 行情取自price_source指定的真实交易所，下单和余额均在本地模拟
+func NewPaper() Exchange {
+	paperCfg, exists := config.GetExchangeConfig("paper")
+	if !exists {
+		paperCfg = &config.ExchangeConfig{}
+	}
+	return newPaper(paperCfg)
+}
+
+// newPaper 按给定配置创建模拟盘交易所实例
+func newPaper(paperCfg *config.ExchangeConfig) *Paper {
+	var priceSource Exchange
+	switch paperCfg.PriceSource {
+	case "binance":
+		priceSource = NewBinance()
+	case "okx":
+		priceSource = NewOKX()
+	default:
+		priceSource = NewGateIO()
+	}
+
+	return &Paper{
+		client:      paper.NewClient(paperCfg, priceSource.GetSymbolPrice),
+		priceSource: priceSource,
+	}
+}
+
+// Paper 模拟盘交易所实现
+type Paper struct {
+	client      *paper.Client
+	priceSource Exchange // 行情来源交易所
+}
+
+// GetClient 获取内部的模拟盘客户端
+func (p *Paper) GetClient() *paper.Client {
+	return p.client
+}
+
+// GetSymbolPrice 获取交易对价格
+func (p *Paper) GetSymbolPrice(symbol string) (float64, error) {
+	return p.client.GetSymbolPrice(symbol)
+}
+
+// GetOrderBook 使用行情来源交易所的盘口
+func (p *Paper) GetOrderBook(symbol string, depth int) (*OrderBook, error) {
+	return p.priceSource.GetOrderBook(symbol, depth)
+}
+
+// GetKlines 使用行情来源交易所的K线
+func (p *Paper) GetKlines(symbol, interval string, since time.Time, limit int) ([]Kline, error) {
+	return p.priceSource.GetKlines(symbol, interval, since, limit)
+}
+
+// GetAllPrices 从行情来源交易所批量获取最新价，行情来源不支持时返回错误
+func (p *Paper) GetAllPrices() (map[string]float64, error) {
+	provider, ok := p.priceSource.(TickerProvider)
+	if !ok {
+		return nil, fmt.Errorf("行情来源不支持批量获取行情")
+	}
+	return provider.GetAllPrices()
+}
+
+// GetFeeRates 获取模拟盘配置的手续费率
+func (p *Paper) GetFeeRates(symbol string) (*FeeRates, error) {
+	return p.client.GetFeeRates(), nil
+}
+
+// CreateOrder 创建订单
+func (p *Paper) CreateOrder(order *OrderRequest) (*OrderResponse, error) {
+	clientID := paperClientID(order.ClientID)
+	if clientID == "" {
+		clientID = "t-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+
+	resp, err := p.client.CreateOrder(&types.Order{
+		Symbol:      order.Symbol,
+		Side:        types.OrderSide(order.Side),
+		Type:        types.OrderType(order.Type),
+		Amount:      order.Amount,
+		Price:       order.Price,
+		ClientID:    clientID,
+		TimeInForce: types.TimeInForce(order.TimeInForce),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderResponse{
+		OrderID:     resp.OrderID,
+		Status:      resp.Status,
+		FilledQty:   resp.FilledQty,
+		FilledPrice: resp.FilledPrice,
+		Fee:         resp.Fee,
+		FeeCurrency: resp.FeeCurrency,
+	}, nil
+}
+
+// CancelOrder 取消订单
+func (p *Paper) CancelOrder(symbol, orderID string) error {
+	return p.client.CancelOrder(symbol, orderID)
+}
+
+// GetOrderStatus 获取订单状态
+func (p *Paper) GetOrderStatus(symbol, orderID string) (*OrderResponse, error) {
+	resp, err := p.client.GetOrderStatus(symbol, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderResponse{
+		OrderID:     resp.OrderID,
+		Status:      resp.Status,
+		FilledQty:   resp.FilledQty,
+		FilledPrice: resp.FilledPrice,
+		Fee:         resp.Fee,
+		FeeCurrency: resp.FeeCurrency,
+	}, nil
+}
+
+// FindOrderByClientID 按客户端订单ID查询订单
+func (p *Paper) FindOrderByClientID(symbol, clientID string) (*OrderResponse, error) {
+	resp, err := p.client.GetOrderByClientID(symbol, paperClientID(clientID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderResponse{
+		OrderID:     resp.OrderID,
+		Status:      resp.Status,
+		FilledQty:   resp.FilledQty,
+		FilledPrice: resp.FilledPrice,
+		Fee:         resp.Fee,
+		FeeCurrency: resp.FeeCurrency,
+	}, nil
+}
+
+// paperClientID 为系统订单号加上前缀作为客户端订单ID
+func paperClientID(clientID string) string {
+	if clientID == "" {
+		return ""
+	}
+	return "t-" + clientID
+}
+
+// GetBalance 获取账户余额
+func (p *Paper) GetBalance(currency string) (float64, float64, error) {
+	return p.client.GetBalance(currency)
+}
+
+// GetAllBalances 获取账户中所有有余额的币种
+func (p *Paper) GetAllBalances() ([]Balance, error) {
+	balances, err := p.client.GetAccountBalance("")
+	if err != nil {
+		return nil, err
+	}
+	return balancesFromMap(balances), nil
+}
+
+// GetPosition 获取持仓信息
+// 现货持仓即基础币的总余额
+func (p *Paper) GetPosition(symbol string) (*models.Position, error) {
+	parts := strings.Split(symbol, "_")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("无效的交易对格式: %s", symbol)
+	}
+
+	_, total, err := p.client.GetBalance(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// 如果没有持仓，返回null
+	if total <= 0 {
+		return nil, nil
+	}
+
+	return &models.Position{
+		Symbol:     symbol,
+		Size:       total,
+		EntryPrice: 0,      // 现货没有入场价格概念
+		Leverage:   1,      // 现货没有杠杆概念
+		MarginType: "spot", // 标记为现货
+	}, nil
+}
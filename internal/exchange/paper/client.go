@@ -0,0 +1,373 @@
+// Package paper 实现模拟盘交易：余额保存在内存中，限价单在价格穿越委托价时按委托价成交
+package paper
+
+import (
+	"fmt"
+	"order_go/internal/exchange/types"
+	"order_go/internal/utils/config"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrOrderNotFound 订单不存在
+	ErrOrderNotFound = types.ErrOrderNotFound
+
+	// ErrInsufficientBalance 模拟账户余额不足
+	ErrInsufficientBalance = fmt.Errorf("模拟账户: %w", types.ErrInsufficientFunds)
+)
+
+// PriceSource 行情来源，返回交易对最新价
+type PriceSource func(symbol string) (float64, error)
+
+// order 模拟订单
+type order struct {
+	id          string
+	clientID    string
+	symbol      string
+	base        string
+	quote       string
+	side        types.OrderSide
+	price       float64
+	amount      float64
+	status      string // open/filled/canceled
+	filledQty   float64
+	fee         float64
+	feeCurrency string
+	locked      float64 // 下单时冻结的资金（买单为计价币，卖单为基础币）
+}
+
+// balance 币种余额
+type balance struct {
+	available float64
+	locked    float64
+}
+
+// Client 模拟盘客户端
+type Client struct {
+	priceSource  PriceSource
+	makerFeeRate float64
+	takerFeeRate float64
+
+	mu       sync.Mutex
+	balances map[string]*balance
+	orders   map[string]*order
+	nextID   int64
+}
+
+// NewClient 创建模拟盘客户端
+// 初始余额、手续费率从配置读取，行情由priceSource提供
+func NewClient(cfg *config.ExchangeConfig, priceSource PriceSource) *Client {
+	c := &Client{
+		priceSource:  priceSource,
+		makerFeeRate: cfg.MakerFeeRate,
+		takerFeeRate: cfg.TakerFeeRate,
+		balances:     make(map[string]*balance),
+		orders:       make(map[string]*order),
+	}
+
+	for currency, amount := range cfg.InitialBalances {
+		c.balances[strings.ToUpper(currency)] = &balance{available: amount}
+	}
+
+	return c
+}
+
+// GetFeeRates 获取配置的模拟手续费率，模拟盘的手续费从成交币种中扣除
+func (c *Client) GetFeeRates() *types.FeeRates {
+	return &types.FeeRates{Maker: c.makerFeeRate, Taker: c.takerFeeRate}
+}
+
+// getBalance 获取币种余额对象，不存在时创建（调用方需持有锁）
+func (c *Client) getBalance(currency string) *balance {
+	b, ok := c.balances[currency]
+	if !ok {
+		b = &balance{}
+		c.balances[currency] = b
+	}
+	return b
+}
+
+// splitSymbol 拆分交易对为基础币和计价币
+func splitSymbol(symbol string) (string, string, error) {
+	parts := strings.Split(symbol, "_")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("%w: 无效的交易对格式 %s", types.ErrInvalidSymbol, symbol)
+	}
+	return parts[0], parts[1], nil
+}
+
+// GetSymbolPrice 获取交易对价格，同时撮合该交易对的挂单
+func (c *Client) GetSymbolPrice(symbol string) (float64, error) {
+	price, err := c.priceSource(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.matchOrders(symbol, price)
+	c.mu.Unlock()
+
+	return price, nil
+}
+
+// CreateOrder 创建模拟订单并冻结资金
+// 市价单按最新价立即成交；限价单委托价已穿越当前价时立即按吃单费率成交，
+// IOC/FOK未能立即成交则撤销，只做maker(poc)会立即成交时拒绝下单
+func (c *Client) CreateOrder(o *types.Order) (*types.OrderResponse, error) {
+	base, quote, err := splitSymbol(o.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := c.priceSource(o.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取模拟盘行情失败: %w", err)
+	}
+
+	// 市价单以最新价作为成交价
+	limitPrice := o.Price
+	if o.Type == types.OrderTypeMarket {
+		limitPrice = price
+	}
+	if o.Amount <= 0 || limitPrice <= 0 {
+		return nil, fmt.Errorf("%w: 数量%.8f 价格%.8f", types.ErrInvalidOrder, o.Amount, limitPrice)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 冻结资金：买单冻结计价币（含最大手续费），卖单冻结基础币
+	var lockCurrency string
+	var lockAmount float64
+	if o.Side == types.OrderSideBuy {
+		lockCurrency = quote
+		lockAmount = limitPrice * o.Amount * (1 + c.takerFeeRate)
+	} else {
+		lockCurrency = base
+		lockAmount = o.Amount
+	}
+
+	ord := &order{
+		clientID: o.ClientID,
+		symbol:   o.Symbol,
+		base:     base,
+		quote:    quote,
+		side:     o.Side,
+		price:    limitPrice,
+		amount:   o.Amount,
+		status:   "open",
+	}
+
+	immediate := crosses(ord, price)
+	if o.TimeInForce == types.TimeInForcePOC && immediate {
+		return nil, fmt.Errorf("%w: 只做maker订单会立即成交，委托价%.8f 最新价%.8f", types.ErrOrderRejected, o.Price, price)
+	}
+
+	b := c.getBalance(lockCurrency)
+	if b.available < lockAmount {
+		return nil, fmt.Errorf("%w: %s 可用%.8f，需要%.8f", ErrInsufficientBalance, lockCurrency, b.available, lockAmount)
+	}
+	b.available -= lockAmount
+	b.locked += lockAmount
+	ord.locked = lockAmount
+
+	c.nextID++
+	ord.id = "paper-" + strconv.FormatInt(c.nextID, 10)
+	c.orders[ord.id] = ord
+
+	switch {
+	case immediate:
+		// 下单即成交的订单视为吃单
+		c.fill(ord, c.takerFeeRate)
+	case o.TimeInForce == types.TimeInForceIOC || o.TimeInForce == types.TimeInForceFOK:
+		// 无法立即成交的IOC/FOK订单直接撤销
+		c.release(ord)
+		ord.status = "canceled"
+	}
+
+	config.Logger.Infow("模拟盘下单",
+		"order_id", ord.id,
+		"symbol", ord.symbol,
+		"side", ord.side,
+		"price", ord.price,
+		"amount", ord.amount,
+		"status", ord.status,
+	)
+
+	return &types.OrderResponse{
+		OrderID:   ord.id,
+		Status:    ord.status,
+		FilledQty: ord.filledQty,
+	}, nil
+}
+
+// CancelOrder 取消模拟订单并解冻剩余资金
+func (c *Client) CancelOrder(symbol, orderID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ord, ok := c.orders[orderID]
+	if !ok {
+		return ErrOrderNotFound
+	}
+	if ord.status != "open" {
+		return fmt.Errorf("%w: 订单%s状态为%s", types.ErrOrderClosed, orderID, ord.status)
+	}
+
+	c.release(ord)
+	ord.status = "canceled"
+	return nil
+}
+
+// GetOrderStatus 获取模拟订单状态，查询前先按最新价撮合
+func (c *Client) GetOrderStatus(symbol, orderID string) (*types.OrderResponse, error) {
+	price, err := c.priceSource(symbol)
+	if err != nil {
+		config.Logger.Warnw("获取模拟盘行情失败，跳过撮合",
+			"symbol", symbol,
+			"error", err.Error(),
+		)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err == nil {
+		c.matchOrders(symbol, price)
+	}
+
+	ord, ok := c.orders[orderID]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+
+	resp := &types.OrderResponse{
+		OrderID:     ord.id,
+		Status:      ord.status,
+		FilledQty:   ord.filledQty,
+		Fee:         ord.fee,
+		FeeCurrency: ord.feeCurrency,
+	}
+	if ord.filledQty > 0 {
+		resp.FilledPrice = ord.price
+	}
+	return resp, nil
+}
+
+// GetOrderByClientID 按客户端订单ID查询模拟订单
+func (c *Client) GetOrderByClientID(symbol, clientID string) (*types.OrderResponse, error) {
+	c.mu.Lock()
+	orderID := ""
+	for id, ord := range c.orders {
+		if ord.symbol == symbol && ord.clientID == clientID {
+			orderID = id
+			break
+		}
+	}
+	c.mu.Unlock()
+
+	if orderID == "" {
+		return nil, ErrOrderNotFound
+	}
+	return c.GetOrderStatus(symbol, orderID)
+}
+
+// GetBalance 获取可用余额和总余额
+func (c *Client) GetBalance(currency string) (float64, float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.balances[currency]
+	if !ok {
+		return 0, 0, nil
+	}
+	return b.available, b.available + b.locked, nil
+}
+
+// GetAccountBalance 获取账户余额
+// 返回格式与Gate.io客户端一致：币种.available / 币种.locked / 币种.total
+func (c *Client) GetAccountBalance(currency string) (map[string]float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	balances := make(map[string]float64)
+	for cur, b := range c.balances {
+		if currency != "" && cur != currency {
+			continue
+		}
+		if b.available > 0 || b.locked > 0 {
+			balances[cur+".available"] = b.available
+			balances[cur+".locked"] = b.locked
+			balances[cur+".total"] = b.available + b.locked
+		}
+	}
+	return balances, nil
+}
+
+// matchOrders 用最新价撮合交易对的所有挂单（调用方需持有锁）
+func (c *Client) matchOrders(symbol string, price float64) {
+	for _, ord := range c.orders {
+		if ord.symbol == symbol && ord.status == "open" && crosses(ord, price) {
+			c.fill(ord, c.makerFeeRate)
+		}
+	}
+}
+
+// crosses 判断价格是否穿越委托价：买单价格不高于委托价、卖单价格不低于委托价时成交
+func crosses(ord *order, price float64) bool {
+	if ord.side == types.OrderSideBuy {
+		return price <= ord.price
+	}
+	return price >= ord.price
+}
+
+// fill 按委托价全部成交并结算余额（调用方需持有锁）
+// 手续费从收到的币种中扣除：买单扣基础币，卖单扣计价币，与Gate.io现货一致
+func (c *Client) fill(ord *order, feeRate float64) {
+	quoteAmount := ord.price * ord.amount
+
+	if ord.side == types.OrderSideBuy {
+		quote := c.getBalance(ord.quote)
+		quote.locked -= ord.locked
+		quote.available += ord.locked - quoteAmount // 退回多冻结的部分
+
+		ord.fee = ord.amount * feeRate
+		ord.feeCurrency = ord.base
+		c.getBalance(ord.base).available += ord.amount - ord.fee
+	} else {
+		c.getBalance(ord.base).locked -= ord.locked
+
+		ord.fee = quoteAmount * feeRate
+		ord.feeCurrency = ord.quote
+		c.getBalance(ord.quote).available += quoteAmount - ord.fee
+	}
+
+	ord.filledQty = ord.amount
+	ord.status = "filled"
+	ord.locked = 0
+
+	config.Logger.Infow("模拟盘订单成交",
+		"order_id", ord.id,
+		"symbol", ord.symbol,
+		"side", ord.side,
+		"price", ord.price,
+		"amount", ord.amount,
+		"fee", ord.fee,
+		"fee_currency", ord.feeCurrency,
+	)
+}
+
+// release 解冻订单冻结的资金（调用方需持有锁）
+func (c *Client) release(ord *order) {
+	currency := ord.base
+	if ord.side == types.OrderSideBuy {
+		currency = ord.quote
+	}
+
+	b := c.getBalance(currency)
+	b.locked -= ord.locked
+	b.available += ord.locked
+	ord.locked = 0
+}
@@ -0,0 +1,362 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"order_go/internal/models"
+	"order_go/internal/utils/config"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// 限流的接口分类，可在配置的rate_limits中按名称覆盖默认值
+const (
+	EndpointPrice       = "price"
+	EndpointCreateOrder = "create_order"
+	EndpointCancelOrder = "cancel_order"
+	EndpointOrderStatus = "order_status"
+	EndpointBalance     = "balance"
+	EndpointPosition    = "position"
+	EndpointSymbolRules = "symbol_rules"
+	EndpointFeeRates    = "fee_rates"
+	EndpointKlines      = "klines"
+)
+
+// defaultRateLimits 各接口默认的每秒请求数，低于Gate.io现货的公开限制
+var defaultRateLimits = map[string]float64{
+	EndpointPrice:       20,
+	EndpointCreateOrder: 10,
+	EndpointCancelOrder: 10,
+	EndpointOrderStatus: 10,
+	EndpointBalance:     10,
+	EndpointPosition:    10,
+	EndpointSymbolRules: 1,
+	EndpointFeeRates:    2,
+	EndpointKlines:      10,
+}
+
+const (
+	defaultMaxRetries = 3
+	retryBaseDelay    = 200 * time.Millisecond
+	retryMaxDelay     = 5 * time.Second
+)
+
+// 同一名称的交易所共用一组限流器，即使创建了多个实例也不会超出限制
+var (
+	limiterSets   = make(map[string]map[string]*rate.Limiter)
+	limiterSetsMu sync.Mutex
+)
+
+// RateLimited 带限流和重试的交易所装饰器
+type RateLimited struct {
+	Exchange
+	name       string
+	limiters   map[string]*rate.Limiter
+	maxRetries int
+}
+
+// NewRateLimited 为交易所加上按接口的令牌桶限流和临时性错误重试
+// name为配置中的交易所名称，用于读取rate_limits和max_retries，并在同名实例间共享限流器
+func NewRateLimited(name string, ex Exchange) Exchange {
+	cfg, _ := config.GetExchangeConfig(name)
+
+	maxRetries := defaultMaxRetries
+	if cfg != nil && cfg.MaxRetries > 0 {
+		maxRetries = cfg.MaxRetries
+	}
+
+	return &RateLimited{
+		Exchange:   ex,
+		name:       name,
+		limiters:   getLimiters(name, cfg),
+		maxRetries: maxRetries,
+	}
+}
+
+// getLimiters 获取交易所的限流器，首次使用时按配置创建
+func getLimiters(name string, cfg *config.ExchangeConfig) map[string]*rate.Limiter {
+	limiterSetsMu.Lock()
+	defer limiterSetsMu.Unlock()
+
+	if limiters, ok := limiterSets[name]; ok {
+		return limiters
+	}
+
+	limiters := make(map[string]*rate.Limiter, len(defaultRateLimits))
+	for endpoint, limit := range defaultRateLimits {
+		if cfg != nil && cfg.RateLimits[endpoint] > 0 {
+			limit = cfg.RateLimits[endpoint]
+		}
+		limiters[endpoint] = rate.NewLimiter(rate.Limit(limit), int(math.Ceil(limit)))
+	}
+	limiterSets[name] = limiters
+	return limiters
+}
+
+// Unwrap 获取被装饰的交易所
+func (r *RateLimited) Unwrap() Exchange {
+	return r.Exchange
+}
+
+// Unwrap 逐层去掉装饰器，返回最内层的交易所实现
+func Unwrap(ex Exchange) Exchange {
+	for {
+		w, ok := ex.(interface{ Unwrap() Exchange })
+		if !ok {
+			return ex
+		}
+		ex = w.Unwrap()
+	}
+}
+
+// call 限流后执行请求，临时性错误按指数退避重试
+func (r *RateLimited) call(endpoint string, retry bool, fn func() error) error {
+	limiter := r.limiters[endpoint]
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if waitErr := limiter.Wait(context.Background()); waitErr != nil {
+			return waitErr
+		}
+
+		err = fn()
+		var stop *stopRetry
+		if errors.As(err, &stop) {
+			return stop.err
+		}
+		if err == nil || !retry || attempt >= r.maxRetries || !IsRetryable(err) {
+			return err
+		}
+
+		delay := retryBaseDelay << attempt
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+		config.Logger.Warnw("交易所请求失败，稍后重试",
+			"exchange", r.name,
+			"endpoint", endpoint,
+			"attempt", attempt+1,
+			"retry_in", delay.String(),
+			"error", err.Error(),
+		)
+		time.Sleep(delay)
+	}
+}
+
+// stopRetry 请求函数要求立即结束重试，call返回其中的原始错误
+type stopRetry struct {
+	err error
+}
+
+// Error 实现error接口
+func (s *stopRetry) Error() string {
+	return s.err.Error()
+}
+
+// GetSymbolPrice 获取交易对价格
+func (r *RateLimited) GetSymbolPrice(symbol string) (float64, error) {
+	var price float64
+	err := r.call(EndpointPrice, true, func() error {
+		var err error
+		price, err = r.Exchange.GetSymbolPrice(symbol)
+		return err
+	})
+	return price, err
+}
+
+// GetOrderBook 获取交易对盘口深度，与行情接口共用限流器
+func (r *RateLimited) GetOrderBook(symbol string, depth int) (*OrderBook, error) {
+	var book *OrderBook
+	err := r.call(EndpointPrice, true, func() error {
+		var err error
+		book, err = r.Exchange.GetOrderBook(symbol, depth)
+		return err
+	})
+	return book, err
+}
+
+// GetKlines 获取K线
+func (r *RateLimited) GetKlines(symbol, interval string, since time.Time, limit int) ([]Kline, error) {
+	var klines []Kline
+	err := r.call(EndpointKlines, true, func() error {
+		var err error
+		klines, err = r.Exchange.GetKlines(symbol, interval, since, limit)
+		return err
+	})
+	return klines, err
+}
+
+// CreateOrder 创建订单
+// 下单不是幂等操作，只有带客户端订单ID时才重试，且只重试限流等交易所明确未下单的错误；
+// 结果不确定时立即返回，由调用方按客户端订单ID确认。刚创建的订单可能稍后才能查到，
+// 查不到就重新下单会重复下单，Gate.io也不校验text唯一
+func (r *RateLimited) CreateOrder(order *OrderRequest) (*OrderResponse, error) {
+	var resp *OrderResponse
+	err := r.call(EndpointCreateOrder, order.ClientID != "", func() error {
+		var err error
+		resp, err = r.Exchange.CreateOrder(order)
+		if IsOrderOutcomeUnknown(err) {
+			return &stopRetry{err: err}
+		}
+		return err
+	})
+	return resp, err
+}
+
+// CancelOrder 取消订单
+func (r *RateLimited) CancelOrder(symbol, orderID string) error {
+	return r.call(EndpointCancelOrder, true, func() error {
+		return r.Exchange.CancelOrder(symbol, orderID)
+	})
+}
+
+// GetOrderStatus 获取订单状态
+func (r *RateLimited) GetOrderStatus(symbol, orderID string) (*OrderResponse, error) {
+	var resp *OrderResponse
+	err := r.call(EndpointOrderStatus, true, func() error {
+		var err error
+		resp, err = r.Exchange.GetOrderStatus(symbol, orderID)
+		return err
+	})
+	return resp, err
+}
+
+// GetBalance 获取账户余额
+func (r *RateLimited) GetBalance(currency string) (float64, float64, error) {
+	var available, total float64
+	err := r.call(EndpointBalance, true, func() error {
+		var err error
+		available, total, err = r.Exchange.GetBalance(currency)
+		return err
+	})
+	return available, total, err
+}
+
+// GetAllBalances 获取账户中所有有余额的币种
+func (r *RateLimited) GetAllBalances() ([]Balance, error) {
+	var balances []Balance
+	err := r.call(EndpointBalance, true, func() error {
+		var err error
+		balances, err = r.Exchange.GetAllBalances()
+		return err
+	})
+	return balances, err
+}
+
+// GetPosition 获取持仓信息
+func (r *RateLimited) GetPosition(symbol string) (*models.Position, error) {
+	var position *models.Position
+	err := r.call(EndpointPosition, true, func() error {
+		var err error
+		position, err = r.Exchange.GetPosition(symbol)
+		return err
+	})
+	return position, err
+}
+
+// FindOrderByClientID 按客户端订单ID查询订单，被装饰的交易所不支持时返回错误
+func (r *RateLimited) FindOrderByClientID(symbol, clientID string) (*OrderResponse, error) {
+	finder, ok := r.Exchange.(OrderFinder)
+	if !ok {
+		return nil, fmt.Errorf("交易所不支持按客户端订单ID查询")
+	}
+
+	var resp *OrderResponse
+	err := r.call(EndpointOrderStatus, true, func() error {
+		var err error
+		resp, err = finder.FindOrderByClientID(symbol, clientID)
+		return err
+	})
+	return resp, err
+}
+
+// GetAllPrices 批量获取行情，被装饰的交易所不支持时返回错误
+func (r *RateLimited) GetAllPrices() (map[string]float64, error) {
+	provider, ok := r.Exchange.(TickerProvider)
+	if !ok {
+		return nil, fmt.Errorf("交易所不支持批量获取行情")
+	}
+
+	var prices map[string]float64
+	err := r.call(EndpointPrice, true, func() error {
+		var err error
+		prices, err = provider.GetAllPrices()
+		return err
+	})
+	return prices, err
+}
+
+// GetSymbolRules 获取交易对下单规则，被装饰的交易所不支持时返回错误
+func (r *RateLimited) GetSymbolRules() ([]SymbolRule, error) {
+	provider, ok := r.Exchange.(SymbolRuleProvider)
+	if !ok {
+		return nil, fmt.Errorf("交易所不支持查询交易对规则")
+	}
+
+	var rules []SymbolRule
+	err := r.call(EndpointSymbolRules, true, func() error {
+		var err error
+		rules, err = provider.GetSymbolRules()
+		return err
+	})
+	return rules, err
+}
+
+// GetFeeRates 获取账户手续费率，被装饰的交易所不支持时返回错误
+func (r *RateLimited) GetFeeRates(symbol string) (*FeeRates, error) {
+	provider, ok := r.Exchange.(FeeRateProvider)
+	if !ok {
+		return nil, fmt.Errorf("交易所不支持查询手续费率")
+	}
+
+	var rates *FeeRates
+	err := r.call(EndpointFeeRates, true, func() error {
+		var err error
+		rates, err = provider.GetFeeRates(symbol)
+		return err
+	})
+	return rates, err
+}
+
+// SubscribeOrders 转发订单推送订阅，被装饰的交易所不支持推送时返回错误
+func (r *RateLimited) SubscribeOrders(handler func(symbol string, update *OrderResponse)) error {
+	streamer, ok := r.Exchange.(OrderStreamer)
+	if !ok {
+		return fmt.Errorf("交易所不支持订单推送")
+	}
+	return streamer.SubscribeOrders(handler)
+}
+
+// OrderStreamConnected 订单推送连接是否可用
+func (r *RateLimited) OrderStreamConnected() bool {
+	streamer, ok := r.Exchange.(OrderStreamer)
+	return ok && streamer.OrderStreamConnected()
+}
+
+// CloseOrderStream 关闭被装饰交易所的订单推送连接
+func (r *RateLimited) CloseOrderStream() {
+	if streamer, ok := r.Exchange.(OrderStreamer); ok {
+		streamer.CloseOrderStream()
+	}
+}
+
+// IsRetryable 判断错误是否为可重试的临时性故障：超时、限流和交易所服务不可用
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnavailable) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
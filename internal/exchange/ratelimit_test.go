@@ -0,0 +1,70 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"order_go/internal/utils/config"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	config.Logger = zap.NewNop().Sugar()
+	os.Exit(m.Run())
+}
+
+// createOnly 只实现下单和按客户端订单ID查询的交易所，按顺序返回预设的下单错误
+// 刚创建的订单查询不到，与交易所的实际情况一致
+type createOnly struct {
+	Exchange
+	errs  []error
+	calls int
+}
+
+func (c *createOnly) CreateOrder(order *OrderRequest) (*OrderResponse, error) {
+	c.calls++
+	if c.calls <= len(c.errs) && c.errs[c.calls-1] != nil {
+		return nil, c.errs[c.calls-1]
+	}
+	return &OrderResponse{OrderID: "1", Status: "open"}, nil
+}
+
+func (c *createOnly) FindOrderByClientID(symbol, clientID string) (*OrderResponse, error) {
+	return nil, ErrOrderNotFound
+}
+
+// TestCreateOrderOutcomeUnknownNotRetried 下单结果不确定时不重试，交给调用方确认
+func TestCreateOrderOutcomeUnknownNotRetried(t *testing.T) {
+	inner := &createOnly{errs: []error{fmt.Errorf("创建订单失败: %w", ErrUnavailable)}}
+	ex := NewRateLimited("test-unknown", inner)
+
+	_, err := ex.CreateOrder(&OrderRequest{Symbol: "BTC_USDT", ClientID: "c1"})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("应返回原始错误, got %v", err)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("结果不确定的下单不能重试, got %d次下单", inner.calls)
+	}
+}
+
+// TestCreateOrderRateLimitedRetried 被限流的下单一定没有创建订单，带客户端订单ID时重试
+func TestCreateOrderRateLimitedRetried(t *testing.T) {
+	inner := &createOnly{errs: []error{ErrRateLimited}}
+	ex := NewRateLimited("test-rate-limited", inner)
+
+	resp, err := ex.CreateOrder(&OrderRequest{Symbol: "BTC_USDT", ClientID: "c1"})
+	if err != nil || resp.OrderID != "1" {
+		t.Fatalf("重试后应下单成功, got %+v, %v", resp, err)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("应重试一次, got %d次下单", inner.calls)
+	}
+
+	inner = &createOnly{errs: []error{ErrRateLimited}}
+	ex = NewRateLimited("test-rate-limited", inner)
+	if _, err := ex.CreateOrder(&OrderRequest{Symbol: "BTC_USDT"}); !errors.Is(err, ErrRateLimited) || inner.calls != 1 {
+		t.Fatalf("没有客户端订单ID时不重试, got %v, %d次下单", err, inner.calls)
+	}
+}
@@ -0,0 +1,9 @@
+package types
+
+// Balance 单个币种的账户余额
+type Balance struct {
+	Currency  string  `json:"currency"`
+	Available float64 `json:"available"` // 可用余额
+	Locked    float64 `json:"locked"`    // 冻结余额
+	Total     float64 `json:"total"`     // 总余额，合约账户包含未实现盈亏，不一定等于可用加冻结
+}
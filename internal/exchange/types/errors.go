@@ -0,0 +1,47 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// 交易所错误分类，各交易所适配器把接口错误映射为以下错误，调用方使用errors.Is判断
+var (
+	ErrOrderNotFound     = errors.New("订单不存在")
+	ErrOrderClosed       = errors.New("订单已结束")
+	ErrOrderRejected     = errors.New("订单被交易所拒绝")
+	ErrInsufficientFunds = errors.New("账户余额不足")
+	ErrInvalidSymbol     = errors.New("交易对不存在或不可交易")
+	ErrMinNotional       = errors.New("下单数量或金额低于交易所最小限制")
+	ErrInvalidOrder      = errors.New("下单参数无效")
+	ErrRateLimited       = errors.New("请求过于频繁，已被交易所限流")
+	ErrUnavailable       = errors.New("交易所服务暂时不可用")
+	ErrAuth              = errors.New("API密钥无效或权限不足")
+)
+
+// HTTPError 无法解析为接口错误的HTTP响应，按状态码归类
+type HTTPError struct {
+	Exchange   string
+	StatusCode int
+	Body       string
+}
+
+// Error 实现error接口
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s http error: %d - %s", e.Exchange, e.StatusCode, e.Body)
+}
+
+// Unwrap 返回状态码对应的错误分类
+func (e *HTTPError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrUnavailable
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuth
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,8 @@
+package types
+
+// FeeRates 账户在交易对上的手续费率，已按VIP等级和抵扣方式折算
+type FeeRates struct {
+	Maker    float64 `json:"maker"`    // 挂单费率，负数表示返佣
+	Taker    float64 `json:"taker"`    // 吃单费率
+	Deducted bool    `json:"deducted"` // 手续费由GT或点卡抵扣，不从成交的币种中扣除
+}
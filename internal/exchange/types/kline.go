@@ -0,0 +1,70 @@
+package types
+
+import "time"
+
+// 统一的K线周期，各交易所实现负责转换为自身的周期参数
+const (
+	Interval1m  = "1m"
+	Interval5m  = "5m"
+	Interval15m = "15m"
+	Interval30m = "30m"
+	Interval1h  = "1h"
+	Interval4h  = "4h"
+	Interval1d  = "1d"
+	Interval1w  = "1w"
+)
+
+// intervalDurations 各K线周期的时长
+var intervalDurations = map[string]time.Duration{
+	Interval1m:  time.Minute,
+	Interval5m:  5 * time.Minute,
+	Interval15m: 15 * time.Minute,
+	Interval30m: 30 * time.Minute,
+	Interval1h:  time.Hour,
+	Interval4h:  4 * time.Hour,
+	Interval1d:  24 * time.Hour,
+	Interval1w:  7 * 24 * time.Hour,
+}
+
+// IntervalDuration 返回K线周期的时长，不支持的周期返回false
+func IntervalDuration(interval string) (time.Duration, bool) {
+	d, ok := intervalDurations[interval]
+	return d, ok
+}
+
+// IntervalOfMinutes 按分钟数查找K线周期
+func IntervalOfMinutes(minutes int) (string, bool) {
+	for interval, d := range intervalDurations {
+		if d == time.Duration(minutes)*time.Minute {
+			return interval, true
+		}
+	}
+	return "", false
+}
+
+// Kline 一根K线
+type Kline struct {
+	OpenTime    time.Time `json:"open_time"`    // 开盘时间
+	Open        float64   `json:"open"`         // 开盘价
+	High        float64   `json:"high"`         // 最高价
+	Low         float64   `json:"low"`          // 最低价
+	Close       float64   `json:"close"`        // 收盘价，未收盘时为最新价
+	Volume      float64   `json:"volume"`       // 成交量（基础币）
+	QuoteVolume float64   `json:"quote_volume"` // 成交额（计价币）
+	Closed      bool      `json:"closed"`       // 是否已收盘
+}
+
+// NewKline 创建K线，按开盘时间和周期判断是否已收盘
+func NewKline(interval string, openTime time.Time, open, high, low, closePrice, volume, quoteVolume float64) Kline {
+	d, _ := IntervalDuration(interval)
+	return Kline{
+		OpenTime:    openTime,
+		Open:        open,
+		High:        high,
+		Low:         low,
+		Close:       closePrice,
+		Volume:      volume,
+		QuoteVolume: quoteVolume,
+		Closed:      !time.Now().Before(openTime.Add(d)),
+	}
+}
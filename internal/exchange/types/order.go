@@ -21,6 +21,16 @@ const (
     OrderTypeMarket OrderType = "market"
 )
 
+// TimeInForce 订单有效方式
+type TimeInForce string
+
+const (
+    TimeInForceGTC TimeInForce = "gtc" // 撤销前一直有效
+    TimeInForceIOC TimeInForce = "ioc" // 立即成交剩余撤销
+    TimeInForceFOK TimeInForce = "fok" // 全部成交否则撤销
+    TimeInForcePOC TimeInForce = "poc" // 只做maker（post-only）
+)
+
 // Order 统一的订单结构
 type Order struct {
     Symbol    string    `json:"symbol"`     // 交易对
@@ -29,6 +39,11 @@ type Order struct {
     Price     float64   `json:"price"`      // 价格
     Amount    float64   `json:"amount"`     // 数量
     ClientID  string    `json:"client_id"`  // 客户端订单ID
+    TimeInForce TimeInForce `json:"time_in_force"` // 有效方式，默认为gtc
+
+    // 以下字段仅用于永续合约
+    PositionSide string `json:"position_side"` // 持仓方向 (open/close)
+    ReduceOnly   bool   `json:"reduce_only"`   // 只减仓
 }
 
 // OrderResponse 下单响应
@@ -39,5 +54,12 @@ type OrderResponse struct {
     FilledPrice float64 `json:"filled_price"`  // 成交均价
     Fee         float64 `json:"fee"`           // 手续费
     FeeCurrency string  `json:"fee_currency"`  // 手续费币种
+    Fees        map[string]float64 `json:"fees,omitempty"` // 按币种的全部手续费，手续费使用了多个币种时Fee只是其中之一
     Error       error   `json:"-"`            // 错误信息
-}
\ No newline at end of file
+}
+// OrderUpdate 交易所推送的订单更新
+type OrderUpdate struct {
+    OrderResponse
+    Symbol   string `json:"symbol"`    // 交易对
+    ClientID string `json:"client_id"` // 客户端订单ID
+}
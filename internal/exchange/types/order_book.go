@@ -0,0 +1,33 @@
+package types
+
+import "time"
+
+// PriceLevel 盘口的一档价格
+type PriceLevel struct {
+	Price  float64 `json:"price"`  // 价格
+	Amount float64 `json:"amount"` // 挂单数量（基础币）
+}
+
+// OrderBook 交易对的盘口深度
+type OrderBook struct {
+	Symbol string       `json:"symbol"` // 交易对，统一为BTC_USDT格式
+	Bids   []PriceLevel `json:"bids"`   // 买盘，价格从高到低
+	Asks   []PriceLevel `json:"asks"`   // 卖盘，价格从低到高
+	Time   time.Time    `json:"time"`   // 盘口时间，交易所未返回时为获取时间
+}
+
+// BestBid 买一价，买盘为空时返回0
+func (b *OrderBook) BestBid() float64 {
+	if len(b.Bids) == 0 {
+		return 0
+	}
+	return b.Bids[0].Price
+}
+
+// BestAsk 卖一价，卖盘为空时返回0
+func (b *OrderBook) BestAsk() float64 {
+	if len(b.Asks) == 0 {
+		return 0
+	}
+	return b.Asks[0].Price
+}
@@ -0,0 +1,25 @@
+package types
+
+// 交易对在交易所的交易状态
+const (
+	SymbolStatusTradable   = "tradable"   // 可正常交易
+	SymbolStatusBuyable    = "buyable"    // 只能买入
+	SymbolStatusSellable   = "sellable"   // 只能卖出
+	SymbolStatusUntradable = "untradable" // 暂停交易
+	SymbolStatusDelisting  = "delisting"  // 即将下架
+)
+
+// SymbolRule 交易所公布的交易对下单规则
+type SymbolRule struct {
+	Symbol          string  `json:"symbol"`           // 交易对，统一为BTC_USDT格式
+	MinBaseAmount   float64 `json:"min_base_amount"`  // 最小下单数量（基础币），0表示交易所未限制
+	MinQuoteAmount  float64 `json:"min_quote_amount"` // 最小下单金额（计价币），0表示交易所未限制
+	AmountPrecision int     `json:"amount_precision"` // 数量小数位数
+	PricePrecision  int     `json:"price_precision"`  // 价格小数位数
+	Status          string  `json:"status"`           // 交易状态
+}
+
+// Tradable 交易对是否可以双向交易
+func (r *SymbolRule) Tradable() bool {
+	return r.Status == SymbolStatusTradable
+}
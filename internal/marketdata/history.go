@@ -0,0 +1,91 @@
+package marketdata
+
+import (
+	"order_go/internal/exchange/types"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"time"
+)
+
+// KlineCoverage 某个交易所、交易对和周期已记录K线的覆盖情况
+type KlineCoverage struct {
+	Exchange string    `json:"exchange"`
+	Symbol   string    `json:"symbol"`
+	Interval string    `json:"interval"`
+	Count    int64     `json:"count"`   // 已记录的K线数量
+	First    time.Time `json:"first"`   // 最早一根K线的开盘时间
+	Last     time.Time `json:"last"`    // 最近一根K线的开盘时间
+	Missing  int64     `json:"missing"` // 最早和最近之间缺少的K线数量，周线按7天计算
+	Lag      string    `json:"lag"`     // 最近一根K线收盘后经过的时间
+}
+
+// SnapshotCoverage 某个交易所和交易对已记录行情快照的覆盖情况
+type SnapshotCoverage struct {
+	Exchange string    `json:"exchange"`
+	Symbol   string    `json:"symbol"`
+	Count    int64     `json:"count"`
+	First    time.Time `json:"first"`
+	Last     time.Time `json:"last"`
+}
+
+// LoadKlines 读取已记录的K线，时间范围为[from, to)，按开盘时间从早到晚排列
+func LoadKlines(exchangeCode, symbol, interval string, from, to time.Time) ([]models.MarketKline, error) {
+	var klines []models.MarketKline
+	err := repository.DB.
+		Where("exchange = ? AND symbol = ? AND kline_interval = ? AND open_time >= ? AND open_time < ?", exchangeCode, symbol, interval, from, to).
+		Order("open_time").Find(&klines).Error
+	return klines, err
+}
+
+// LoadTickerSnapshots 读取已记录的行情快照，时间范围为[from, to)，按时间从早到晚排列
+func LoadTickerSnapshots(exchangeCode, symbol string, from, to time.Time) ([]models.TickerSnapshot, error) {
+	var snapshots []models.TickerSnapshot
+	err := repository.DB.
+		Where("exchange = ? AND symbol = ? AND time >= ? AND time < ?", exchangeCode, symbol, from, to).
+		Order("time").Find(&snapshots).Error
+	return snapshots, err
+}
+
+// GetKlineCoverage 统计已记录K线的覆盖情况，symbol为空时统计全部交易对
+func GetKlineCoverage(symbol string) ([]KlineCoverage, error) {
+	query := repository.DB.Model(&models.MarketKline{}).
+		Select("exchange, symbol, kline_interval AS interval, COUNT(*) AS count, MIN(open_time) AS first, MAX(open_time) AS last").
+		Group("exchange, symbol, kline_interval").
+		Order("symbol, exchange, kline_interval")
+	if symbol != "" {
+		query = query.Where("symbol = ?", symbol)
+	}
+
+	var coverage []KlineCoverage
+	if err := query.Scan(&coverage).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range coverage {
+		c := &coverage[i]
+		d, ok := types.IntervalDuration(c.Interval)
+		if !ok {
+			continue
+		}
+		if expected := int64(c.Last.Sub(c.First)/d) + 1; expected > c.Count {
+			c.Missing = expected - c.Count
+		}
+		c.Lag = time.Since(c.Last.Add(d)).Truncate(time.Second).String()
+	}
+	return coverage, nil
+}
+
+// GetSnapshotCoverage 统计已记录行情快照的覆盖情况，symbol为空时统计全部交易对
+func GetSnapshotCoverage(symbol string) ([]SnapshotCoverage, error) {
+	query := repository.DB.Model(&models.TickerSnapshot{}).
+		Select("exchange, symbol, COUNT(*) AS count, MIN(time) AS first, MAX(time) AS last").
+		Group("exchange, symbol").
+		Order("symbol, exchange")
+	if symbol != "" {
+		query = query.Where("symbol = ?", symbol)
+	}
+
+	var coverage []SnapshotCoverage
+	err := query.Scan(&coverage).Error
+	return coverage, err
+}
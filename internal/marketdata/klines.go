@@ -0,0 +1,151 @@
+// Package marketdata 提供带缓存的行情数据，供策略校验和下单计算使用
+package marketdata
+
+import (
+	"errors"
+	"fmt"
+	"order_go/internal/exchange"
+	"order_go/internal/exchange/types"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnsupportedCycle 信号周期无法对应到统一的K线周期
+var ErrUnsupportedCycle = errors.New("不支持的K线周期")
+
+const (
+	// maxCachedKlines 每个交易对和周期最多缓存的K线数量
+	maxCachedKlines = 500
+
+	// K线缓存的刷新间隔为周期的1/10，并限制在以下范围内
+	minKlineRefresh = 5 * time.Second
+	maxKlineRefresh = time.Minute
+)
+
+// klineKey K线缓存的键，不同账户的交易所实例分别缓存
+type klineKey struct {
+	ex       exchange.Exchange
+	symbol   string
+	interval string
+}
+
+// klineEntry 缓存的K线，mu保证同一个键同时只有一个请求访问交易所
+type klineEntry struct {
+	mu        sync.Mutex
+	klines    []exchange.Kline
+	requested int // 上次请求的数量，交易所返回的数量可能更少
+	fetchedAt time.Time
+}
+
+var klineCache sync.Map // klineKey -> *klineEntry
+
+// ResolveInterval 将信号或time_cycles表中的周期代码转换为统一的K线周期
+// 依次识别统一周期(5m、1h等)、time_cycles表中配置的分钟数和TradingView的周期格式(5、60、D、W)
+func ResolveInterval(cycle string) (string, error) {
+	if _, ok := types.IntervalDuration(cycle); ok {
+		return cycle, nil
+	}
+
+	var tc models.TimeCycle
+	if err := repository.DB.Where("code = ?", cycle).First(&tc).Error; err == nil {
+		if interval, ok := types.IntervalOfMinutes(tc.Minutes); ok {
+			return interval, nil
+		}
+		return "", fmt.Errorf("%w: %s(%d分钟)", ErrUnsupportedCycle, cycle, tc.Minutes)
+	}
+
+	switch strings.ToUpper(cycle) {
+	case "D", "1D":
+		return types.Interval1d, nil
+	case "W", "1W":
+		return types.Interval1w, nil
+	}
+	if minutes, err := strconv.Atoi(cycle); err == nil {
+		if interval, ok := types.IntervalOfMinutes(minutes); ok {
+			return interval, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedCycle, cycle)
+}
+
+// GetKlines 获取交易对最近limit根K线，按开盘时间从早到晚排列，最后一根可能尚未收盘
+// 刷新间隔内重复读取直接使用缓存，缓存数量不足时才重新请求
+func GetKlines(ex exchange.Exchange, symbol, cycle string, limit int) ([]exchange.Kline, error) {
+	interval, err := ResolveInterval(cycle)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxCachedKlines {
+		limit = maxCachedKlines
+	}
+
+	value, _ := klineCache.LoadOrStore(klineKey{ex: ex, symbol: symbol, interval: interval}, &klineEntry{})
+	entry := value.(*klineEntry)
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if time.Since(entry.fetchedAt) >= refreshInterval(interval) || limit > entry.requested {
+		// 不少于上次请求的数量，避免不同调用方交替请求不同数量时反复刷新
+		fetch := limit
+		if entry.requested > fetch {
+			fetch = entry.requested
+		}
+		klines, err := ex.GetKlines(symbol, interval, time.Time{}, fetch)
+		if err != nil {
+			return nil, fmt.Errorf("获取%s %s K线失败: %w", symbol, interval, err)
+		}
+		entry.klines = klines
+		entry.requested = fetch
+		entry.fetchedAt = time.Now()
+	}
+
+	return lastKlines(entry.klines, limit), nil
+}
+
+// GetClosedKlines 获取交易对最近limit根已收盘的K线，计算技术指标时使用
+func GetClosedKlines(ex exchange.Exchange, symbol, cycle string, limit int) ([]exchange.Kline, error) {
+	klines, err := GetKlines(ex, symbol, cycle, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(klines); n > 0 && !klines[n-1].Closed {
+		klines = klines[:n-1]
+	}
+	return lastKlines(klines, limit), nil
+}
+
+// ForgetExchange 删除交易所实例的K线缓存，交易所实例被替换或移除后调用，避免旧实例的缓存一直保留
+func ForgetExchange(ex exchange.Exchange) {
+	klineCache.Range(func(key, _ interface{}) bool {
+		if key.(klineKey).ex == ex {
+			klineCache.Delete(key)
+		}
+		return true
+	})
+}
+
+// refreshInterval 返回K线周期的缓存刷新间隔
+func refreshInterval(interval string) time.Duration {
+	d, _ := types.IntervalDuration(interval)
+	refresh := d / 10
+	if refresh < minKlineRefresh {
+		return minKlineRefresh
+	}
+	if refresh > maxKlineRefresh {
+		return maxKlineRefresh
+	}
+	return refresh
+}
+
+// lastKlines 返回最后limit根K线的副本，调用方可以修改
+func lastKlines(klines []exchange.Kline, limit int) []exchange.Kline {
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	return append([]exchange.Kline(nil), klines...)
+}
@@ -0,0 +1,293 @@
+package marketdata
+
+import (
+	"errors"
+	"fmt"
+	"order_go/internal/exchange"
+	"order_go/internal/exchange/types"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/utils/config"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultBackfill         = 7 * 24 * time.Hour
+	defaultSnapshotInterval = time.Minute
+
+	// klineSyncInterval 检查新收盘K线的间隔
+	klineSyncInterval = time.Minute
+
+	// gapScanInterval 检查历史K线缺口的间隔，缺口可能来自停机或交易所维护
+	gapScanInterval = time.Hour
+
+	// klinePageSize 每次向交易所请求的K线数量，交易所限制更小时按交易所的上限返回
+	klinePageSize = 500
+
+	// maxPagesPerFill 补齐一段K线时最多请求的页数，回溯时间很长时分多轮完成，避免占满限流
+	maxPagesPerFill = 20
+)
+
+// defaultIntervals 未配置时记录的K线周期
+var defaultIntervals = []string{types.Interval1m, types.Interval1h, types.Interval1d}
+
+// VenueResolver 返回交易对记录行情使用的交易所实例和交易所代码
+type VenueResolver func(symbol string) (exchange.Exchange, string, error)
+
+// Recorder 行情记录任务，为每个启用的交易对保存已收盘K线和行情快照
+type Recorder struct {
+	resolve          VenueResolver
+	intervals        []string
+	backfill         time.Duration
+	snapshotInterval time.Duration
+	lastGapScan      time.Time
+}
+
+// timeRange 需要补齐的K线时间范围[from, to)
+type timeRange struct {
+	from time.Time
+	to   time.Time
+}
+
+var recorderOnce sync.Once
+
+// StartRecorder 按market_data配置启动行情记录任务，未启用时不启动
+func StartRecorder(resolve VenueResolver) {
+	if !config.AppConfig.MarketData.Enabled {
+		return
+	}
+
+	recorderOnce.Do(func() {
+		r := newRecorder(resolve)
+		go r.runKlines()
+		go r.runSnapshots()
+
+		config.Logger.Infow("行情记录任务已启动",
+			"intervals", r.intervals,
+			"backfill", r.backfill.String(),
+			"snapshot_interval", r.snapshotInterval.String(),
+		)
+	})
+}
+
+// newRecorder 按配置创建行情记录任务，无效的配置项使用默认值
+func newRecorder(resolve VenueResolver) *Recorder {
+	cfg := config.AppConfig.MarketData
+	r := &Recorder{
+		resolve:          resolve,
+		backfill:         parseDuration("market_data.backfill", cfg.Backfill, defaultBackfill),
+		snapshotInterval: parseDuration("market_data.snapshot_interval", cfg.SnapshotInterval, defaultSnapshotInterval),
+	}
+
+	for _, interval := range cfg.Intervals {
+		if _, ok := types.IntervalDuration(interval); !ok {
+			config.Logger.Warnw("market_data.intervals中的K线周期无效，已忽略",
+				"interval", interval,
+			)
+			continue
+		}
+		r.intervals = append(r.intervals, interval)
+	}
+	if len(r.intervals) == 0 {
+		r.intervals = defaultIntervals
+	}
+	return r
+}
+
+// parseDuration 解析配置中的时长，为空或无效时返回默认值
+func parseDuration(name, value string, def time.Duration) time.Duration {
+	if value == "" {
+		return def
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		config.Logger.Warnw(name+"配置无效，使用默认值",
+			"value", value,
+			"default", def.String(),
+		)
+		return def
+	}
+	return parsed
+}
+
+// runKlines 定时保存新收盘的K线，每隔gapScanInterval检查一次回溯范围内的缺口
+func (r *Recorder) runKlines() {
+	for {
+		scanGaps := time.Since(r.lastGapScan) >= gapScanInterval
+		r.forEachSymbol(func(ex exchange.Exchange, code, symbol string) {
+			for _, interval := range r.intervals {
+				if err := r.syncKlines(ex, code, symbol, interval, scanGaps); err != nil {
+					config.Logger.Warnw("记录K线失败",
+						"error", err.Error(),
+						"exchange", code,
+						"symbol", symbol,
+						"interval", interval,
+					)
+				}
+			}
+		})
+		if scanGaps {
+			r.lastGapScan = time.Now()
+		}
+		time.Sleep(klineSyncInterval)
+	}
+}
+
+// runSnapshots 定时保存启用交易对的最新价和买一卖一价
+func (r *Recorder) runSnapshots() {
+	for {
+		var snapshots []models.TickerSnapshot
+		r.forEachSymbol(func(ex exchange.Exchange, code, symbol string) {
+			price, err := ex.GetSymbolPrice(symbol)
+			if err != nil {
+				config.Logger.Warnw("获取行情快照失败",
+					"error", err.Error(),
+					"exchange", code,
+					"symbol", symbol,
+				)
+				return
+			}
+
+			snapshot := models.TickerSnapshot{Exchange: code, Symbol: symbol, Time: time.Now(), Price: price}
+			if book, err := ex.GetOrderBook(symbol, 1); err == nil {
+				snapshot.Bid, snapshot.Ask = book.BestBid(), book.BestAsk()
+			}
+			snapshots = append(snapshots, snapshot)
+		})
+
+		if len(snapshots) > 0 {
+			if err := repository.DB.CreateInBatches(&snapshots, 200).Error; err != nil {
+				config.Logger.Errorw("保存行情快照失败",
+					"error", err.Error(),
+				)
+			}
+		}
+		time.Sleep(r.snapshotInterval)
+	}
+}
+
+// forEachSymbol 对每个启用的交易对执行fn，没有可用交易所的交易对跳过
+func (r *Recorder) forEachSymbol(fn func(ex exchange.Exchange, code, symbol string)) {
+	var contractCodes []models.ContractCode
+	if err := repository.DB.Select("symbol").Where("status = ?", true).Order("id").Find(&contractCodes).Error; err != nil {
+		config.Logger.Errorw("查询交易对失败",
+			"error", err.Error(),
+		)
+		return
+	}
+
+	for _, contractCode := range contractCodes {
+		ex, code, err := r.resolve(contractCode.Symbol)
+		if err != nil {
+			config.Logger.Debugw("交易对没有可用的交易所，跳过行情记录",
+				"error", err.Error(),
+				"symbol", contractCode.Symbol,
+			)
+			continue
+		}
+		fn(ex, code, contractCode.Symbol)
+	}
+}
+
+// syncKlines 保存最后一根已记录K线之后的新K线，尚无记录时从回溯起点开始
+func (r *Recorder) syncKlines(ex exchange.Exchange, code, symbol, interval string, scanGaps bool) error {
+	d, _ := types.IntervalDuration(interval)
+	start := time.Now().Add(-r.backfill)
+
+	var last models.MarketKline
+	err := repository.DB.Select("open_time").
+		Where("exchange = ? AND symbol = ? AND kline_interval = ?", code, symbol, interval).
+		Order("open_time DESC").First(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.fill(ex, code, symbol, interval, timeRange{from: start, to: time.Now()})
+	}
+	if err != nil {
+		return fmt.Errorf("查询已记录的K线失败: %w", err)
+	}
+
+	if scanGaps {
+		gaps, err := findGaps(code, symbol, interval, start, d)
+		if err != nil {
+			return err
+		}
+		for _, gap := range gaps {
+			if err := r.fill(ex, code, symbol, interval, gap); err != nil {
+				return err
+			}
+		}
+	}
+	return r.fill(ex, code, symbol, interval, timeRange{from: last.OpenTime.Add(d), to: time.Now()})
+}
+
+// fill 分页获取并保存时间范围内已收盘的K线，已存在的K线忽略
+func (r *Recorder) fill(ex exchange.Exchange, code, symbol, interval string, span timeRange) error {
+	d, _ := types.IntervalDuration(interval)
+	since := span.from
+
+	for page := 0; page < maxPagesPerFill && since.Before(span.to); page++ {
+		klines, err := ex.GetKlines(symbol, interval, since, klinePageSize)
+		if err != nil {
+			return err
+		}
+		if len(klines) == 0 {
+			return nil
+		}
+
+		rows := make([]models.MarketKline, 0, len(klines))
+		for _, k := range klines {
+			if !k.Closed || k.OpenTime.Before(span.from) || !k.OpenTime.Before(span.to) {
+				continue
+			}
+			rows = append(rows, models.MarketKline{
+				Exchange:    code,
+				Symbol:      symbol,
+				Interval:    interval,
+				OpenTime:    k.OpenTime,
+				Open:        k.Open,
+				High:        k.High,
+				Low:         k.Low,
+				Close:       k.Close,
+				Volume:      k.Volume,
+				QuoteVolume: k.QuoteVolume,
+			})
+		}
+		if len(rows) > 0 {
+			if err := repository.DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 200).Error; err != nil {
+				return fmt.Errorf("保存K线失败: %w", err)
+			}
+		}
+
+		next := klines[len(klines)-1].OpenTime.Add(d)
+		if !next.After(since) {
+			return nil
+		}
+		since = next
+	}
+	return nil
+}
+
+// findGaps 查找from之后已记录K线之间的缺口，包括from到第一根已记录K线之间的部分
+func findGaps(code, symbol, interval string, from time.Time, d time.Duration) ([]timeRange, error) {
+	var openTimes []time.Time
+	if err := repository.DB.Model(&models.MarketKline{}).
+		Where("exchange = ? AND symbol = ? AND kline_interval = ? AND open_time >= ?", code, symbol, interval, from).
+		Order("open_time").Pluck("open_time", &openTimes).Error; err != nil {
+		return nil, fmt.Errorf("查询已记录的K线失败: %w", err)
+	}
+
+	// from不一定与K线开盘时间对齐，第一根K线应在from之后一个周期内开盘
+	var gaps []timeRange
+	for i, t := range openTimes {
+		switch {
+		case i == 0 && t.Sub(from) >= d:
+			gaps = append(gaps, timeRange{from: from, to: t})
+		case i > 0 && t.Sub(openTimes[i-1]) > d:
+			gaps = append(gaps, timeRange{from: openTimes[i-1].Add(d), to: t})
+		}
+	}
+	return gaps, nil
+}
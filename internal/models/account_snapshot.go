@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// AccountSnapshot 账户总价值快照，每次计算账户总价值时保存，用于统计账户净值曲线和最大回撤
+type AccountSnapshot struct {
+    ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+    Exchange   string    `json:"exchange" gorm:"size:50;index:idx_account_snapshot"` // 计算账户总价值使用的交易所代码
+    Currency   string    `json:"currency"`                                           // 计价币种
+    TotalValue float64   `json:"total_value"`
+    CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;index:idx_account_snapshot"`
+}
+
+func (AccountSnapshot) TableName() string {
+    return "account_snapshots"
+}
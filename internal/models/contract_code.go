@@ -10,8 +10,12 @@ type ContractCode struct {
     MinAmount       float64   `json:"min_amount" gorm:"default:0.001"`           // 最小交易量
     AmountPrecision int       `json:"amount_precision" gorm:"default:3"`         // 数量精度
     PricePrecision  int       `json:"price_precision" gorm:"default:5"`          // 价格精度
+    MinNotional     float64   `json:"min_notional"`                              // 最小下单金额（计价币），0表示不限制
     MaxPositionRatio float64   `json:"max_position_ratio"`                        // 交易对占账户总价值的最大比例，可以设置为0
+    MarketType      string    `json:"market_type" gorm:"default:spot"`          // 市场类型 (spot/futures)，决定信号路由到现货还是永续合约
     Status          bool      `json:"status" gorm:"default:true"`
+    ExchangeStatus  string    `json:"exchange_status"`                           // 交易所上的交易状态，由规则同步写入
+    RulesSyncedAt   *time.Time `json:"rules_synced_at"`                          // 最近一次同步交易所规则的时间
     CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
     UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
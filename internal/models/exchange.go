@@ -2,17 +2,29 @@ package models
 
 import "time"
 
+// Exchange 交易所账户，交易引擎按启用的记录创建交易所实例
 type Exchange struct {
-    ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-    Name      string    `json:"name" binding:"required"`
-    Code      string    `json:"code" binding:"required"`
-    ApiKey    string    `json:"api_key"`
-    ApiSecret string    `json:"api_secret"`
-    Status    bool      `json:"status" gorm:"default:true"`
-    CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-    UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+    ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+    Name       string    `json:"name" binding:"required"`
+    Code       string    `json:"code" binding:"required" gorm:"uniqueIndex;size:50"` // 交易所代码，同时作为配置文件中exchanges的名称
+    Type       string    `json:"type"`                                               // 适配器类型 (gateio/gateio_futures/binance/okx/paper)，为空时与Code相同
+    Account    string    `json:"account" gorm:"index"`                               // 账户名称，同一账户的现货和永续合约记录使用相同名称，为空表示默认账户
+    ApiKey     string    `json:"api_key"`                                            // 为空时使用配置文件中的密钥
+    ApiSecret  string    `json:"api_secret"`
+    Passphrase string    `json:"passphrase"`                                         // OKX需要
+    Status     bool      `json:"status" gorm:"default:true"`
+    CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+    UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 func (Exchange) TableName() string {
     return "exchanges"
 }
+
+// Kind 返回交易所的适配器类型
+func (e Exchange) Kind() string {
+    if e.Type != "" {
+        return e.Type
+    }
+    return e.Code
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// MarketKline 行情记录任务保存的已收盘K线，供回测和分析使用
+type MarketKline struct {
+    ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+    Exchange    string    `json:"exchange" gorm:"size:50;uniqueIndex:idx_market_kline"` // 交易所代码
+    Symbol      string    `json:"symbol" gorm:"size:50;uniqueIndex:idx_market_kline"`
+    Interval    string    `json:"interval" gorm:"column:kline_interval;size:10;uniqueIndex:idx_market_kline"` // 统一的K线周期 (1m/5m/1h/1d等)，interval是SQL关键字，列名加前缀
+    OpenTime    time.Time `json:"open_time" gorm:"uniqueIndex:idx_market_kline"`
+    Open        float64   `json:"open"`
+    High        float64   `json:"high"`
+    Low         float64   `json:"low"`
+    Close       float64   `json:"close"`
+    Volume      float64   `json:"volume"`       // 成交量（基础币）
+    QuoteVolume float64   `json:"quote_volume"` // 成交额（计价币）
+    CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (MarketKline) TableName() string {
+    return "market_klines"
+}
+
+// TickerSnapshot 行情记录任务定时保存的最新价和买一卖一价
+type TickerSnapshot struct {
+    ID       uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+    Exchange string    `json:"exchange" gorm:"size:50;index:idx_ticker_snapshot"`
+    Symbol   string    `json:"symbol" gorm:"size:50;index:idx_ticker_snapshot"`
+    Time     time.Time `json:"time" gorm:"index:idx_ticker_snapshot"`
+    Price    float64   `json:"price"` // 最新成交价
+    Bid      float64   `json:"bid"`   // 买一价，获取盘口失败时为0
+    Ask      float64   `json:"ask"`   // 卖一价，获取盘口失败时为0
+}
+
+func (TickerSnapshot) TableName() string {
+    return "ticker_snapshots"
+}
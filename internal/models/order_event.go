@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// OrderEvent 订单状态变更记录，只追加不修改
+type OrderEvent struct {
+    ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+    OrderRecordID uint      `json:"order_record_id" gorm:"index"` // 订单记录ID，交易所订单号确认前后不变
+    OrderID       string    `json:"order_id"`                     // 变更时的交易所订单ID
+    FromStatus    string    `json:"from_status"`                  // 变更前的状态，新建订单记录时为空
+    ToStatus      string    `json:"to_status"`
+    Source        string    `json:"source"`                       // 变更来源 (placement/rest_poll/websocket/manual/reconciliation)
+    FilledAmount  float64   `json:"filled_amount"`                // 变更后的累计成交数量
+    FilledPrice   float64   `json:"filled_price"`                 // 变更后的成交均价
+    Payload       string    `json:"payload" gorm:"type:text"`     // 交易所返回的订单状态(JSON)，没有时为空
+    Note          string    `json:"note"`                         // 变更说明
+    CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (OrderEvent) TableName() string {
+    return "order_events"
+}
@@ -8,6 +8,7 @@ type OrderParams struct {
 	Price        float64 `json:"price"`         // 价格
 	Action       string  `json:"action"`        // 交易动作 (buy/sell)
 	OrderType    string  `json:"order_type"`    // 订单类型 (limit/market)
+	TimeInForce  string  `json:"time_in_force"` // 有效方式 (gtc/ioc/fok/poc)
 	PositionSide string  `json:"position_side"` // 持仓方向 (open/close)
 	Amount       float64 `json:"amount"`        // 下单数量
 }
@@ -16,22 +17,30 @@ type OrderParams struct {
 type OrderRecord struct {
 	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	SystemOrderID  string    `json:"system_order_id" gorm:"uniqueIndex"` // 系统订单号（12位，包含类型和日期信息）
+	ParentID       *uint     `json:"parent_id" gorm:"index"`            // 监控超时后为剩余数量重新下单时，指向最初的订单记录
 	OrderID        string    `json:"order_id" gorm:"uniqueIndex"`       // 交易所订单ID
 	StrategyID     uint      `json:"strategy_id"`                       // 关联的策略ID
+	TimeCircle     string    `json:"time_circle"`                       // 信号的时间周期，用于按周期统计盈亏
 	ExchangeID     uint      `json:"exchange_id"`                       // 交易所ID
+	Account        string    `json:"account" gorm:"index"`              // 下单使用的交易所账户，默认账户为空
 	Symbol         string    `json:"symbol"`                            // 交易对
 	ContractCode   string    `json:"contract_code"`                     // 合约代码
 	ContractType   string    `json:"contract_type"`                     // 合约类型 (spot/futures)
 	OrderType      string    `json:"order_type"`                        // 订单类型 (limit/market)
+	TimeInForce    string    `json:"time_in_force"`                     // 有效方式 (gtc/ioc/fok/poc)
 	Price          float64   `json:"price"`                             // 价格
 	Amount         float64   `json:"amount"`                            // 数量
 	Action         string    `json:"action"`                            // 交易动作 (buy/sell)
 	PositionSide   string    `json:"position_side"`                     // 持仓方向 (open/close)
-	Status         string    `json:"status"`                            // 订单状态 (created/pending/filled/canceled/failed)
+	Status         string    `json:"status"`                            // 订单状态 (created/unknown/open/partially_filled/filled/canceled/failed)，变更记录在order_events中
 	FilledPrice    float64   `json:"filled_price"`                      // 成交价格
 	FilledAmount   float64   `json:"filled_amount"`                     // 成交数量
 	Fee            float64   `json:"fee"`                               // 手续费
 	FeeCurrency    string    `json:"fee_currency"`                      // 手续费币种
+	FeeValue       float64   `json:"fee_value"`                         // 手续费折算为账户计价币种的价值，订单结束时计算
+	ValueCurrency  string    `json:"value_currency"`                    // FeeValue和QuoteRate的计价币种
+	QuoteRate      float64   `json:"quote_rate"`                        // 1单位交易对计价币种折合ValueCurrency的汇率，订单结束时记录，用于折算盈亏和成交额
+	RealizedPnl    float64   `json:"realized_pnl"`                      // 成交在策略持仓上产生的已实现盈亏（计价币），不含手续费
 	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"` // 订单更新时间，完成时也会更新
 }
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// PositionLedger 按成交记录维护的持仓账本，每个账户、交易对和策略一行
+// StrategyID为0的行是账户在该交易对上的汇总，由所有策略的成交按顺序累计得到
+type PositionLedger struct {
+    ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+    Account     string     `json:"account" gorm:"size:50;uniqueIndex:idx_position_ledger"` // 交易所账户，默认账户为空
+    Symbol      string     `json:"symbol" gorm:"size:50;uniqueIndex:idx_position_ledger"`
+    StrategyID  uint       `json:"strategy_id" gorm:"uniqueIndex:idx_position_ledger"` // 策略ID，0表示账户汇总
+    MarketType  string     `json:"market_type"`                                         // 市场类型 (spot/futures)
+    Quantity    float64    `json:"quantity"`                                            // 持仓数量（基础币），负数为空仓
+    EntryPrice  float64    `json:"entry_price"`                                         // 加权平均开仓价格，持仓为0时为0
+    RealizedPnl float64    `json:"realized_pnl"`                                        // 已实现盈亏（计价币），不含手续费
+    Fees        float64    `json:"fees"`                                                // 累计手续费（计价币），以其他币种抵扣的手续费不计入
+    Volume      float64    `json:"volume"`                                              // 累计成交额（计价币）
+    LastOrderID string     `json:"last_order_id"`                                       // 最近一笔成交所属的交易所订单ID
+    LastFillAt  *time.Time `json:"last_fill_at"`
+    CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+    UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (PositionLedger) TableName() string {
+    return "positions"
+}
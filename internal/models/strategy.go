@@ -3,12 +3,21 @@ package models
 import "time"
 
 type Strategy struct {
-    ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-    Name      string    `json:"name" binding:"required"`
-    Code      string    `json:"code" binding:"required"`
-    Status    bool      `json:"status" gorm:"default:true"`
-    CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-    UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+    ID              uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+    Name            string    `json:"name" binding:"required"`
+    Code            string    `json:"code" binding:"required"`
+    Status          bool      `json:"status" gorm:"default:true"`
+    OrderType       string    `json:"order_type" gorm:"default:limit"`        // 默认订单类型 (limit/market)，信号未指定时使用
+    TimeInForce     string    `json:"time_in_force"`                          // 默认有效方式 (gtc/ioc/fok/poc)，为空时按订单类型取默认值
+    Account         string    `json:"account"`                                // 下单使用的交易所账户，对应exchanges表的account，为空时使用默认账户
+    PricePolicy     string    `json:"price_policy" gorm:"default:signal"`     // 限价单定价方式 (signal/best/mid)
+    PriceTicks      int       `json:"price_ticks"`                            // best定价时相对对手价加价的最小价格变动单位数量，负数表示向己方盘口退让
+    MaxSlippage     float64   `json:"max_slippage"`                           // 盘口相对信号价格不利变动的最大比例，超过时放弃信号，0表示使用全局配置
+    ExecutionPolicy string    `json:"execution_policy" gorm:"default:cancel"` // 订单监控超时后剩余数量的执行方式 (cancel/chase/market)
+    ChaseSteps      int       `json:"chase_steps"`                            // chase方式最多重新挂单的次数，0表示使用默认次数
+    TrendMAPeriod   int       `json:"trend_ma_period"`                        // 趋势策略的均线过滤周期数，买入要求最近收盘价在均线之上、卖出在均线之下，0表示不过滤
+    CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+    UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 func (Strategy) TableName() string {
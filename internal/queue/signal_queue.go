@@ -1,6 +1,8 @@
 package queue
 
 import (
+	"errors"
+	"order_go/internal/exchange"
 	"order_go/internal/models"
 	"order_go/internal/repository"
 	"order_go/internal/strategy"
@@ -70,27 +72,24 @@ func processSignal(signal models.TradingSignal) {
     // 2. 调用交易引擎执行交易逻辑
     engine := trading.GetEngine()
     if err := engine.ProcessSignal(signal); err != nil {
-        // 检查错误是否与“持仓量小于最小交易量”相关
-        if strings.Contains(err.Error(), "当前持仓量") && strings.Contains(err.Error(), "小于最小交易量") {
-            // 将持仓量小于最小交易量的情况记录为警告级别
-            config.Logger.Warnw("交易处理终止，忽略卖出信号",
-                "reason", err.Error(),
+        // 信号有效但未下单，原因按错误类型记录
+        signal.ProcessStatus = "valid_no_order"
+        signal.ProcessReason = signalErrorReason(err)
+        
+        if isExpectedSignalError(err) {
+            // 按下单规则主动放弃的信号记录为警告级别
+            config.Logger.Warnw("交易处理终止，未执行下单",
+                "reason", signal.ProcessReason,
                 "symbol", signal.Symbol,
                 "action", signal.Action,
             )
-            // 更新信号处理状态为有效未下单
-            signal.ProcessStatus = "valid_no_order"
-            signal.ProcessReason = err.Error()
         } else {
             // 其他错误仍然记录为错误级别
             config.Logger.Errorw("交易处理终止，未执行下单",
-                "error", err.Error(),
+                "error", signal.ProcessReason,
                 "symbol", signal.Symbol,
                 "action", signal.Action,
             )
-            // 更新信号处理状态为有效未下单
-            signal.ProcessStatus = "valid_no_order"
-            signal.ProcessReason = err.Error()
         }
         return
     }
@@ -116,3 +115,47 @@ func storeSignal(signal models.TradingSignal) {
         return
     }
 }
+
+// expectedSignalErrors 按下单规则放弃信号的错误，不属于故障
+var expectedSignalErrors = []error{
+    trading.ErrNoPositionToClose,
+    trading.ErrBelowMinAmount,
+    trading.ErrExceedMaxPositionRatio,
+    trading.ErrInsufficientAddPositionRatio,
+    trading.ErrInsufficientBalance,
+    trading.ErrSlippageExceeded,
+}
+
+// exchangeErrorKinds 交易所返回的错误分类，用于生成信号的处理原因
+var exchangeErrorKinds = []error{
+    exchange.ErrInsufficientFunds,
+    exchange.ErrMinNotional,
+    exchange.ErrInvalidSymbol,
+    exchange.ErrInvalidOrder,
+    exchange.ErrOrderRejected,
+    exchange.ErrRateLimited,
+    exchange.ErrUnavailable,
+    exchange.ErrAuth,
+}
+
+// isExpectedSignalError 判断信号未下单是否为按规则放弃
+func isExpectedSignalError(err error) bool {
+    for _, target := range expectedSignalErrors {
+        if errors.Is(err, target) {
+            return true
+        }
+    }
+    return false
+}
+
+// signalErrorReason 生成信号的处理原因
+// 交易所错误的原始信息通常是英文标签，前面加上错误分类说明
+func signalErrorReason(err error) string {
+    reason := err.Error()
+    for _, kind := range exchangeErrorKinds {
+        if errors.Is(err, kind) && !strings.Contains(reason, kind.Error()) {
+            return kind.Error() + ": " + reason
+        }
+    }
+    return reason
+}
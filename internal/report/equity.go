@@ -0,0 +1,57 @@
+package report
+
+import (
+	"fmt"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"time"
+)
+
+// Equity 账户总价值快照形成的净值曲线及其收益和最大回撤
+type Equity struct {
+	Exchange         string                   `json:"exchange"`
+	Currency         string                   `json:"currency"`
+	StartValue       float64                  `json:"start_value"`
+	EndValue         float64                  `json:"end_value"`
+	Return           float64                  `json:"return"`             // 期末相对期初的收益率，包含入金和出金的影响
+	MaxDrawdown      float64                  `json:"max_drawdown"`       // 最大回撤金额
+	MaxDrawdownRatio float64                  `json:"max_drawdown_ratio"` // 最大回撤占回撤前高点的比例
+	Snapshots        []models.AccountSnapshot `json:"snapshots"`
+}
+
+// GetEquity 按账户总价值快照统计时间范围内的净值曲线，exchange为空时使用所有快照
+func GetEquity(exchange string, from, to time.Time) (*Equity, error) {
+	query := repository.DB.Model(&models.AccountSnapshot{})
+	if exchange != "" {
+		query = query.Where("exchange = ?", exchange)
+	}
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+
+	var snapshots []models.AccountSnapshot
+	if err := query.Order("created_at").Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("查询账户快照失败: %w", err)
+	}
+
+	equity := &Equity{Exchange: exchange, Snapshots: snapshots}
+	if len(snapshots) == 0 {
+		return equity, nil
+	}
+
+	values := make([]float64, len(snapshots))
+	for i, s := range snapshots {
+		values[i] = s.TotalValue
+	}
+	equity.Currency = snapshots[len(snapshots)-1].Currency
+	equity.StartValue = values[0]
+	equity.EndValue = values[len(values)-1]
+	if equity.StartValue > 0 {
+		equity.Return = equity.EndValue/equity.StartValue - 1
+	}
+	equity.MaxDrawdown, equity.MaxDrawdownRatio = maxDrawdown(values)
+	return equity, nil
+}
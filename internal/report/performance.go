@@ -0,0 +1,299 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"order_go/internal/account"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/trading"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 绩效统计的分组方式
+const (
+	GroupByStrategy   = "strategy"
+	GroupBySymbol     = "symbol"
+	GroupByTimeCircle = "time_circle"
+	GroupByDate       = "date"
+)
+
+// ErrInvalidGroupBy 不支持的分组方式
+var ErrInvalidGroupBy = errors.New("不支持的分组方式")
+
+// Filter 绩效统计的筛选条件，时间范围按订单最后更新（成交完成）的时间筛选，To不包含在内
+type Filter struct {
+	StrategyID uint
+	Symbol     string
+	TimeCircle string
+	From       time.Time
+	To         time.Time
+}
+
+// Performance 一组订单的交易绩效
+// 盈亏、成交额和手续费都按订单结束时的汇率折算为账户计价币种，不同计价币种的交易对可以相加；
+// 没有记录汇率且计价币种不是账户计价币种的订单无法折算，只计入订单数和手续费
+type Performance struct {
+	Group         string   `json:"group"`
+	Orders        int      `json:"orders"`         // 有成交的订单数
+	Turnover      float64  `json:"turnover"`       // 成交额
+	Fees          float64  `json:"fees"`           // 手续费
+	RealizedPnl   float64  `json:"realized_pnl"`   // 已实现盈亏，不含手续费
+	NetPnl        float64  `json:"net_pnl"`        // 扣除手续费后的已实现盈亏
+	UnrealizedPnl *float64 `json:"unrealized_pnl"` // 当前持仓按最新价计算的浮动盈亏，按周期或日期分组时无法归属，为空
+	ClosedTrades  int      `json:"closed_trades"`  // 产生已实现盈亏的平仓订单数
+	Wins          int      `json:"wins"`
+	Losses        int      `json:"losses"`
+	WinRate       float64  `json:"win_rate"`      // 盈利订单占平仓订单的比例
+	AvgWin        float64  `json:"avg_win"`       // 盈利订单的平均盈利
+	AvgLoss       float64  `json:"avg_loss"`      // 亏损订单的平均亏损，为正数
+	ProfitFactor  *float64 `json:"profit_factor"` // 总盈利与总亏损之比，没有亏损时为空
+	MaxDrawdown   float64  `json:"max_drawdown"`  // 按成交顺序累计净盈亏的最大回撤
+	Unconverted   int      `json:"unconverted"`   // 无法折算为账户计价币种、未计入盈亏和成交额的订单数
+
+	grossWin  float64
+	grossLoss float64
+	curve     []float64
+}
+
+// GetPerformance 按分组方式统计订单成交的交易绩效，返回各分组及全部订单的汇总
+func GetPerformance(groupBy string, filter Filter) ([]*Performance, *Performance, error) {
+	keyOf, err := groupKey(groupBy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := repository.DB.Model(&models.OrderRecord{}).Where("filled_amount > 0")
+	if filter.StrategyID != 0 {
+		query = query.Where("strategy_id = ?", filter.StrategyID)
+	}
+	if filter.Symbol != "" {
+		query = query.Where("symbol = ?", filter.Symbol)
+	}
+	if filter.TimeCircle != "" {
+		query = query.Where("time_circle = ?", filter.TimeCircle)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("updated_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("updated_at < ?", filter.To)
+	}
+
+	var orders []models.OrderRecord
+	if err := query.Order("updated_at, id").Find(&orders).Error; err != nil {
+		return nil, nil, fmt.Errorf("查询订单失败: %w", err)
+	}
+
+	currency := account.ReportingCurrency()
+	total := &Performance{Group: "total"}
+	groups := make(map[string]*Performance)
+	for i := range orders {
+		order := &orders[i]
+		key := keyOf(order)
+		g, ok := groups[key]
+		if !ok {
+			g = &Performance{Group: key}
+			groups[key] = g
+		}
+		rate, ok := quoteRate(order, currency)
+		g.add(order, rate, ok)
+		total.add(order, rate, ok)
+	}
+
+	items := make([]*Performance, 0, len(groups))
+	for _, g := range groups {
+		g.finish()
+		items = append(items, g)
+	}
+	total.finish()
+
+	items, err = fillUnrealized(groupBy, filter, items, total)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, total, nil
+}
+
+// groupKey 返回分组方式对应的分组键
+func groupKey(groupBy string) (func(*models.OrderRecord) string, error) {
+	switch groupBy {
+	case GroupByStrategy:
+		return func(o *models.OrderRecord) string { return strconv.FormatUint(uint64(o.StrategyID), 10) }, nil
+	case GroupBySymbol:
+		return func(o *models.OrderRecord) string { return o.Symbol }, nil
+	case GroupByTimeCircle:
+		return func(o *models.OrderRecord) string { return o.TimeCircle }, nil
+	case GroupByDate:
+		return func(o *models.OrderRecord) string { return o.UpdatedAt.Format("2006-01-02") }, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidGroupBy, groupBy)
+	}
+}
+
+// quoteRate 获取订单计价币种折合账户计价币种的汇率
+// 没有记录汇率的历史订单，计价币种就是账户计价币种时按1折算，否则无法折算
+func quoteRate(order *models.OrderRecord, currency string) (float64, bool) {
+	if order.QuoteRate > 0 {
+		return order.QuoteRate, true
+	}
+	if _, quote, ok := strings.Cut(order.Symbol, "_"); ok && strings.EqualFold(quote, currency) {
+		return 1, true
+	}
+	return 0, false
+}
+
+// add 计入一个订单的成交，rate为订单计价币种折合账户计价币种的汇率，converted为false时无法折算
+func (p *Performance) add(order *models.OrderRecord, rate float64, converted bool) {
+	p.Orders++
+	p.Fees += order.FeeValue
+
+	pnl := 0.0
+	if converted {
+		p.Turnover += order.FilledAmount * order.FilledPrice * rate
+		pnl = order.RealizedPnl * rate
+		p.RealizedPnl += pnl
+	} else {
+		p.Unconverted++
+	}
+	p.NetPnl = p.RealizedPnl - p.Fees
+	p.curve = append(p.curve, p.NetPnl)
+
+	switch {
+	case pnl > 0:
+		p.Wins++
+		p.grossWin += pnl
+	case pnl < 0:
+		p.Losses++
+		p.grossLoss -= pnl
+	}
+}
+
+// finish 计算比率类指标
+func (p *Performance) finish() {
+	p.ClosedTrades = p.Wins + p.Losses
+	if p.ClosedTrades > 0 {
+		p.WinRate = float64(p.Wins) / float64(p.ClosedTrades)
+	}
+	if p.Wins > 0 {
+		p.AvgWin = p.grossWin / float64(p.Wins)
+	}
+	if p.Losses > 0 {
+		p.AvgLoss = p.grossLoss / float64(p.Losses)
+		factor := p.grossWin / p.grossLoss
+		p.ProfitFactor = &factor
+	}
+	// 累计净盈亏曲线从0开始
+	p.MaxDrawdown, _ = maxDrawdown(append([]float64{0}, p.curve...))
+}
+
+// maxDrawdown 计算序列从高点到之后低点的最大回撤，返回回撤金额和相对高点的比例，高点不为正时比例为0
+func maxDrawdown(values []float64) (float64, float64) {
+	var amount, ratio float64
+	peak := math.Inf(-1)
+	for _, v := range values {
+		if v > peak {
+			peak = v
+		}
+		if dd := peak - v; dd > amount {
+			amount = dd
+			if peak > 0 {
+				ratio = dd / peak
+			}
+		}
+	}
+	return amount, ratio
+}
+
+// fillUnrealized 按持仓账本的当前持仓计算浮动盈亏，按当前汇率折算为账户计价币种，返回按分组排序的结果
+// 按策略分组时使用各策略的持仓，按交易对分组时使用账户汇总持仓，汇总为各分组之和；浮动盈亏反映当前持仓，不受时间范围限制
+func fillUnrealized(groupBy string, filter Filter, items []*Performance, total *Performance) ([]*Performance, error) {
+	if groupBy != GroupByStrategy && groupBy != GroupBySymbol {
+		return sortGroups(items), nil
+	}
+
+	query := repository.DB.Model(&models.PositionLedger{}).Where("quantity <> 0")
+	if filter.Symbol != "" {
+		query = query.Where("symbol = ?", filter.Symbol)
+	}
+	if filter.StrategyID != 0 {
+		query = query.Where("strategy_id = ?", filter.StrategyID)
+	} else if groupBy == GroupBySymbol {
+		query = query.Where("strategy_id = 0")
+	} else {
+		query = query.Where("strategy_id <> 0")
+	}
+
+	var rows []models.PositionLedger
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询持仓账本失败: %w", err)
+	}
+
+	byGroup := make(map[string]*Performance, len(items))
+	for _, item := range items {
+		byGroup[item.Group] = item
+	}
+
+	type mark struct{ price, rate float64 }
+	marks := make(map[string]mark)
+	var sum float64
+	for _, row := range rows {
+		key := row.Account + "|" + row.Symbol
+		m, ok := marks[key]
+		if !ok {
+			m.price, m.rate = markPrice(row.Account, row.Symbol)
+			marks[key] = m
+		}
+		if m.price <= 0 || m.rate <= 0 {
+			continue
+		}
+		pnl := (m.price - row.EntryPrice) * row.Quantity * m.rate
+		sum += pnl
+
+		group := row.Symbol
+		if groupBy == GroupByStrategy {
+			group = strconv.FormatUint(uint64(row.StrategyID), 10)
+		}
+		item, ok := byGroup[group]
+		if !ok {
+			// 时间范围内没有成交但仍有持仓
+			item = &Performance{Group: group}
+			byGroup[group] = item
+			items = append(items, item)
+		}
+		if item.UnrealizedPnl == nil {
+			item.UnrealizedPnl = new(float64)
+		}
+		*item.UnrealizedPnl += pnl
+	}
+	total.UnrealizedPnl = &sum
+	return sortGroups(items), nil
+}
+
+// sortGroups 按分组键排序
+func sortGroups(items []*Performance) []*Performance {
+	sort.Slice(items, func(i, j int) bool { return items[i].Group < items[j].Group })
+	return items
+}
+
+// markPrice 获取交易对在账户使用的交易所上的最新价，以及计价币种折合账户计价币种的汇率，获取失败时为0
+func markPrice(accountName, symbol string) (float64, float64) {
+	ex, err := trading.GetEngine().ExchangeForAccount(accountName, symbol)
+	if err != nil {
+		return 0, 0
+	}
+	price, err := ex.GetSymbolPrice(symbol)
+	if err != nil {
+		return 0, 0
+	}
+	_, quote, _ := strings.Cut(symbol, "_")
+	rate, err := account.ConversionRate(ex, quote, account.ReportingCurrency())
+	if err != nil {
+		return price, 0
+	}
+	return price, rate
+}
@@ -0,0 +1,38 @@
+package report
+
+import (
+	"order_go/internal/models"
+	"testing"
+)
+
+// TestPerformanceConvertsQuoteCurrency 不同计价币种的订单按订单结束时的汇率折算后相加
+func TestPerformanceConvertsQuoteCurrency(t *testing.T) {
+	orders := []models.OrderRecord{
+		// 计价币就是账户计价币种的历史订单，没有记录汇率
+		{Symbol: "BTC_USDT", FilledAmount: 0.1, FilledPrice: 60000, RealizedPnl: 100, FeeValue: 6},
+		// BTC计价的订单，1 BTC = 60000 USDT
+		{Symbol: "ETH_BTC", FilledAmount: 2, FilledPrice: 0.05, RealizedPnl: -0.001, FeeValue: 3, QuoteRate: 60000},
+		// 没有记录汇率也无法按1折算
+		{Symbol: "ETH_BTC", FilledAmount: 1, FilledPrice: 0.05, RealizedPnl: 0.002, FeeValue: 1},
+	}
+
+	p := &Performance{Group: "total"}
+	for i := range orders {
+		rate, ok := quoteRate(&orders[i], "USDT")
+		p.add(&orders[i], rate, ok)
+	}
+	p.finish()
+
+	if p.Orders != 3 || p.Unconverted != 1 {
+		t.Fatalf("订单数或未折算订单数错误, got %d %d", p.Orders, p.Unconverted)
+	}
+	if p.Turnover != 6000+6000 {
+		t.Fatalf("成交额应折算为USDT, got %v", p.Turnover)
+	}
+	if p.RealizedPnl != 100-60 || p.Fees != 10 || p.NetPnl != 30 {
+		t.Fatalf("盈亏应折算为USDT, got realized=%v fees=%v net=%v", p.RealizedPnl, p.Fees, p.NetPnl)
+	}
+	if p.Wins != 1 || p.Losses != 1 || p.AvgLoss != 60 {
+		t.Fatalf("胜负统计应按折算后的盈亏, got wins=%d losses=%d avg_loss=%v", p.Wins, p.Losses, p.AvgLoss)
+	}
+}
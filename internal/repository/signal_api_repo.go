@@ -107,4 +107,25 @@ func GetOrderByID(ctx context.Context, id uint) (models.OrderRecord, error) {
 	}
 	
 	return order, nil
-}
\ No newline at end of file
+}
+// GetOrderEvents 获取订单的状态变更记录，按发生顺序排列
+func GetOrderEvents(ctx context.Context, orderRecordID uint) ([]models.OrderEvent, error) {
+	var events []models.OrderEvent
+	
+	if err := DB.WithContext(ctx).Where("order_record_id = ?", orderRecordID).Order("id").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	
+	return events, nil
+}
+
+// GetChildOrders 获取监控超时后为剩余数量重新下的订单，按下单顺序排列
+func GetChildOrders(ctx context.Context, parentID uint) ([]models.OrderRecord, error) {
+	var orders []models.OrderRecord
+	
+	if err := DB.WithContext(ctx).Where("parent_id = ?", parentID).Order("id").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	
+	return orders, nil
+}
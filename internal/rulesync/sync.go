@@ -0,0 +1,263 @@
+// Package rulesync 从交易所同步交易对的最小下单量、精度和交易状态，并报告与本地交易对配置的差异
+package rulesync
+
+import (
+	"fmt"
+	"math"
+	"order_go/internal/constants"
+	"order_go/internal/exchange"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/utils/config"
+	"sync"
+	"time"
+)
+
+// 交易对同步结果状态
+const (
+	StateInSync    = "in_sync"   // 本地配置与交易所规则一致
+	StateChanged   = "changed"   // 本地配置与交易所规则不一致
+	StateSuspended = "suspended" // 交易所暂停交易、只能单向交易或即将下架
+	StateDelisted  = "delisted"  // 交易所已不存在该交易对
+	StateError     = "error"     // 获取交易所规则失败
+)
+
+const defaultInterval = 24 * time.Hour
+
+// FieldDiff 单个字段的差异
+type FieldDiff struct {
+	Field    string      `json:"field"`
+	Local    interface{} `json:"local"`
+	Exchange interface{} `json:"exchange"`
+}
+
+// Result 单个交易对的同步结果
+type Result struct {
+	ContractCodeID uint                 `json:"contract_code_id"`
+	Symbol         string               `json:"symbol"`
+	MarketType     string               `json:"market_type"`
+	State          string               `json:"state"`
+	Diffs          []FieldDiff          `json:"diffs,omitempty"`
+	Rule           *exchange.SymbolRule `json:"rule,omitempty"` // 交易所规则，即建议写入的设置
+	Error          string               `json:"error,omitempty"`
+}
+
+// Report 一次同步的结果
+type Report struct {
+	SyncedAt time.Time `json:"synced_at"`
+	Applied  bool      `json:"applied"` // 是否已将交易所规则写入交易对配置
+	Results  []Result  `json:"results"`
+}
+
+var (
+	lastReport   *Report
+	lastReportMu sync.RWMutex
+)
+
+// LastReport 获取最近一次同步的结果，尚未同步时返回nil
+func LastReport() *Report {
+	lastReportMu.RLock()
+	defer lastReportMu.RUnlock()
+	return lastReport
+}
+
+// Start 启动定时同步任务，间隔和是否自动写入由rule_sync配置决定
+func Start() {
+	interval := defaultInterval
+	if config.AppConfig.RuleSync.Interval != "" {
+		parsed, err := time.ParseDuration(config.AppConfig.RuleSync.Interval)
+		if err != nil || parsed <= 0 {
+			config.Logger.Warnw("rule_sync.interval配置无效，使用默认值",
+				"interval", config.AppConfig.RuleSync.Interval,
+				"default", defaultInterval.String(),
+			)
+		} else {
+			interval = parsed
+		}
+	}
+
+	go func() {
+		for {
+			if _, err := Sync(config.AppConfig.RuleSync.AutoApply); err != nil {
+				config.Logger.Errorw("同步交易对规则失败",
+					"error", err.Error(),
+				)
+			}
+			time.Sleep(interval)
+		}
+	}()
+
+	config.Logger.Infow("交易对规则同步任务已启动",
+		"interval", interval.String(),
+		"auto_apply", config.AppConfig.RuleSync.AutoApply,
+	)
+}
+
+// Sync 获取交易所规则并与所有交易对配置比较，apply为true时写入交易所规则
+// 暂停交易或已下架的交易对只做标记，是否停用由运维决定
+func Sync(apply bool) (*Report, error) {
+	var contractCodes []models.ContractCode
+	if err := repository.DB.Order("id").Find(&contractCodes).Error; err != nil {
+		return nil, fmt.Errorf("查询交易对失败: %w", err)
+	}
+
+	report := &Report{SyncedAt: time.Now(), Applied: apply}
+	rulesByMarket := make(map[string]map[string]exchange.SymbolRule)
+	fetchErrs := make(map[string]error)
+
+	for _, contractCode := range contractCodes {
+		marketType := contractCode.MarketType
+		if marketType == "" {
+			marketType = constants.ExchangeTypeSpot
+		}
+
+		// 每个市场只请求一次交易所规则
+		if _, ok := rulesByMarket[marketType]; !ok && fetchErrs[marketType] == nil {
+			rules, err := fetchRules(marketType)
+			if err != nil {
+				fetchErrs[marketType] = err
+			} else {
+				rulesByMarket[marketType] = rules
+			}
+		}
+
+		result := Result{
+			ContractCodeID: contractCode.ID,
+			Symbol:         contractCode.Symbol,
+			MarketType:     marketType,
+		}
+
+		if err := fetchErrs[marketType]; err != nil {
+			result.State = StateError
+			result.Error = err.Error()
+			report.Results = append(report.Results, result)
+			continue
+		}
+
+		rule, ok := rulesByMarket[marketType][contractCode.Symbol]
+		if !ok {
+			result.State = StateDelisted
+			config.Logger.Warnw("交易所已不存在该交易对",
+				"symbol", contractCode.Symbol,
+				"market_type", marketType,
+			)
+		} else {
+			result.Rule = &rule
+			result.Diffs = compare(&contractCode, &rule)
+			switch {
+			case !rule.Tradable():
+				result.State = StateSuspended
+				config.Logger.Warnw("交易对在交易所不可正常交易",
+					"symbol", contractCode.Symbol,
+					"market_type", marketType,
+					"exchange_status", rule.Status,
+				)
+			case len(result.Diffs) > 0:
+				result.State = StateChanged
+			default:
+				result.State = StateInSync
+			}
+		}
+
+		if apply {
+			if err := applyRule(&contractCode, result.Rule, report.SyncedAt); err != nil {
+				result.Error = err.Error()
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	lastReportMu.Lock()
+	lastReport = report
+	lastReportMu.Unlock()
+
+	changed := 0
+	for _, result := range report.Results {
+		if result.State != StateInSync {
+			changed++
+		}
+	}
+	config.Logger.Infow("交易对规则同步完成",
+		"contract_count", len(report.Results),
+		"not_in_sync", changed,
+		"applied", apply,
+	)
+
+	return report, nil
+}
+
+// fetchRules 获取市场对应交易所的全部交易对规则，按交易对索引
+func fetchRules(marketType string) (map[string]exchange.SymbolRule, error) {
+	var ex exchange.Exchange
+	if marketType == constants.ExchangeTypeFutures {
+		ex = exchange.NewRateLimited("gateio_futures", exchange.NewGateIOFutures())
+	} else {
+		ex = exchange.NewRateLimited("gateio", exchange.NewGateIO())
+	}
+
+	provider, ok := ex.(exchange.SymbolRuleProvider)
+	if !ok {
+		return nil, fmt.Errorf("交易所不支持查询交易对规则")
+	}
+
+	rules, err := provider.GetSymbolRules()
+	if err != nil {
+		return nil, err
+	}
+
+	bySymbol := make(map[string]exchange.SymbolRule, len(rules))
+	for _, rule := range rules {
+		bySymbol[rule.Symbol] = rule
+	}
+	return bySymbol, nil
+}
+
+// compare 比较本地交易对配置与交易所规则
+// 交易所未公布最小下单数量时不比较该字段
+func compare(contractCode *models.ContractCode, rule *exchange.SymbolRule) []FieldDiff {
+	var diffs []FieldDiff
+	if rule.MinBaseAmount > 0 && !floatEqual(contractCode.MinAmount, rule.MinBaseAmount) {
+		diffs = append(diffs, FieldDiff{Field: "min_amount", Local: contractCode.MinAmount, Exchange: rule.MinBaseAmount})
+	}
+	if !floatEqual(contractCode.MinNotional, rule.MinQuoteAmount) {
+		diffs = append(diffs, FieldDiff{Field: "min_notional", Local: contractCode.MinNotional, Exchange: rule.MinQuoteAmount})
+	}
+	if contractCode.AmountPrecision != rule.AmountPrecision {
+		diffs = append(diffs, FieldDiff{Field: "amount_precision", Local: contractCode.AmountPrecision, Exchange: rule.AmountPrecision})
+	}
+	if contractCode.PricePrecision != rule.PricePrecision {
+		diffs = append(diffs, FieldDiff{Field: "price_precision", Local: contractCode.PricePrecision, Exchange: rule.PricePrecision})
+	}
+	return diffs
+}
+
+// applyRule 将交易所规则写入交易对配置，rule为nil表示交易对已下架，只更新交易状态
+func applyRule(contractCode *models.ContractCode, rule *exchange.SymbolRule, syncedAt time.Time) error {
+	updates := map[string]interface{}{
+		"exchange_status": StateDelisted,
+		"rules_synced_at": syncedAt,
+	}
+	if rule != nil {
+		updates["exchange_status"] = rule.Status
+		updates["min_notional"] = rule.MinQuoteAmount
+		updates["amount_precision"] = rule.AmountPrecision
+		updates["price_precision"] = rule.PricePrecision
+		if rule.MinBaseAmount > 0 {
+			updates["min_amount"] = rule.MinBaseAmount
+		}
+	}
+
+	if err := repository.DB.Model(&models.ContractCode{}).Where("id = ?", contractCode.ID).Updates(updates).Error; err != nil {
+		config.Logger.Errorw("写入交易对规则失败",
+			"symbol", contractCode.Symbol,
+			"error", err.Error(),
+		)
+		return fmt.Errorf("写入交易对规则失败: %w", err)
+	}
+	return nil
+}
+
+// floatEqual 按相对误差比较浮点数，避免交易所返回的字符串解析误差被报告为差异
+func floatEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
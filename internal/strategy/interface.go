@@ -1,7 +1,10 @@
 package strategy
 
 import (
+	"order_go/internal/exchange"
+	"order_go/internal/marketdata"
 	"order_go/internal/models"
+	"order_go/internal/trading"
 )
 
 // Strategy 策略接口定义
@@ -22,6 +25,9 @@ type Strategy interface {
 	ValidateSignal(signal models.TradingSignal) (bool, string)
 }
 
+// MaxTrendMAPeriod 均线过滤最多使用的K线数量，不超过K线缓存的容量
+const MaxTrendMAPeriod = 200
+
 // BaseStrategy 基础策略实现，包含共用字段和方法
 type BaseStrategy struct {
 	dbStrategy models.Strategy
@@ -45,4 +51,13 @@ func (s *BaseStrategy) GetName() string {
 // IsActive 返回策略是否激活
 func (s *BaseStrategy) IsActive() bool {
 	return s.dbStrategy.Status
-}
\ No newline at end of file
+}
+
+// recentKlines 获取信号交易对在信号周期上最近limit根已收盘的K线，用于计算技术指标
+func (s *BaseStrategy) recentKlines(signal models.TradingSignal, limit int) ([]exchange.Kline, error) {
+	ex, err := trading.GetEngine().ExchangeForSignal(signal)
+	if err != nil {
+		return nil, err
+	}
+	return marketdata.GetClosedKlines(ex, signal.Symbol, signal.TimeCircle, limit)
+}
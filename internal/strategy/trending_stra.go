@@ -1,6 +1,8 @@
 package strategy
 
 import (
+	"fmt"
+	"order_go/internal/exchange"
 	"order_go/internal/models"
 	"order_go/internal/repository"
 	"order_go/internal/utils/config"
@@ -46,10 +48,56 @@ func (s *TrendingStrategy) ValidateSignal(signal models.TradingSignal) (bool, st
 		return false, reason
 	}
 	
-	// 在实际应用中，这里可以添加更复杂的趋势分析逻辑
-	// 例如：移动平均线、相对强弱指标(RSI)、MACD等技术指标分析
+	// 5. 按均线过滤逆势信号
+	if period := s.dbStrategy.TrendMAPeriod; period > 0 {
+		valid, reason = s.checkTrend(signal, period)
+		if !valid {
+			return false, reason
+		}
+	}
 	
-	// 示例中简单返回有效
+	return true, ""
+}
+
+// checkTrend 按信号周期上最近period根已收盘K线的收盘价均线过滤逆势信号
+// 买入要求最近收盘价高于均线，卖出要求低于均线；K线获取失败或数量不足时不过滤，避免行情故障阻止平仓
+func (s *TrendingStrategy) checkTrend(signal models.TradingSignal, period int) (bool, string) {
+	klines, err := s.recentKlines(signal, period)
+	if err != nil {
+		config.Logger.Warnw("获取K线失败，跳过均线过滤",
+			"error", err.Error(),
+			"symbol", signal.Symbol,
+			"time_circle", signal.TimeCircle,
+		)
+		return true, ""
+	}
+	if len(klines) < period {
+		config.Logger.Warnw("K线数量不足，跳过均线过滤",
+			"symbol", signal.Symbol,
+			"time_circle", signal.TimeCircle,
+			"klines", len(klines),
+			"period", period,
+		)
+		return true, ""
+	}
+	return trendAllows(signal.Action, klines)
+}
+
+// trendAllows 判断信号方向是否与K线收盘价均线给出的趋势一致
+func trendAllows(action string, klines []exchange.Kline) (bool, string) {
+	var sum float64
+	for _, k := range klines {
+		sum += k.Close
+	}
+	ma := sum / float64(len(klines))
+	last := klines[len(klines)-1].Close
+
+	switch {
+	case action == "buy" && last <= ma:
+		return false, fmt.Sprintf("收盘价%g不高于%d周期均线%g，不顺势买入", last, len(klines), ma)
+	case action == "sell" && last >= ma:
+		return false, fmt.Sprintf("收盘价%g不低于%d周期均线%g，不顺势卖出", last, len(klines), ma)
+	}
 	return true, ""
 }
 
@@ -0,0 +1,45 @@
+package strategy
+
+import (
+	"order_go/internal/exchange"
+	"testing"
+)
+
+// closes 按收盘价生成K线
+func closes(prices ...float64) []exchange.Kline {
+	klines := make([]exchange.Kline, len(prices))
+	for i, p := range prices {
+		klines[i] = exchange.Kline{Close: p, Closed: true}
+	}
+	return klines
+}
+
+// TestTrendAllows 买入要求收盘价高于均线，卖出要求低于均线
+func TestTrendAllows(t *testing.T) {
+	up := closes(100, 101, 102, 103, 104)   // 均线102，收盘104
+	down := closes(104, 103, 102, 101, 100) // 均线102，收盘100
+	flat := closes(100, 100, 100)
+
+	cases := []struct {
+		name   string
+		action string
+		klines []exchange.Kline
+		want   bool
+	}{
+		{"上涨时买入", "buy", up, true},
+		{"上涨时卖出", "sell", up, false},
+		{"下跌时卖出", "sell", down, true},
+		{"下跌时买入", "buy", down, false},
+		{"收盘价等于均线时买入", "buy", flat, false},
+		{"收盘价等于均线时卖出", "sell", flat, false},
+	}
+	for _, c := range cases {
+		got, reason := trendAllows(c.action, c.klines)
+		if got != c.want {
+			t.Errorf("%s: trendAllows = %v (%s), want %v", c.name, got, reason, c.want)
+		}
+		if !got && reason == "" {
+			t.Errorf("%s: 过滤信号时应返回原因", c.name)
+		}
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"order_go/internal/utils/config"
 	"order_go/internal/utils/orderid"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 )
@@ -20,13 +19,13 @@ var (
 	engineOnce sync.Once
 	
 	ErrInvalidContractType = errors.New("无效的合约类型")
-	ErrInsufficientBalance = errors.New("账户余额不足")
+	ErrInsufficientBalance = exchange.ErrInsufficientFunds // 与交易所返回的余额不足错误归为同一类
 	ErrOrderFailed         = errors.New("下单失败")
 )
 
 // Engine 交易引擎，负责处理交易信号并执行下单操作
 type Engine struct {
-	exchanges map[string]exchange.Exchange
+	exchanges map[string]*venue // 交易所代码 -> 已注册的交易所
 	monitor   *OrderMonitor
 	mutex     sync.RWMutex
 }
@@ -35,43 +34,53 @@ type Engine struct {
 func GetEngine() *Engine {
 	engineOnce.Do(func() {
 		engine = &Engine{
-			exchanges: make(map[string]exchange.Exchange),
+			exchanges: make(map[string]*venue),
 			monitor:   GetOrderMonitor(),
 		}
-		engine.registerExchanges()
+		engine.loadExchanges()
 	})
 	return engine
 }
 
-// registerExchanges 注册交易所
-func (e *Engine) registerExchanges() {
-	// 注册Gate.io交易所
-	gateio := exchange.NewGateIO()
-	e.exchanges[constants.ExchangeTypeSpot] = gateio
-	e.exchanges[constants.ExchangeTypeFutures] = gateio
-	
-	// 注册交易所到监控器
-	e.monitor.RegisterExchange(constants.ExchangeTypeSpot, gateio)
-	e.monitor.RegisterExchange(constants.ExchangeTypeFutures, gateio)
-	
-	// 可以注册更多交易所
-	// e.exchanges["binance"] = exchange.NewBinance()
-}
-
 // ProcessSignal 处理交易信号，执行下单操作
 func (e *Engine) ProcessSignal(signal models.TradingSignal) error {
-	// 1. 根据合约类型选择交易所
-	ex, exchangeType, err := e.getExchangeByContractType(signal.ContractType)
+	// 1. 确定下单的交易所
+	v, err := e.resolveVenue(signal)
 	if err != nil {
 		config.Logger.Errorw("获取交易所失败",
 			"error", err.Error(),
 			"contract_type", signal.ContractType,
+			"symbol", signal.Symbol,
+		)
+		return err
+	}
+	ex, exchangeName, exchangeType := v.ex, v.code, v.market
+	
+	// 信号指定的订单类型和有效方式优先于策略配置
+	orderType, timeInForce, err := resolveOrderMode(signal)
+	if err != nil {
+		config.Logger.Errorw("订单类型或有效方式无效",
+			"error", err.Error(),
+			"symbol", signal.Symbol,
+			"strategy_id", signal.StrategyID,
 		)
 		return err
 	}
 	
+	// 按策略的定价方式确定价格，下单数量也按该价格计算
+	price, err := resolveOrderPrice(signal, ex, orderType)
+	if err != nil {
+		config.Logger.Warnw("确定下单价格失败",
+			"error", err.Error(),
+			"symbol", signal.Symbol,
+			"signal_price", signal.Price,
+		)
+		return err
+	}
+	signal.Price = price
+	
 	// 2. 确定下单参数
-	orderParams, err := e.determineOrderParams(signal, ex)
+	orderParams, err := e.determineOrderParams(signal, ex, exchangeType)
 	if err != nil {
 		config.Logger.Errorw("确定下单参数失败",
 			"error", err.Error(),
@@ -82,7 +91,7 @@ func (e *Engine) ProcessSignal(signal models.TradingSignal) error {
 	
 	// 检查计算出的订单数量是否为0，如果为0则表示不满足最小交易量要求
 	if orderParams.Amount <= 0 {
-		err := fmt.Errorf("交易数量不足，无法下单")
+		err := fmt.Errorf("%w: 交易数量不足，无法下单", ErrBelowMinAmount)
 		config.Logger.Warnw(err.Error(),
 			"symbol", signal.Symbol,
 			"amount", orderParams.Amount,
@@ -90,6 +99,9 @@ func (e *Engine) ProcessSignal(signal models.TradingSignal) error {
 		return err
 	}
 	
+	orderParams.OrderType = orderType
+	orderParams.TimeInForce = timeInForce
+	
 	// 3. 创建订单记录
 	// 生成系统订单号
 	systemOrderID, err := orderid.GenerateOrderID(orderid.TypeCrypto)
@@ -116,16 +128,19 @@ func (e *Engine) ProcessSignal(signal models.TradingSignal) error {
 	orderRecord := models.OrderRecord{
 		SystemOrderID: systemOrderID,
 		StrategyID:   uint(strategyID),
-		ExchangeID:   1, // 假设Gate.io的ID为1，实际应从配置或数据库获取
+		TimeCircle:   signal.TimeCircle,
+		ExchangeID:   v.id,
+		Account:      v.account,
 		Symbol:       signal.Symbol,
 		ContractType: exchangeType,
 		ContractCode: fmt.Sprintf("%d", signal.ContractType), // 存储原始合约类型编码
 		OrderType:    orderParams.OrderType,
+		TimeInForce:  orderParams.TimeInForce,
 		Price:        orderParams.Price,
 		Amount:       orderParams.Amount,
 		Action:       orderParams.Action,
 		PositionSide: orderParams.PositionSide,
-		Status:       "created",
+		Status:       OrderStatusCreated,
 	}
 	
 	// 4. 执行下单
@@ -135,43 +150,92 @@ func (e *Engine) ProcessSignal(signal models.TradingSignal) error {
 		Amount:       orderParams.Amount,
 		Side:         orderParams.Action,
 		Type:         orderParams.OrderType,
+		TimeInForce:  orderParams.TimeInForce,
 		PositionSide: orderParams.PositionSide,
+		ClientID:     systemOrderID, // 系统订单号作为客户端订单ID，下单结果不确定时据此查询
 	}
 	
+	return e.submitOrder(orderRecord, orderReq, ex, exchangeName)
+}
+
+// submitOrder 向交易所提交订单并保存订单记录，下单成功后开始监控
+// 下单结果不确定时保存待确认记录并转入后台确认，下单失败时保存失败记录
+func (e *Engine) submitOrder(orderRecord models.OrderRecord, orderReq *exchange.OrderRequest, ex exchange.Exchange, exchangeName string) error {
+	systemOrderID := orderRecord.SystemOrderID
 	orderResp, err := ex.CreateOrder(orderReq)
 	
+	// 超时、连接中断等情况下订单可能已经创建，先按客户端订单ID确认，避免留下无人监控的订单
+	if err != nil && exchange.IsOrderOutcomeUnknown(err) {
+		found, findErr := confirmOrder(ex, orderReq)
+		switch {
+		case findErr == nil:
+			config.Logger.Warnw("下单请求失败但订单已在交易所创建",
+				"error", err.Error(),
+				"system_order_id", systemOrderID,
+				"order_id", found.OrderID,
+			)
+			orderResp, err = found, nil
+		case !errors.Is(findErr, exchange.ErrOrderNotFound):
+			config.Logger.Errorw("下单结果无法确认，转入后台确认",
+				"error", err.Error(),
+				"confirm_error", findErr.Error(),
+				"system_order_id", systemOrderID,
+				"symbol", orderRecord.Symbol,
+			)
+			
+			orderRecord.Status = OrderStatusUnknown
+			orderRecord.OrderID = "unknown_" + systemOrderID
+			if err := createOrderRecord(&orderRecord, EventSourcePlacement, nil, err.Error()); err != nil {
+				config.Logger.Errorw("保存待确认订单记录失败",
+					"error", err.Error(),
+				)
+			}
+			go e.reconcileOrder(orderRecord, ex, exchangeName, orderReq)
+			
+			return fmt.Errorf("%w: %w", ErrOrderUnconfirmed, err)
+		}
+	}
+	
 	if err != nil {
 		config.Logger.Errorw("下单失败",
 			"error", err.Error(),
-			"symbol", signal.Symbol,
-			"action", signal.Action,
+			"symbol", orderRecord.Symbol,
+			"action", orderRecord.Action,
 		)
 		
 		// 更新订单状态为失败
-		orderRecord.Status = "failed"
+		orderRecord.Status = OrderStatusFailed
 		
 		// 为失败的订单生成一个唯一的OrderID，避免唯一索引冲突
-		// 使用时间戳和随机数组合生成一个临时的OrderID
+		// 使用时间戳和系统订单号组合生成一个临时的OrderID
 		timeNow := time.Now().UnixNano()
-		orderRecord.OrderID = fmt.Sprintf("failed_%d_%d", timeNow, signal.ID)
-		
-
+		orderRecord.OrderID = fmt.Sprintf("failed_%d_%s", timeNow, systemOrderID)
 		
-		if err := repository.DB.Create(&orderRecord).Error; err != nil {
+		if err := createOrderRecord(&orderRecord, EventSourcePlacement, nil, err.Error()); err != nil {
 			config.Logger.Errorw("保存失败订单记录失败",
 				"error", err.Error(),
 			)
 		}
 		
-		return ErrOrderFailed
+		return fmt.Errorf("%w: %w", ErrOrderFailed, err)
 	}
 	
 	// 5. 更新订单信息
 	orderRecord.OrderID = orderResp.OrderID
-	orderRecord.Status = orderResp.Status
+	orderRecord.Status = OrderStatusOpen
+	if status, statusErr := orderStatusOf(orderResp.Status, orderResp.FilledQty); statusErr == nil {
+		orderRecord.Status = status
+	}
+	if orderResp.FilledQty > 0 && orderResp.FilledPrice > 0 {
+		// 下单时已经成交的部分，没有成交价格时留给订单监控的状态查询补齐，否则这部分成交无法计入持仓账本
+		orderRecord.FilledPrice = orderResp.FilledPrice
+		orderRecord.FilledAmount = orderResp.FilledQty
+		orderRecord.Fee = orderResp.Fee
+		orderRecord.FeeCurrency = orderResp.FeeCurrency
+	}
 	
 	// 6. 保存订单信息到数据库
-	if err := repository.DB.Create(&orderRecord).Error; err != nil {
+	if err := createOrderRecord(&orderRecord, EventSourcePlacement, orderResp, ""); err != nil {
 		config.Logger.Errorw("保存订单记录失败",
 			"error", err.Error(),
 			"order_id", orderRecord.OrderID,
@@ -180,49 +244,33 @@ func (e *Engine) ProcessSignal(signal models.TradingSignal) error {
 	}
 	
 	// 7. 启动订单监控
-	e.monitor.StartMonitor(&orderRecord, exchangeType)
+	e.monitor.StartMonitor(&orderRecord, exchangeName)
 	
 	return nil
 }
 
-// getExchangeByContractType 根据合约类型获取对应的交易所
-func (e *Engine) getExchangeByContractType(contractType int) (exchange.Exchange, string, error) {
-	e.mutex.RLock()
-	defer e.mutex.RUnlock()
-	
-	// 根据合约类型选择交易所
-	var exchangeType string
-	
-	switch contractType {
-	case constants.ContractTypeCrypto: // 虚拟货币
-		// 默认使用现货交易
-		exchangeType = constants.ExchangeTypeSpot
-	default:
-		return nil, "", ErrInvalidContractType
-	}
-	
-	ex, ok := e.exchanges[exchangeType]
-	if !ok {
-		return nil, "", ErrInvalidContractType
-	}
-	
-	return ex, exchangeType, nil
-}
-
 // determineOrderParams 确定下单参数
-func (e *Engine) determineOrderParams(signal models.TradingSignal, ex exchange.Exchange) (models.OrderParams, error) {
-	// 根据合约类型选择不同的下单策略
-	// 目前只实现了现货交易策略，合约交易策略待实现
+func (e *Engine) determineOrderParams(signal models.TradingSignal, ex exchange.Exchange, exchangeType string) (models.OrderParams, error) {
+	// 根据交易所类型选择不同的下单策略
+	if exchangeType == constants.ExchangeTypeFutures {
+		return DetermineFuturesOrderStrategy(signal, ex)
+	}
 	return DetermineSpotOrderStrategy(signal, ex)
 }
 
-// getBaseCurrency 从交易对中提取基础货币
-func getBaseCurrency(symbol string) string {
-	// 简单实现，假设交易对格式为"BTC_USDT"，基础货币为"USDT"
-	// 实际实现应该更复杂，处理不同交易所的不同格式
-	parts := strings.Split(symbol, "_")
-	if len(parts) > 1 {
-		return parts[1]
+// resolveOrderMode 确定信号的订单类型和有效方式
+// 信号指定了订单类型时只使用信号中的有效方式，否则使用策略配置
+func resolveOrderMode(signal models.TradingSignal) (string, string, error) {
+	orderType, timeInForce := signal.OrderType, signal.TimeInForce
+	if orderType == "" {
+		var stra models.Strategy
+		strategyID, _ := strconv.ParseUint(signal.StrategyID, 10, 64)
+		if err := repository.DB.Select("order_type", "time_in_force").First(&stra, strategyID).Error; err == nil {
+			orderType = stra.OrderType
+			if timeInForce == "" {
+				timeInForce = stra.TimeInForce
+			}
+		}
 	}
-	return "USDT" // 默认返回USDT
-}
\ No newline at end of file
+	return exchange.NormalizeOrderMode(orderType, timeInForce)
+}
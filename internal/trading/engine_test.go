@@ -0,0 +1,241 @@
+package trading
+
+import (
+	"math"
+	"net/http"
+	"order_go/internal/constants"
+	"order_go/internal/database"
+	"order_go/internal/exchange/gateio/gatetest"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/utils/config"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	testKey    = "test-key"
+	testSecret = "test-secret"
+	testSymbol = "BTC_USDT"
+	testPrice  = 50000.0
+)
+
+// 引擎测试需要PostgreSQL（持仓账本使用行锁和表锁），通过ORDER_GO_TEST_DSN指定专用的测试库，
+// 例如 "host=localhost user=postgres password=postgres dbname=order_go_test sslmode=disable"，未设置时跳过
+func TestMain(m *testing.M) {
+	config.Logger = zap.NewNop().Sugar()
+
+	if dsn := os.Getenv("ORDER_GO_TEST_DSN"); dsn != "" {
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if err != nil {
+			panic("连接测试数据库失败: " + err.Error())
+		}
+		database.MigrateDB(db)
+		repository.DB = db
+	}
+	os.Exit(m.Run())
+}
+
+// newTestEngine 启动Gate.io替身，按替身地址注册默认账户的现货交易所
+// 账户有10000 USDT，交易对最大交易额度为账户总价值的50%，首次开仓使用其中一半
+func newTestEngine(t *testing.T, timeout time.Duration) *gatetest.Server {
+	t.Helper()
+	if repository.DB == nil {
+		t.Skip("未设置ORDER_GO_TEST_DSN，跳过需要数据库的引擎测试")
+	}
+
+	srv := gatetest.NewServer(testKey, testSecret)
+	t.Cleanup(srv.Close)
+	srv.SetPrice(testSymbol, testPrice)
+	srv.SetPair(testSymbol, 2, 4)
+	srv.SetBalance("USDT", 10000, 0)
+
+	cfg := &config.Config{}
+	cfg.Monitor.Timeout = "10s"
+	cfg.Monitor.Interval = "50ms"
+	cfg.OrderStrategy.InitialOrderRatio = 0.5
+	cfg.Exchanges = map[string]config.ExchangeConfig{
+		"gateio": {
+			ApiKey:    testKey,
+			ApiSecret: testSecret,
+			BaseURL:   srv.BaseURL(),
+			WSURL:     "ws://127.0.0.1:1/ws/v4/", // 推送不可用，订单监控轮询替身
+			Timeout:   timeout.String(),
+		},
+	}
+	config.AppConfig = cfg
+
+	if err := repository.DB.Exec("TRUNCATE order_records, order_events, positions, contract_codes, exchanges, strategies RESTART IDENTITY").Error; err != nil {
+		t.Fatalf("清空测试数据失败: %v", err)
+	}
+	rows := []interface{}{
+		&models.Exchange{Name: "Gate.io现货", Code: "gateio", Type: "gateio", Status: true},
+		&models.ContractCode{Symbol: testSymbol, Code: "BTC", MinAmount: 0.0001, AmountPrecision: 4, PricePrecision: 2, MaxPositionRatio: 50, MarketType: constants.ExchangeTypeSpot},
+	}
+	for _, row := range rows {
+		if err := repository.DB.Create(row).Error; err != nil {
+			t.Fatalf("写入测试数据失败: %v", err)
+		}
+	}
+
+	if err := GetEngine().ReloadExchanges(); err != nil {
+		t.Fatalf("注册交易所失败: %v", err)
+	}
+	t.Cleanup(func() {
+		// 移除交易所，关闭推送重连
+		repository.DB.Where("1 = 1").Delete(&models.Exchange{})
+		GetEngine().ReloadExchanges()
+	})
+	return srv
+}
+
+// buySignal 策略1的限价买入信号
+func buySignal() models.TradingSignal {
+	return models.TradingSignal{
+		Symbol:       testSymbol,
+		Scode:        "BTC",
+		ContractType: constants.ContractTypeCrypto,
+		Price:        testPrice,
+		Action:       "buy",
+		TimeCircle:   "5m",
+		StrategyID:   "1",
+		OrderType:    "limit",
+	}
+}
+
+// waitFor 等待条件成立，超时后测试失败
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// fillAndWait 在替身上按信号价格全部成交订单，手续费以基础币支付，等待订单监控结束
+func fillAndWait(t *testing.T, srv *gatetest.Server, order gatetest.Order, fee float64) {
+	t.Helper()
+	srv.FillOrder(order.ID, order.Amount, testPrice, fee, "BTC")
+	waitFor(t, "订单监控结束", func() bool {
+		return len(GetOrderMonitor().GetActiveOrders()) == 0
+	})
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// onlyOrder 获取替身上唯一的订单
+func onlyOrder(t *testing.T, srv *gatetest.Server) gatetest.Order {
+	t.Helper()
+	orders := srv.Orders()
+	if len(orders) != 1 {
+		t.Fatalf("交易所上应只有一个订单, got %d", len(orders))
+	}
+	return orders[0]
+}
+
+// TestSignalFilledThroughMonitor 信号下单后由订单监控轮询到成交，订单记录、状态事件和持仓账本一致
+func TestSignalFilledThroughMonitor(t *testing.T) {
+	srv := newTestEngine(t, 0)
+
+	if err := GetEngine().ProcessSignal(buySignal()); err != nil {
+		t.Fatalf("ProcessSignal: %v", err)
+	}
+	placed := onlyOrder(t, srv)
+	if placed.Side != "buy" || placed.Type != "limit" || placed.Price != testPrice || placed.Amount <= 0 {
+		t.Fatalf("下单参数错误: %+v", placed)
+	}
+
+	fee := placed.Amount * 0.002
+	fillAndWait(t, srv, placed, fee)
+
+	var record models.OrderRecord
+	if err := repository.DB.Where("order_id = ?", placed.ID).First(&record).Error; err != nil {
+		t.Fatalf("查询订单记录失败: %v", err)
+	}
+	if !strings.HasSuffix(placed.Text, record.SystemOrderID) {
+		t.Errorf("客户端订单ID应为系统订单号, text=%s system_order_id=%s", placed.Text, record.SystemOrderID)
+	}
+	if record.Status != OrderStatusFilled || record.StrategyID != 1 || record.Account != "" {
+		t.Errorf("订单记录错误: status=%s strategy_id=%d account=%q", record.Status, record.StrategyID, record.Account)
+	}
+	if !almostEqual(record.FilledAmount, placed.Amount) || record.FilledPrice != testPrice ||
+		!almostEqual(record.Fee, fee) || record.FeeCurrency != "BTC" {
+		t.Errorf("成交信息错误: %+v", record)
+	}
+	if record.QuoteRate != 1 || record.ValueCurrency != "USDT" || !almostEqual(record.FeeValue, fee*testPrice) {
+		t.Errorf("折算价值错误: quote_rate=%v fee_value=%v %s", record.QuoteRate, record.FeeValue, record.ValueCurrency)
+	}
+
+	var events []models.OrderEvent
+	repository.DB.Where("order_record_id = ?", record.ID).Order("id").Find(&events)
+	if len(events) != 2 {
+		t.Fatalf("应有下单和成交两个事件, got %d", len(events))
+	}
+	if e := events[0]; e.FromStatus != "" || e.ToStatus != OrderStatusOpen || e.Source != EventSourcePlacement {
+		t.Errorf("下单事件错误: %s -> %s (%s)", e.FromStatus, e.ToStatus, e.Source)
+	}
+	if e := events[1]; e.FromStatus != OrderStatusOpen || e.ToStatus != OrderStatusFilled || e.Source != EventSourceRestPoll ||
+		!almostEqual(e.FilledAmount, placed.Amount) {
+		t.Errorf("成交事件错误: %s -> %s (%s) filled=%v", e.FromStatus, e.ToStatus, e.Source, e.FilledAmount)
+	}
+
+	// 策略行和账户汇总行都计入这笔成交，买入的手续费从基础币中扣除
+	var ledger []models.PositionLedger
+	repository.DB.Where("symbol = ?", testSymbol).Order("strategy_id").Find(&ledger)
+	if len(ledger) != 2 || ledger[0].StrategyID != 0 || ledger[1].StrategyID != 1 {
+		t.Fatalf("应有账户汇总行和策略行, got %+v", ledger)
+	}
+	for _, row := range ledger {
+		if !almostEqual(row.Quantity, placed.Amount-fee) || row.EntryPrice != testPrice ||
+			!almostEqual(row.Fees, fee*testPrice) || row.LastOrderID != placed.ID {
+			t.Errorf("持仓账本错误(strategy_id=%d): %+v", row.StrategyID, row)
+		}
+	}
+}
+
+// TestCreateTimeoutNoDuplicateOrder 下单请求超时但订单已在交易所创建时，按客户端订单ID确认订单，不重复下单
+func TestCreateTimeoutNoDuplicateOrder(t *testing.T) {
+	timeout := 200 * time.Millisecond
+	srv := newTestEngine(t, timeout)
+	// 替身先创建订单，超过客户端超时时间后才返回响应
+	srv.InjectFault(gatetest.RouteCreateOrder, gatetest.Fault{Status: http.StatusOK, Delay: 3 * timeout, Apply: true})
+
+	if err := GetEngine().ProcessSignal(buySignal()); err != nil {
+		t.Fatalf("订单已创建，确认后应按下单成功处理, got %v", err)
+	}
+	if n := srv.Requests(gatetest.RouteCreateOrder); n != 1 {
+		t.Fatalf("下单超时后不能重新下单, got %d次下单请求", n)
+	}
+	placed := onlyOrder(t, srv)
+
+	var records []models.OrderRecord
+	repository.DB.Find(&records)
+	if len(records) != 1 || records[0].OrderID != placed.ID || records[0].Status != OrderStatusOpen {
+		t.Fatalf("应只保存一条已确认的订单记录, got %+v", records)
+	}
+
+	fillAndWait(t, srv, placed, placed.Amount*0.002)
+
+	var record models.OrderRecord
+	repository.DB.First(&record, records[0].ID)
+	if record.Status != OrderStatusFilled || !almostEqual(record.FilledAmount, placed.Amount) {
+		t.Errorf("确认后的订单应继续监控到成交, got status=%s filled=%v", record.Status, record.FilledAmount)
+	}
+	var rows int64
+	repository.DB.Model(&models.PositionLedger{}).Where("symbol = ? AND strategy_id = 1", testSymbol).Count(&rows)
+	if rows != 1 {
+		t.Errorf("成交应计入策略持仓账本, got %d行", rows)
+	}
+}
@@ -0,0 +1,301 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+	"order_go/internal/constants"
+	"order_go/internal/exchange"
+	"order_go/internal/marketdata"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/utils/config"
+	"strconv"
+	"time"
+)
+
+// ErrExchangeUnavailable 没有可用于下单的交易所
+var ErrExchangeUnavailable = errors.New("没有可用的交易所")
+
+// venue 按exchanges表记录注册的交易所，创建后不再修改
+type venue struct {
+	id        uint
+	code      string
+	kind      string
+	account   string
+	market    string
+	updatedAt time.Time
+	ex        exchange.Exchange
+}
+
+// loadExchanges 启动时注册exchanges表中启用的交易所
+// 表为空时先按配置文件写入默认记录，与原先固定注册的交易所保持一致
+func (e *Engine) loadExchanges() {
+	if err := seedExchanges(); err != nil {
+		config.Logger.Errorw("初始化交易所记录失败",
+			"error", err.Error(),
+		)
+	}
+	if err := e.ReloadExchanges(); err != nil {
+		config.Logger.Errorw("加载交易所失败",
+			"error", err.Error(),
+		)
+	}
+}
+
+// seedExchanges exchanges表为空时写入Gate.io现货和永续合约，以及配置文件中启用的其他交易所
+// 写入的记录不带密钥，仍使用配置文件中的密钥
+func seedExchanges() error {
+	var count int64
+	if err := repository.DB.Model(&models.Exchange{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	rows := []models.Exchange{
+		{Name: "Gate.io现货", Code: exchange.KindGateIO, Type: exchange.KindGateIO, Status: true},
+		{Name: "Gate.io永续合约", Code: exchange.KindGateIOFutures, Type: exchange.KindGateIOFutures, Status: true},
+	}
+	optional := []struct{ kind, name string }{
+		{exchange.KindBinance, "Binance现货"},
+		{exchange.KindOKX, "OKX现货"},
+		{exchange.KindPaper, "模拟盘"},
+	}
+	for _, o := range optional {
+		if _, ok := config.GetExchangeConfig(o.kind); ok {
+			rows = append(rows, models.Exchange{Name: o.name, Code: o.kind, Type: o.kind, Status: true})
+		}
+	}
+
+	config.Logger.Infow("exchanges表为空，按配置文件写入交易所记录", "count", len(rows))
+	return repository.DB.Create(&rows).Error
+}
+
+// ReloadExchanges 按exchanges表中启用的记录重建交易所注册表，后台新增、修改或停用交易所后调用
+// 未修改的记录保留原实例；修改过的重新创建；停用、删除或无法创建的从注册表和订单监控中移除
+// 已在监控中的订单继续使用下单时的实例直到结束；被替换或移除的实例的K线缓存同时清除
+func (e *Engine) ReloadExchanges() error {
+	var rows []models.Exchange
+	if err := repository.DB.Where("status = ?", true).Order("id").Find(&rows).Error; err != nil {
+		return fmt.Errorf("查询交易所失败: %w", err)
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	active := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		old, exists := e.exchanges[row.Code]
+		if exists && old.id == row.ID && old.updatedAt.Equal(row.UpdatedAt) {
+			active[row.Code] = true
+			continue
+		}
+
+		v, err := newVenue(row)
+		if err != nil {
+			config.Logger.Errorw("创建交易所实例失败",
+				"error", err.Error(),
+				"exchange_id", row.ID,
+				"code", row.Code,
+			)
+			continue
+		}
+		active[row.Code] = true
+
+		if exists {
+			e.monitor.UnregisterExchange(row.Code)
+			marketdata.ForgetExchange(old.ex)
+		}
+		e.exchanges[row.Code] = v
+		e.monitor.RegisterExchange(row.Code, v.ex)
+
+		config.Logger.Infow("交易所已注册",
+			"exchange_id", v.id,
+			"code", v.code,
+			"type", v.kind,
+			"account", v.account,
+			"market", v.market,
+		)
+	}
+
+	for code, v := range e.exchanges {
+		if active[code] {
+			continue
+		}
+		delete(e.exchanges, code)
+		e.monitor.UnregisterExchange(code)
+		marketdata.ForgetExchange(v.ex)
+
+		config.Logger.Infow("交易所已移除",
+			"exchange_id", v.id,
+			"code", code,
+		)
+	}
+	return nil
+}
+
+// newVenue 按交易所记录创建实例，记录中的密钥优先于配置文件
+func newVenue(row models.Exchange) (*venue, error) {
+	kind := row.Kind()
+	cfg := exchange.LookupConfig(row.Code, kind)
+	if row.ApiKey != "" {
+		cfg.ApiKey = row.ApiKey
+		cfg.ApiSecret = row.ApiSecret
+	}
+	if row.Passphrase != "" {
+		cfg.Passphrase = row.Passphrase
+	}
+
+	ex, err := exchange.New(kind, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// 所有交易所都经过限流和重试装饰，下单策略和订单监控共用同一组限流器
+	return &venue{
+		id:        row.ID,
+		code:      row.Code,
+		kind:      kind,
+		account:   row.Account,
+		market:    exchange.MarketOf(kind),
+		updatedAt: row.UpdatedAt,
+		ex:        exchange.NewRateLimited(row.Code, ex),
+	}, nil
+}
+
+// GetExchange 按交易所代码获取交易所，spot和futures表示默认账户中对应市场的交易所
+func (e *Engine) GetExchange(name string) (exchange.Exchange, bool) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	if v, ok := e.exchanges[name]; ok {
+		return v.ex, true
+	}
+	if v := e.findVenue("", func(v *venue) bool { return v.market == name }); v != nil {
+		return v.ex, true
+	}
+	return nil, false
+}
+
+// findVenue 获取账户中满足条件且ID最小的交易所，调用方需持有锁
+func (e *Engine) findVenue(account string, match func(v *venue) bool) *venue {
+	var found *venue
+	for _, v := range e.exchanges {
+		if v.account == account && match(v) && (found == nil || v.id < found.id) {
+			found = v
+		}
+	}
+	return found
+}
+
+// venueByID 按交易所ID获取已注册的交易所，调用方需持有锁
+func (e *Engine) venueByID(id uint) *venue {
+	for _, v := range e.exchanges {
+		if v.id == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// resolveVenue 确定信号下单的交易所，只在策略绑定的账户中选择
+// 现货交易对优先使用配置文件中按策略或交易对指定的交易所，其次是交易对配置的交易所类型，最后是账户中对应市场的交易所
+func (e *Engine) resolveVenue(signal models.TradingSignal) (*venue, error) {
+	if signal.ContractType != constants.ContractTypeCrypto {
+		return nil, ErrInvalidContractType
+	}
+
+	strategyID, _ := strconv.ParseUint(signal.StrategyID, 10, 64)
+	return e.resolveAccountVenue(strategyAccount(uint(strategyID)), uint(strategyID), signal.Symbol)
+}
+
+// resolveAccountVenue 在账户中确定交易对下单的交易所，strategyID用于查找配置文件中按策略指定的交易所
+func (e *Engine) resolveAccountVenue(account string, strategyID uint, symbol string) (*venue, error) {
+	// 交易对未配置时按现货处理
+	var contractCode models.ContractCode
+	hasContract := repository.DB.Select("market_type", "exchange_id").Where("symbol = ?", symbol).First(&contractCode).Error == nil
+	marketType := constants.ExchangeTypeSpot
+	if hasContract && contractCode.MarketType == constants.ExchangeTypeFutures {
+		marketType = constants.ExchangeTypeFutures
+	}
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	if marketType == constants.ExchangeTypeSpot {
+		if name, ok := config.GetExchangeForSignal(strategyID, symbol); ok {
+			if v, registered := e.exchanges[name]; registered && v.market == marketType && v.account == account {
+				return v, nil
+			}
+		}
+	}
+
+	if hasContract && contractCode.ExchangeID != nil {
+		pinned := e.venueByID(*contractCode.ExchangeID)
+		if pinned == nil {
+			return nil, fmt.Errorf("%w: 交易对配置的交易所(ID %d)不存在或已停用", ErrExchangeUnavailable, *contractCode.ExchangeID)
+		}
+		if pinned.market != marketType {
+			return nil, fmt.Errorf("%w: 交易对配置的交易所%s不支持%s市场", ErrExchangeUnavailable, pinned.code, marketType)
+		}
+		if pinned.account == account {
+			return pinned, nil
+		}
+
+		// 交易对指定的交易所属于其他账户时，换成策略账户中同类型的交易所
+		if v := e.findVenue(account, func(v *venue) bool { return v.kind == pinned.kind }); v != nil {
+			return v, nil
+		}
+		return nil, fmt.Errorf("%w: %s没有%s类型的交易所", ErrExchangeUnavailable, accountLabel(account), pinned.kind)
+	}
+
+	if v := e.findVenue(account, func(v *venue) bool { return v.market == marketType }); v != nil {
+		return v, nil
+	}
+	return nil, fmt.Errorf("%w: %s没有%s交易所", ErrExchangeUnavailable, accountLabel(account), constants.GetExchangeTypeName(marketType))
+}
+
+// ExchangeForSignal 获取信号下单时使用的交易所，供策略校验信号时读取行情
+func (e *Engine) ExchangeForSignal(signal models.TradingSignal) (exchange.Exchange, error) {
+	v, err := e.resolveVenue(signal)
+	if err != nil {
+		return nil, err
+	}
+	return v.ex, nil
+}
+
+// ExchangeForSymbol 获取默认账户中交易对下单使用的交易所及其代码，供行情记录等后台任务使用
+func (e *Engine) ExchangeForSymbol(symbol string) (exchange.Exchange, string, error) {
+	v, err := e.resolveVenue(models.TradingSignal{Symbol: symbol, ContractType: constants.ContractTypeCrypto})
+	if err != nil {
+		return nil, "", err
+	}
+	return v.ex, v.code, nil
+}
+
+// ExchangeForAccount 获取账户中交易对下单使用的交易所，供持仓核对等按账户查询的任务使用
+func (e *Engine) ExchangeForAccount(account, symbol string) (exchange.Exchange, error) {
+	v, err := e.resolveAccountVenue(account, 0, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return v.ex, nil
+}
+
+// strategyAccount 获取策略绑定的交易所账户，策略不存在或未绑定时为默认账户
+func strategyAccount(strategyID uint) string {
+	var stra models.Strategy
+	if err := repository.DB.Select("account").First(&stra, strategyID).Error; err != nil {
+		return ""
+	}
+	return stra.Account
+}
+
+// accountLabel 返回日志和错误信息中使用的账户名称
+func accountLabel(account string) string {
+	if account == "" {
+		return "默认账户"
+	}
+	return "账户" + account
+}
@@ -0,0 +1,222 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+	"order_go/internal/constants"
+	"order_go/internal/exchange"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/utils/config"
+	"order_go/internal/utils/orderid"
+)
+
+// defaultChaseSteps 策略未设置重新挂单次数时使用的次数
+const defaultChaseSteps = 3
+
+// executionPolicy 策略在订单监控超时后处理剩余数量的设置
+type executionPolicy struct {
+	policy string
+	steps  int
+}
+
+// strategyExecution 获取策略的执行方式，策略不存在时撤单放弃
+func strategyExecution(strategyID uint) executionPolicy {
+	p := executionPolicy{policy: constants.ExecutionPolicyCancel, steps: defaultChaseSteps}
+
+	var stra models.Strategy
+	if err := repository.DB.Select("execution_policy", "chase_steps").First(&stra, strategyID).Error; err == nil {
+		if stra.ExecutionPolicy != "" {
+			p.policy = stra.ExecutionPolicy
+		}
+		if stra.ChaseSteps > 0 {
+			p.steps = stra.ChaseSteps
+		}
+	}
+	return p
+}
+
+// executeRemainder 订单超时撤单后，按策略的执行方式为未成交的剩余数量重新下单
+// 重新下的订单记录ParentID指向最初的订单，重新挂单次数按最初订单下的子订单数量计算；
+// 子订单同样受监控，超时后继续按执行方式处理，直到成交、次数用完或不足最小交易量
+func (e *Engine) executeRemainder(order *models.OrderRecord, ex exchange.Exchange, exchangeName string) {
+	var record models.OrderRecord
+	if err := repository.DB.Where("order_id = ?", order.OrderID).First(&record).Error; err != nil {
+		config.Logger.Errorw("查询超时订单记录失败",
+			"error", err.Error(),
+			"order_id", order.OrderID,
+		)
+		return
+	}
+
+	p := strategyExecution(record.StrategyID)
+	if p.policy == constants.ExecutionPolicyCancel || record.Status == OrderStatusFilled {
+		return
+	}
+
+	remaining := roundAmount(record.Amount-record.FilledAmount, record.Symbol)
+	if remaining <= 0 {
+		config.Logger.Infow("剩余数量不足最小交易量，不再重新下单",
+			"order_id", record.OrderID,
+			"symbol", record.Symbol,
+			"amount", record.Amount,
+			"filled_amount", record.FilledAmount,
+		)
+		return
+	}
+
+	rootID := record.ID
+	if record.ParentID != nil {
+		rootID = *record.ParentID
+	}
+	var children int64
+	if err := repository.DB.Model(&models.OrderRecord{}).Where("parent_id = ?", rootID).Count(&children).Error; err != nil {
+		config.Logger.Errorw("查询子订单数量失败",
+			"error", err.Error(),
+			"order_id", record.OrderID,
+		)
+		return
+	}
+
+	logFields := []interface{}{
+		"order_id", record.OrderID,
+		"parent_id", rootID,
+		"symbol", record.Symbol,
+		"action", record.Action,
+		"policy", p.policy,
+		"step", children + 1,
+		"remaining", remaining,
+	}
+
+	orderType, timeInForce := "limit", record.TimeInForce
+	var price float64
+	switch p.policy {
+	case constants.ExecutionPolicyChase:
+		if int(children) >= p.steps {
+			config.Logger.Warnw("重新挂单次数已用完，放弃剩余数量", append(logFields, "max_steps", p.steps)...)
+			return
+		}
+		var err error
+		if price, err = chasePrice(ex, &record, rootID); err != nil {
+			config.Logger.Warnw("确定重新挂单价格失败，放弃剩余数量", append(logFields, "error", err.Error())...)
+			return
+		}
+	case constants.ExecutionPolicyMarket:
+		// 市价单超时说明交易所没有按预期立即结束订单，不再重复下单
+		if record.OrderType == "market" {
+			config.Logger.Warnw("市价单未能成交，放弃剩余数量", logFields...)
+			return
+		}
+		orderType, timeInForce = "market", ""
+		price = record.Price
+		if book, err := ex.GetOrderBook(record.Symbol, pricingBookDepth); err == nil {
+			// 市价买单按卖一价换算金额，卖出按买一价估算
+			if record.Action == "buy" && book.BestAsk() > 0 {
+				price = book.BestAsk()
+			} else if record.Action != "buy" && book.BestBid() > 0 {
+				price = book.BestBid()
+			}
+		}
+	default:
+		config.Logger.Errorw("不支持的执行方式", logFields...)
+		return
+	}
+
+	orderType, timeInForce, err := exchange.NormalizeOrderMode(orderType, timeInForce)
+	if err != nil {
+		config.Logger.Errorw("重新下单的订单类型无效", append(logFields, "error", err.Error())...)
+		return
+	}
+
+	if contractCode, err := getFullContractConfig(record.Symbol); err == nil {
+		if err := checkMinNotional(contractCode, remaining, price); err != nil {
+			config.Logger.Warnw("剩余数量不足最小下单金额，放弃剩余数量", append(logFields, "price", price, "error", err.Error())...)
+			return
+		}
+	}
+
+	systemOrderID, err := orderid.GenerateOrderID(orderid.TypeCrypto)
+	if err != nil {
+		config.Logger.Errorw("生成系统订单号失败，放弃剩余数量", append(logFields, "error", err.Error())...)
+		return
+	}
+
+	child := models.OrderRecord{
+		SystemOrderID: systemOrderID,
+		ParentID:      &rootID,
+		StrategyID:    record.StrategyID,
+		TimeCircle:    record.TimeCircle,
+		ExchangeID:    record.ExchangeID,
+		Account:       record.Account,
+		Symbol:        record.Symbol,
+		ContractType:  record.ContractType,
+		ContractCode:  record.ContractCode,
+		OrderType:     orderType,
+		TimeInForce:   timeInForce,
+		Price:         price,
+		Amount:        remaining,
+		Action:        record.Action,
+		PositionSide:  record.PositionSide,
+		Status:        OrderStatusCreated,
+	}
+	orderReq := &exchange.OrderRequest{
+		Symbol:       child.Symbol,
+		Price:        child.Price,
+		Amount:       child.Amount,
+		Side:         child.Action,
+		Type:         child.OrderType,
+		TimeInForce:  child.TimeInForce,
+		PositionSide: child.PositionSide,
+		ClientID:     systemOrderID,
+	}
+
+	config.Logger.Infow("订单监控超时，为剩余数量重新下单", append(logFields, "order_type", orderType, "price", price)...)
+	if err := e.submitOrder(child, orderReq, ex, exchangeName); err != nil {
+		config.Logger.Errorw("剩余数量重新下单失败", append(logFields, "error", err.Error())...)
+	}
+}
+
+// chasePrice 按最新盘口确定重新挂单的价格，与best定价方式一致：买入按卖一价、卖出按买一价，再偏移策略设置的价格单位
+// 策略设置了滑点限制时，相对最初订单价格的不利变动超过限制则放弃
+func chasePrice(ex exchange.Exchange, record *models.OrderRecord, rootID uint) (float64, error) {
+	book, err := ex.GetOrderBook(record.Symbol, pricingBookDepth)
+	if err != nil {
+		return 0, fmt.Errorf("获取盘口失败: %w", err)
+	}
+	bid, ask := book.BestBid(), book.BestAsk()
+	if bid <= 0 || ask <= 0 {
+		return 0, fmt.Errorf("交易对%s的盘口为空", record.Symbol)
+	}
+
+	contractCode, err := getFullContractConfig(record.Symbol)
+	if err != nil {
+		return 0, err
+	}
+	tick := math.Pow10(-contractCode.PricePrecision)
+
+	p := strategyPricing(record.StrategyID)
+	isBuy := record.Action == "buy"
+	price := bid - float64(p.ticks)*tick
+	if isBuy {
+		price = ask + float64(p.ticks)*tick
+	}
+	price = roundPrice(price, contractCode.PricePrecision, isBuy)
+	if price <= 0 {
+		return 0, fmt.Errorf("重新挂单价格无效: %v", price)
+	}
+
+	if p.maxSlippage > 0 {
+		var root models.OrderRecord
+		if err := repository.DB.Select("price").First(&root, rootID).Error; err == nil && root.Price > 0 {
+			slippage := (root.Price - price) / root.Price
+			if isBuy {
+				slippage = (price - root.Price) / root.Price
+			}
+			if slippage > p.maxSlippage {
+				return 0, fmt.Errorf("%w: 最初价格%v，重新挂单价格%v，偏离%.4f%%，限制%.4f%%",
+					ErrSlippageExceeded, root.Price, price, slippage*100, p.maxSlippage*100)
+			}
+		}
+	}
+	return price, nil
+}
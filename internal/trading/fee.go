@@ -0,0 +1,166 @@
+package trading
+
+import (
+	"math"
+	"order_go/internal/account"
+	"order_go/internal/exchange"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/utils/config"
+	"sync"
+	"time"
+)
+
+const (
+	// feeRatesTTL 手续费率缓存时间，费率只随VIP等级和抵扣设置变化
+	feeRatesTTL = time.Hour
+
+	// defaultFeeRate 未配置fee_rate时预留的手续费率，与Gate.io现货普通用户的费率一致
+	defaultFeeRate = 0.002
+)
+
+// feeRatesKey 手续费率缓存的键，不同账户的交易所实例分别缓存
+type feeRatesKey struct {
+	ex     exchange.Exchange
+	symbol string
+}
+
+// cachedFeeRates 缓存的手续费率
+type cachedFeeRates struct {
+	rates     exchange.FeeRates
+	expiresAt time.Time
+}
+
+var feeRatesCache sync.Map // feeRatesKey -> cachedFeeRates
+
+// getFeeRates 获取账户在交易对上的手续费率
+// 交易所不支持查询或查询失败时使用配置的fee_rate，且不缓存，下次重新查询
+func getFeeRates(ex exchange.Exchange, symbol string) exchange.FeeRates {
+	key := feeRatesKey{ex: ex, symbol: symbol}
+	if cached, ok := feeRatesCache.Load(key); ok && time.Now().Before(cached.(cachedFeeRates).expiresAt) {
+		return cached.(cachedFeeRates).rates
+	}
+
+	if provider, ok := ex.(exchange.FeeRateProvider); ok {
+		rates, err := provider.GetFeeRates(symbol)
+		if err == nil {
+			feeRatesCache.Store(key, cachedFeeRates{rates: *rates, expiresAt: time.Now().Add(feeRatesTTL)})
+			return *rates
+		}
+		config.Logger.Warnw("获取手续费率失败，使用配置的费率",
+			"error", err.Error(),
+			"symbol", symbol,
+		)
+	}
+
+	rate := defaultFeeRate
+	if config.AppConfig != nil && config.AppConfig.OrderStrategy.FeeRate > 0 {
+		rate = config.AppConfig.OrderStrategy.FeeRate
+	}
+	return exchange.FeeRates{Maker: rate, Taker: rate}
+}
+
+// buyFeeReserve 买入时为手续费预留的比例
+// 限价单也可能立即成交，按吃单费率预留；手续费由GT或点卡抵扣时不需要预留
+func buyFeeReserve(ex exchange.Exchange, symbol string) float64 {
+	rates := getFeeRates(ex, symbol)
+	if rates.Deducted || rates.Taker <= 0 {
+		return 0
+	}
+	return rates.Taker
+}
+
+// fitSpotCloseAmount 按可卖余额调整现货平仓数量
+// 持仓量包含挂单锁定的部分，平仓数量不能超过可用余额；
+// 买入手续费从基础币中扣除后，平仓剩下的零头可能不足最小交易量而无法卖出，此时一并平掉。
+// 按策略持仓时position是策略自己的持仓，一并平掉的数量不超过策略持仓，不会卖出其他策略的持仓
+func fitSpotCloseAmount(ex exchange.Exchange, symbol string, position *models.Position, closeAmount, minAmount float64) float64 {
+	base, _, err := splitSymbol(symbol)
+	if err != nil {
+		return closeAmount
+	}
+
+	available, _, err := ex.GetBalance(base)
+	if err != nil {
+		config.Logger.Warnw("获取可卖余额失败，按持仓量平仓",
+			"error", err.Error(),
+			"symbol", symbol,
+		)
+		return closeAmount
+	}
+
+	fitted := closeAmount
+	if position.Size-closeAmount < minAmount {
+		fitted = math.Min(available, position.Size)
+	}
+	if fitted > available {
+		fitted = available
+	}
+	fitted = roundAmount(fitted, symbol)
+
+	if fitted != closeAmount {
+		config.Logger.Infow("按可卖余额调整平仓数量",
+			"symbol", symbol,
+			"position_size", position.Size,
+			"available", available,
+			"close_amount", closeAmount,
+			"fitted_amount", fitted,
+		)
+	}
+	return fitted
+}
+
+// recordOrderValue 订单结束后记录计价币种折合账户计价币种的汇率，并将手续费折算为账户计价币种，用于统计盈亏
+// 已实现盈亏和成交额以交易对计价币种表示，统计时按这里记录的汇率折算，与手续费使用同一时刻的行情
+func recordOrderValue(orderID string, ex exchange.Exchange) {
+	var record models.OrderRecord
+	if err := repository.DB.Select("symbol", "filled_amount", "fee", "fee_currency").Where("order_id = ?", orderID).First(&record).Error; err != nil {
+		return
+	}
+	if record.FilledAmount == 0 {
+		return
+	}
+
+	currency := account.ReportingCurrency()
+	updates := map[string]interface{}{}
+	rates := make(map[string]float64)
+	convert := func(from string) (float64, bool) {
+		rate, ok := rates[from]
+		if ok {
+			return rate, true
+		}
+		rate, err := account.ConversionRate(ex, from, currency)
+		if err != nil {
+			config.Logger.Warnw("折算为账户计价币种失败",
+				"error", err.Error(),
+				"order_id", orderID,
+				"currency", from,
+			)
+			return 0, false
+		}
+		rates[from] = rate
+		return rate, true
+	}
+
+	if _, quote, err := splitSymbol(record.Symbol); err == nil {
+		if rate, ok := convert(quote); ok {
+			updates["quote_rate"] = rate
+		}
+	}
+	if record.Fee != 0 && record.FeeCurrency != "" {
+		if rate, ok := convert(record.FeeCurrency); ok {
+			updates["fee_value"] = record.Fee * rate
+		}
+	}
+	if len(updates) == 0 {
+		return
+	}
+	updates["value_currency"] = currency
+
+	if err := repository.DB.Model(&models.OrderRecord{}).Where("order_id = ?", orderID).Updates(updates).Error; err != nil {
+		config.Logger.Errorw("保存订单折算价值失败",
+			"error", err.Error(),
+			"order_id", orderID,
+		)
+	}
+}
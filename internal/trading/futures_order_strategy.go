@@ -0,0 +1,246 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"order_go/internal/account"
+	"order_go/internal/exchange"
+	"order_go/internal/models"
+	"order_go/internal/utils/config"
+	"strings"
+)
+
+// DetermineFuturesOrderStrategy 确定永续合约交易的下单策略
+// 单向持仓模式下，持仓量为正表示多仓、为负表示空仓：
+//   - 无持仓：买入开多，卖出开空
+//   - 信号方向与持仓方向相同：加仓
+//   - 信号方向与持仓方向相反：只减仓平仓
+func DetermineFuturesOrderStrategy(signal models.TradingSignal, ex exchange.Exchange) (models.OrderParams, error) {
+	// 初始化下单参数
+	params := models.OrderParams{
+		Symbol:    signal.Symbol,
+		Price:     signal.Price,
+		Action:    signal.Action,
+		OrderType: "limit", // 默认限价单
+	}
+
+	if config.AppConfig == nil {
+		panic("配置文件未加载，无法获取下单策略参数")
+	}
+
+	// 1. 检查当前持仓情况
+	position, err := ex.GetPosition(signal.Symbol)
+	if err != nil {
+		config.Logger.Errorw("获取合约持仓信息失败",
+			"error", err.Error(),
+			"symbol", signal.Symbol,
+		)
+		return params, err
+	}
+
+	contractCode, err := getFullContractConfig(signal.Symbol)
+	if err != nil {
+		config.Logger.Errorw("获取交易对配置失败",
+			"error", err.Error(),
+			"symbol", signal.Symbol,
+		)
+		return params, err
+	}
+
+	positionSize := 0.0
+	if position != nil {
+		positionSize = position.Size
+	}
+
+	// 持仓量绝对值小于最小交易量视为无持仓
+	if math.Abs(positionSize) < contractCode.MinAmount {
+		config.Logger.Infow("没有现有合约持仓，按照信号开仓",
+			"symbol", signal.Symbol,
+			"action", signal.Action,
+			"position_size", positionSize,
+		)
+
+		amount, err := calculateFuturesOpenAmount(signal, ex, position, contractCode, config.AppConfig.OrderStrategy.InitialOrderRatio, false)
+		if err != nil {
+			return params, err
+		}
+
+		params.PositionSide = "open"
+		params.Amount = amount
+		return params, nil
+	}
+
+	isLong := positionSize > 0
+	isBuy := signal.Action == "buy"
+
+	// 2. 信号方向与持仓方向相同，执行加仓
+	if isLong == isBuy {
+		config.Logger.Infow("信号方向与合约持仓方向相同，执行加仓操作",
+			"symbol", signal.Symbol,
+			"action", signal.Action,
+			"current_position", positionSize,
+		)
+
+		amount, err := calculateFuturesOpenAmount(signal, ex, position, contractCode, config.AppConfig.OrderStrategy.AddPositionRatio, true)
+		if err != nil {
+			return params, err
+		}
+
+		params.PositionSide = "open" // 加仓也是开仓操作
+		params.Amount = amount
+		return params, nil
+	}
+
+	// 3. 信号方向与持仓方向相反，执行平仓
+	closeAmount := calculateFuturesCloseAmount(signal, ex, math.Abs(positionSize), contractCode)
+
+	config.Logger.Infow("信号方向与合约持仓方向相反，执行平仓操作",
+		"symbol", signal.Symbol,
+		"action", signal.Action,
+		"position_size", positionSize,
+		"close_amount", fmt.Sprintf("%.*f", contractCode.AmountPrecision, closeAmount),
+	)
+
+	params.PositionSide = "close"
+	params.Amount = closeAmount
+	return params, nil
+}
+
+// calculateFuturesOpenAmount 计算永续合约开仓或加仓数量
+// 交易对最大交易额度按名义价值计算，可用保证金按杠杆折算
+func calculateFuturesOpenAmount(signal models.TradingSignal, ex exchange.Exchange, position *models.Position, contractCode models.ContractCode, ratio float64, isAdd bool) (float64, error) {
+	parts := strings.Split(signal.Symbol, "_")
+	if len(parts) < 2 {
+		err := errors.New("无效的交易对格式")
+		config.Logger.Errorw(err.Error(),
+			"symbol", signal.Symbol,
+		)
+		return 0, err
+	}
+
+	// 永续合约以结算币种作为保证金
+	settleCurrency := parts[1]
+
+	// 最大交易额度按账户计价币种计算，名义价值以结算币种表示
+	rate, err := reportingRate(ex, settleCurrency)
+	if err != nil {
+		return 0, err
+	}
+
+	totalValue, err := account.GetTotalValue(ex)
+	if err != nil {
+		config.Logger.Errorw("获取账户总价值失败",
+			"error", err.Error(),
+		)
+		return 0, err
+	}
+
+	// 计算交易对的最大名义价值（账户总价值 × 最大交易额度比例）
+	maxPositionValue := totalValue * contractCode.MaxPositionRatio / 100.0
+
+	currentPositionValue := 0.0
+	leverage := 1
+	if position != nil {
+		currentPositionValue = math.Abs(position.Size) * signal.Price * rate
+		if position.Leverage > 0 {
+			leverage = position.Leverage
+		}
+	}
+
+	remainingFunds := maxPositionValue - currentPositionValue
+	if remainingFunds <= 0 {
+		config.Logger.Warnw("已达到或超过交易对最大交易额度限制",
+			"symbol", signal.Symbol,
+			"max_position_value", maxPositionValue,
+			"current_position_value", currentPositionValue,
+			"max_position_ratio", contractCode.MaxPositionRatio,
+		)
+		return 0, ErrExceedMaxPositionRatio
+	}
+
+	// 加仓时检查剩余额度比例
+	if isAdd && remainingFunds/maxPositionValue < config.AppConfig.OrderStrategy.MinAddPositionRatio {
+		config.Logger.Warnw("剩余可用资金比例过低，不进行加仓",
+			"symbol", signal.Symbol,
+			"remaining_funds", remainingFunds,
+			"remaining_ratio", remainingFunds/maxPositionValue,
+			"min_add_position_ratio_threshold", config.AppConfig.OrderStrategy.MinAddPositionRatio,
+		)
+		return 0, ErrInsufficientAddPositionRatio
+	}
+
+	desiredValue := remainingFunds * ratio / rate // 换算为结算币种
+	requiredMargin := desiredValue / float64(leverage)
+
+	available, _, err := ex.GetBalance(settleCurrency)
+	if err != nil {
+		config.Logger.Errorw("获取合约账户余额失败",
+			"error", err.Error(),
+			"currency", settleCurrency,
+		)
+		return 0, err
+	}
+
+	if available < requiredMargin {
+		config.Logger.Warnw("合约账户可用保证金不足",
+			"symbol", signal.Symbol,
+			"available", available,
+			"required_margin", requiredMargin,
+			"leverage", leverage,
+		)
+		return 0, ErrInsufficientBalance
+	}
+
+	config.Logger.Infow("计算合约开仓数量",
+		"symbol", signal.Symbol,
+		"total_value", totalValue,
+		"max_position_value", maxPositionValue,
+		"current_position_value", currentPositionValue,
+		"desired_value", desiredValue,
+		"required_margin", requiredMargin,
+		"leverage", leverage,
+		"available", available,
+	)
+
+	amount := roundAmount(desiredValue/signal.Price, signal.Symbol)
+	if amount == 0 {
+		err := fmt.Errorf("%w: 计算的交易数量小于最小交易量 %.5f", ErrBelowMinAmount, contractCode.MinAmount)
+		config.Logger.Warnw(err.Error(),
+			"symbol", signal.Symbol,
+			"min_amount", contractCode.MinAmount,
+		)
+		return 0, err
+	}
+
+	return amount, nil
+}
+
+// calculateFuturesCloseAmount 计算永续合约平仓数量
+// 规则与现货一致：持仓占额度比例不高于阈值时全部平仓，否则按平仓比例平仓
+func calculateFuturesCloseAmount(signal models.TradingSignal, ex exchange.Exchange, positionSize float64, contractCode models.ContractCode) float64 {
+	strategyCfg := config.AppConfig.OrderStrategy
+	closeAmount := roundAmount(positionSize*strategyCfg.ClosePositionRatio, signal.Symbol)
+
+	// 持仓比例较低时全部平仓
+	totalValue, err := account.GetTotalValue(ex)
+	if err != nil {
+		config.Logger.Errorw("获取账户总价值失败，使用默认平仓比例",
+			"error", err.Error(),
+		)
+	} else if maxPositionValue := totalValue * contractCode.MaxPositionRatio / 100.0; maxPositionValue > 0 &&
+		positionSize*signal.Price*quoteRateOrPar(ex, signal.Symbol)/maxPositionValue <= strategyCfg.MinPositionRatio {
+		closeAmount = roundAmount(positionSize, signal.Symbol)
+	}
+
+	// 平仓数量小于最小交易量时，持仓足够则使用最小交易量，否则全部平仓
+	if closeAmount < contractCode.MinAmount {
+		if positionSize >= contractCode.MinAmount {
+			closeAmount = roundAmount(contractCode.MinAmount, signal.Symbol)
+		} else {
+			closeAmount = roundAmount(positionSize, signal.Symbol)
+		}
+	}
+
+	return closeAmount
+}
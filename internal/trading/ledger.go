@@ -0,0 +1,216 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+	"order_go/internal/constants"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/utils/config"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ledgerEpsilon 持仓数量的浮点容差，小于该值视为已平仓
+const ledgerEpsilon = 1e-9
+
+// orderFill 订单新增的一段成交
+type orderFill struct {
+	qty         float64
+	price       float64
+	fee         float64
+	feeCurrency string
+	at          time.Time
+}
+
+// fillDelta 按订单前后两次的累计成交计算新增的成交
+// 交易所只返回累计成交量和成交均价，新增部分的价格按前后成交额之差折算
+func fillDelta(prev *models.OrderRecord, qty, price, fee float64, feeCurrency string) orderFill {
+	f := orderFill{
+		qty:         qty - prev.FilledAmount,
+		price:       price,
+		fee:         fee - prev.Fee,
+		feeCurrency: feeCurrency,
+		at:          time.Now(),
+	}
+	if f.qty > 0 && prev.FilledAmount > 0 {
+		if p := (qty*price - prev.FilledAmount*prev.FilledPrice) / f.qty; p > 0 {
+			f.price = p
+		}
+	}
+	if f.fee < 0 || (prev.FeeCurrency != "" && prev.FeeCurrency != feeCurrency) {
+		f.fee = fee
+	}
+	return f
+}
+
+// recordFill 将订单的一段成交计入持仓账本，更新策略自己的行和账户汇总行
+// 在更新订单记录的同一事务中调用，保证每段成交只计入一次。
+// 返回这段成交在策略持仓上产生的已实现盈亏，未绑定策略的订单按账户汇总持仓计算
+func recordFill(tx *gorm.DB, order *models.OrderRecord, f orderFill) (float64, error) {
+	if f.qty <= 0 || f.price <= 0 {
+		return 0, nil
+	}
+
+	strategyIDs := []uint{0}
+	if order.StrategyID != 0 {
+		strategyIDs = append(strategyIDs, order.StrategyID)
+	}
+	var realized float64
+	for _, strategyID := range strategyIDs {
+		pnl, err := updateLedger(tx, order, strategyID, f)
+		if err != nil {
+			return 0, fmt.Errorf("更新持仓账本失败: %w", err)
+		}
+		realized = pnl
+	}
+	return realized, nil
+}
+
+// updateLedger 锁定并更新持仓账本中的一行，不存在时先创建，返回这段成交的已实现盈亏
+func updateLedger(tx *gorm.DB, order *models.OrderRecord, strategyID uint, f orderFill) (float64, error) {
+	row := models.PositionLedger{
+		Account:    order.Account,
+		Symbol:     order.Symbol,
+		StrategyID: strategyID,
+		MarketType: order.ContractType,
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("account = ? AND symbol = ? AND strategy_id = ?", order.Account, order.Symbol, strategyID).
+		First(&row).Error; err != nil {
+		return 0, err
+	}
+
+	realizedBefore := row.RealizedPnl
+	applyFill(&row, order, f)
+
+	row.LastOrderID = order.OrderID
+	row.LastFillAt = &f.at
+	if err := tx.Save(&row).Error; err != nil {
+		return 0, err
+	}
+	return row.RealizedPnl - realizedBefore, nil
+}
+
+// applyFill 按一笔成交更新持仓数量、加权平均开仓价格和已实现盈亏
+// 同方向成交按数量加权平均开仓价格；反方向成交先按平均开仓价格平掉已有持仓，超出部分按成交价反向开仓。
+// 现货不能做空，卖出超过账本数量的部分是账本建立前的持仓，成本未知，不计入盈亏
+func applyFill(row *models.PositionLedger, order *models.OrderRecord, f orderFill) {
+	spot := order.ContractType != constants.ExchangeTypeFutures
+	base, quote, _ := splitSymbol(order.Symbol)
+
+	qty := f.qty
+	var feeValue float64
+	switch f.feeCurrency {
+	case quote:
+		feeValue = f.fee
+	case base:
+		feeValue = f.fee * f.price
+		// 现货买入的手续费从买到的基础币中扣除
+		if spot && order.Action == "buy" {
+			qty -= f.fee
+		}
+	}
+	row.Fees += feeValue
+	row.Volume += f.qty * f.price
+
+	signed := qty
+	if order.Action != "buy" {
+		signed = -qty
+	}
+
+	// 同方向加仓或从空仓开仓
+	if math.Abs(row.Quantity) < ledgerEpsilon || (row.Quantity > 0) == (signed > 0) {
+		total := math.Abs(row.Quantity) + math.Abs(signed)
+		if total > 0 {
+			row.EntryPrice = (math.Abs(row.Quantity)*row.EntryPrice + math.Abs(signed)*f.price) / total
+		}
+		row.Quantity += signed
+		if spot && row.Quantity < 0 {
+			config.Logger.Warnw("现货卖出数量超过持仓账本数量，超出部分不计入持仓",
+				"symbol", order.Symbol,
+				"order_id", order.OrderID,
+				"strategy_id", row.StrategyID,
+				"quantity", row.Quantity,
+			)
+			row.Quantity, row.EntryPrice = 0, 0
+		}
+		return
+	}
+
+	// 反方向成交，先平掉已有持仓
+	closing := math.Min(math.Abs(signed), math.Abs(row.Quantity))
+	direction := 1.0
+	if row.Quantity < 0 {
+		direction = -1
+	}
+	row.RealizedPnl += closing * (f.price - row.EntryPrice) * direction
+	row.Quantity += signed
+
+	switch {
+	case math.Abs(row.Quantity) < ledgerEpsilon:
+		row.Quantity, row.EntryPrice = 0, 0
+	case (row.Quantity > 0) != (direction > 0):
+		// 反手，剩余数量按成交价开仓
+		if spot {
+			config.Logger.Warnw("现货卖出数量超过持仓账本数量，超出部分不计入持仓",
+				"symbol", order.Symbol,
+				"order_id", order.OrderID,
+				"strategy_id", row.StrategyID,
+				"excess", -row.Quantity,
+			)
+			row.Quantity, row.EntryPrice = 0, 0
+		} else {
+			row.EntryPrice = f.price
+		}
+	}
+}
+
+// RebuildPositionLedger 按订单记录中的累计成交重新生成持仓账本和订单的已实现盈亏，用于账本上线前的历史订单或数据修复
+// 每个订单按创建顺序作为一笔成交计入；重建期间锁定账本，同时到达的成交等重建完成后再计入
+func RebuildPositionLedger() (int, error) {
+	count := 0
+	err := repository.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE positions IN EXCLUSIVE MODE").Error; err != nil {
+			return fmt.Errorf("锁定持仓账本失败: %w", err)
+		}
+		if err := tx.Where("1 = 1").Delete(&models.PositionLedger{}).Error; err != nil {
+			return fmt.Errorf("清空持仓账本失败: %w", err)
+		}
+		if err := tx.Model(&models.OrderRecord{}).Where("realized_pnl <> 0").Update("realized_pnl", 0).Error; err != nil {
+			return fmt.Errorf("清空订单已实现盈亏失败: %w", err)
+		}
+
+		var orders []models.OrderRecord
+		if err := tx.Where("filled_amount > 0").Order("id").Find(&orders).Error; err != nil {
+			return fmt.Errorf("查询成交订单失败: %w", err)
+		}
+		for i := range orders {
+			order := &orders[i]
+			f := fillDelta(&models.OrderRecord{}, order.FilledAmount, order.FilledPrice, order.Fee, order.FeeCurrency)
+			f.at = order.UpdatedAt
+			realized, err := recordFill(tx, order, f)
+			if err != nil {
+				return fmt.Errorf("订单%s: %w", order.OrderID, err)
+			}
+			if realized != 0 {
+				if err := tx.Model(&models.OrderRecord{}).Where("id = ?", order.ID).Update("realized_pnl", realized).Error; err != nil {
+					return fmt.Errorf("订单%s: 保存已实现盈亏失败: %w", order.OrderID, err)
+				}
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	config.Logger.Infow("持仓账本已重建", "orders", count)
+	return count, nil
+}
@@ -2,20 +2,28 @@ package trading
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"order_go/internal/exchange"
 	"order_go/internal/models"
-	"order_go/internal/repository"
 	"order_go/internal/utils/config"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // OrderMonitor 订单监控器
 type OrderMonitor struct {
 	activeOrders sync.Map       // 当前活跃订单
+	orderUpdates sync.Map       // 订单ID -> *orderUpdateQueue，仅监控中的订单存在
 	exchanges    map[string]exchange.Exchange
+	exchangesMu  sync.RWMutex
+}
+
+// orderUpdateQueue 监控中订单的推送更新队列
+type orderUpdateQueue struct {
+	updates chan *exchange.OrderResponse
+	dropped atomic.Bool // 有更新因队列已满被丢弃，下次轮询时需查询订单状态
 }
 
 var (
@@ -34,14 +42,75 @@ func GetOrderMonitor() *OrderMonitor {
 }
 
 // RegisterExchange 注册交易所
+// 支持订单推送的交易所会同时订阅推送，推送可用时不再轮询订单状态
 func (m *OrderMonitor) RegisterExchange(name string, ex exchange.Exchange) {
+	m.exchangesMu.Lock()
 	m.exchanges[name] = ex
+	m.exchangesMu.Unlock()
+	
+	streamer, ok := ex.(exchange.OrderStreamer)
+	if !ok {
+		return
+	}
+	if err := streamer.SubscribeOrders(m.dispatchOrderUpdate); err != nil {
+		config.Logger.Infow("交易所未启用订单推送，使用轮询监控订单",
+			"exchange", name,
+			"reason", err.Error(),
+		)
+	}
+}
+
+// UnregisterExchange 移除交易所并关闭其订单推送
+// 已在监控中的订单继续使用原实例，推送关闭后改为轮询直到结束
+func (m *OrderMonitor) UnregisterExchange(name string) {
+	m.exchangesMu.Lock()
+	ex, ok := m.exchanges[name]
+	delete(m.exchanges, name)
+	m.exchangesMu.Unlock()
+	
+	if !ok {
+		return
+	}
+	if streamer, ok := ex.(exchange.OrderStreamer); ok {
+		streamer.CloseOrderStream()
+	}
+}
+
+// getExchange 按名称获取已注册的交易所
+func (m *OrderMonitor) getExchange(name string) (exchange.Exchange, bool) {
+	m.exchangesMu.RLock()
+	defer m.exchangesMu.RUnlock()
+	
+	ex, ok := m.exchanges[name]
+	return ex, ok
+}
+
+// dispatchOrderUpdate 将推送的订单更新转发给对应订单的监控协程
+// 不在监控中的订单（例如其他系统下的单）直接忽略
+func (m *OrderMonitor) dispatchOrderUpdate(symbol string, update *exchange.OrderResponse) {
+	v, ok := m.orderUpdates.Load(update.OrderID)
+	if !ok {
+		return
+	}
+	queue := v.(*orderUpdateQueue)
+	
+	select {
+	case queue.updates <- update:
+	default:
+		// 队列已满说明监控协程处理不过来，推送连接正常时也在下次轮询时查询一次订单状态，补上丢弃的更新
+		queue.dropped.Store(true)
+		config.Logger.Warnw("订单推送更新积压，已丢弃",
+			"order_id", update.OrderID,
+			"symbol", symbol,
+			"status", update.Status,
+		)
+	}
 }
 
 // StartMonitor 开始监控订单
 func (m *OrderMonitor) StartMonitor(order *models.OrderRecord, exchangeName string) {
 	// 获取交易所
-	ex, ok := m.exchanges[exchangeName]
+	ex, ok := m.getExchange(exchangeName)
 	if !ok {
 		config.Logger.Errorw("交易所不存在",
 			"exchange", exchangeName,
@@ -54,13 +123,16 @@ func (m *OrderMonitor) StartMonitor(order *models.OrderRecord, exchangeName stri
 	m.activeOrders.Store(order.OrderID, order)
 
 	// 启动监控协程
-	go m.monitorOrder(order, ex)
+	go m.monitorOrder(order, ex, exchangeName)
 }
 
 // monitorOrder 监控订单状态
-func (m *OrderMonitor) monitorOrder(order *models.OrderRecord, ex exchange.Exchange) {
+// 超时撤单后按策略的执行方式处理剩余数量，重新下的订单仍通过exchangeName开始监控
+func (m *OrderMonitor) monitorOrder(order *models.OrderRecord, ex exchange.Exchange, exchangeName string) {
 	// 监控结束时从活跃订单列表中移除
 	defer m.activeOrders.Delete(order.OrderID)
+	// 监控结束时记录计价币种汇率和手续费价值
+	defer recordOrderValue(order.OrderID, ex)
 
 	// 从配置文件中读取监控超时时间
 	if config.AppConfig == nil {
@@ -97,9 +169,26 @@ func (m *OrderMonitor) monitorOrder(order *models.OrderRecord, ex exchange.Excha
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	// 接收订单推送更新
+	queue := &orderUpdateQueue{updates: make(chan *exchange.OrderResponse, 16)}
+	m.orderUpdates.Store(order.OrderID, queue)
+	defer m.orderUpdates.Delete(order.OrderID)
+
+	streamer, _ := ex.(exchange.OrderStreamer)
+	streaming := false
+
 	for {
 		select {
 		case <-ticker.C:
+			// 推送连接可用时跳过轮询。刚开始监控、连接刚恢复或有推送被丢弃时仍查询一次，
+			// 补上注册监控前、断线期间或队列积压时漏掉的推送
+			connected := streamer != nil && streamer.OrderStreamConnected()
+			if connected && streaming && !queue.dropped.Load() {
+				continue
+			}
+			streaming = connected
+			queue.dropped.Store(false)
+			
 			// 查询订单状态
 			orderStatus, err := ex.GetOrderStatus(order.Symbol, order.OrderID)
 			if err != nil {
@@ -110,268 +199,137 @@ func (m *OrderMonitor) monitorOrder(order *models.OrderRecord, ex exchange.Excha
 				continue
 			}
 			
-			// 检查订单状态是否发生变化
-			var previousStatus string
-			if err := repository.DB.Model(&models.OrderRecord{}).Where("order_id = ?", order.OrderID).Select("status").Scan(&previousStatus).Error; err != nil {
-				config.Logger.Errorw("获取订单之前的状态失败",
-					"error", err.Error(),
-					"order_id", order.OrderID,
-				)
-			}
-			
-			// 只有在状态变化时才输出日志
-			if previousStatus != orderStatus.Status {
-				config.Logger.Infow("订单状态变更",
-					"order_id", order.OrderID,
-					"symbol", order.Symbol,
-					"previous_status", previousStatus,
-					"current_status", orderStatus.Status,
-					"filled_amount", orderStatus.FilledQty,
-					"filled_price", orderStatus.FilledPrice,
-					"fee", orderStatus.Fee,
-				)
-			}
-
-			// 更新订单状态
-			updates := map[string]interface{}{
-				"status": orderStatus.Status,
-			}
-
-			// 如果订单已成交，更新成交信息
-			if orderStatus.Status == "filled" {
-				updates["filled_price"] = orderStatus.FilledPrice
-				updates["filled_amount"] = orderStatus.FilledQty
-				updates["fee"] = orderStatus.Fee
-				updates["fee_currency"] = orderStatus.FeeCurrency
-				// 订单完成时间自动记录在updated_at字段中，不需要单独设置completed_at
-			}
-
-			// 更新数据库中的订单信息
-			if err := repository.DB.Model(&models.OrderRecord{}).Where("order_id = ?", order.OrderID).Updates(updates).Error; err != nil {
-				config.Logger.Errorw("更新订单信息失败",
-					"error", err.Error(),
-					"order_id", order.OrderID,
-				)
-			}
-
-			// 如果订单已成交或已取消，结束监控
-			if orderStatus.Status == "filled" || orderStatus.Status == "canceled" {
-				config.Logger.Infow("订单监控结束",
-					"order_id", order.OrderID,
-					"status", orderStatus.Status,
-					"symbol", order.Symbol,
-				)
+			if m.applyOrderStatus(order, orderStatus, EventSourceRestPoll) {
 				return
 			}
 
-		case <-ctx.Done():
-			// 超时前，先检查订单最新状态
-			orderStatus, err := ex.GetOrderStatus(order.Symbol, order.OrderID)
-			if err != nil {
-				config.Logger.Errorw("超时前查询订单状态失败",
-					"error", err.Error(),
-					"order_id", order.OrderID,
-				)
-				// 查询失败，继续尝试撤单
-			} else if orderStatus.Status == "filled" {
-				// 订单已完全成交，不需要撤单
-				config.Logger.Infow("订单已完全成交，无需撤单",
-					"order_id", order.OrderID,
-					"symbol", order.Symbol,
-				)
-				
-				// 更新数据库中的订单信息
-				updates := map[string]interface{}{
-					"status":       "filled",
-					"filled_price": orderStatus.FilledPrice,
-					"filled_amount": orderStatus.FilledQty,
-					"fee":          orderStatus.Fee,
-					"fee_currency": orderStatus.FeeCurrency,
-					// 订单完成时间自动记录在updated_at字段中
-				}
-				
-				if err := repository.DB.Model(&models.OrderRecord{}).Where("order_id = ?", order.OrderID).Updates(updates).Error; err != nil {
-					config.Logger.Errorw("更新已成交订单信息失败",
-						"error", err.Error(),
-						"order_id", order.OrderID,
-					)
-				}
-				return
-			} else if orderStatus.Status == "canceled" {
-				// 订单已被取消，不需要再撤单
-				config.Logger.Infow("订单已被取消，无需撤单",
-					"order_id", order.OrderID,
-					"symbol", order.Symbol,
-				)
+		case update := <-queue.updates:
+			if m.applyOrderStatus(order, update, EventSourceWebsocket) {
 				return
-			} else if orderStatus.FilledQty > 0 && orderStatus.FilledQty < order.Amount {
-				// 订单部分成交，记录日志
-				config.Logger.Warnw("订单部分成交，尝试撤销剩余部分",
-					"order_id", order.OrderID,
-					"symbol", order.Symbol,
-					"filled_amount", orderStatus.FilledQty,
-					"total_amount", order.Amount,
-				)
 			}
-			
-			// 尝试撤单
-			config.Logger.Warnw("订单监控超时，尝试撤单",
-				"order_id", order.OrderID,
-				"symbol", order.Symbol,
-			)
-
-			if err := ex.CancelOrder(order.Symbol, order.OrderID); err != nil {
-				// 检查是否是订单不存在的错误
-				isOrderNotFound := strings.Contains(strings.ToLower(err.Error()), "not found") || 
-								   strings.Contains(strings.ToLower(err.Error()), "does not exist")
-				
-				if isOrderNotFound {
-					config.Logger.Warnw("撤单失败：订单不存在，正在检查订单状态",
-						"error", err.Error(),
-						"order_id", order.OrderID,
-					)
-				} else {
-					config.Logger.Errorw("撤单失败",
-						"error", err.Error(),
-						"order_id", order.OrderID,
-					)
-				}
-				
-				// 无论是什么原因的撤单失败，都再次检查订单状态
-				latestStatus, checkErr := ex.GetOrderStatus(order.Symbol, order.OrderID)
-				
-				if checkErr != nil {
-					// 检查订单状态也失败，记录错误
-					config.Logger.Errorw("撤单失败后检查订单状态也失败",
-						"error", checkErr.Error(),
-						"order_id", order.OrderID,
-					)
-					
-					// 如果有部分成交信息，仍然更新订单状态
-					if orderStatus != nil && orderStatus.FilledQty > 0 {
-						updates := map[string]interface{}{
-							"filled_price": orderStatus.FilledPrice,
-							"filled_amount": orderStatus.FilledQty,
-							"fee": orderStatus.Fee,
-							"fee_currency": orderStatus.FeeCurrency,
-							"status": "partially_filled",
-						}
-						
-						if err := repository.DB.Model(&models.OrderRecord{}).Where("order_id = ?", order.OrderID).Updates(updates).Error; err != nil {
-							config.Logger.Errorw("更新部分成交订单状态失败",
-								"error", err.Error(),
-								"order_id", order.OrderID,
-							)
-						}
-					}
-				} else if latestStatus.Status == "filled" {
-					// 订单已完全成交，更新状态
-					config.Logger.Infow("撤单失败原因：订单已完全成交",
-						"order_id", order.OrderID,
-						"symbol", order.Symbol,
-						"filled_amount", latestStatus.FilledQty,
-						"filled_price", latestStatus.FilledPrice,
-					)
-					
-					updates := map[string]interface{}{
-						"status":       "filled",
-						"filled_price": latestStatus.FilledPrice,
-						"filled_amount": latestStatus.FilledQty,
-						"fee":          latestStatus.Fee,
-						"fee_currency": latestStatus.FeeCurrency,
-						// 订单完成时间自动记录在updated_at字段中
-					}
-					
-					if updateErr := repository.DB.Model(&models.OrderRecord{}).Where("order_id = ?", order.OrderID).Updates(updates).Error; updateErr != nil {
-						config.Logger.Errorw("更新已成交订单信息失败",
-							"error", updateErr.Error(),
-							"order_id", order.OrderID,
-						)
-					}
-				} else if latestStatus.Status == "canceled" {
-					// 订单已被取消，更新状态
-					config.Logger.Infow("撤单失败原因：订单已被取消",
-						"order_id", order.OrderID,
-						"symbol", order.Symbol,
-					)
-					
-					updates := map[string]interface{}{
-						"status": "canceled",
-					}
-					
-					// 如果有部分成交，记录部分成交信息
-					if latestStatus.FilledQty > 0 {
-						updates["filled_price"] = latestStatus.FilledPrice
-						updates["filled_amount"] = latestStatus.FilledQty
-						updates["fee"] = latestStatus.Fee
-						updates["fee_currency"] = latestStatus.FeeCurrency
-						updates["status"] = "partially_filled"
-					}
-					
-					if err := repository.DB.Model(&models.OrderRecord{}).Where("order_id = ?", order.OrderID).Updates(updates).Error; err != nil {
-						config.Logger.Errorw("更新订单状态失败",
-							"error", err.Error(),
-							"order_id", order.OrderID,
-						)
-					}
-				} else if latestStatus.FilledQty > 0 {
-					// 订单部分成交，更新状态
-					config.Logger.Infow("撤单失败，订单部分成交",
-						"order_id", order.OrderID,
-						"symbol", order.Symbol,
-						"filled_amount", latestStatus.FilledQty,
-						"total_amount", order.Amount,
-					)
-					
-					updates := map[string]interface{}{
-						"status":       "partially_filled",
-						"filled_price": latestStatus.FilledPrice,
-						"filled_amount": latestStatus.FilledQty,
-						"fee":          latestStatus.Fee,
-						"fee_currency": latestStatus.FeeCurrency,
-					}
-					
-					if err := repository.DB.Model(&models.OrderRecord{}).Where("order_id = ?", order.OrderID).Updates(updates).Error; err != nil {
-						config.Logger.Errorw("更新部分成交订单状态失败",
-							"error", err.Error(),
-							"order_id", order.OrderID,
-						)
-					}
-				}
-			} else {
-				config.Logger.Infow("撤单成功",
-					"order_id", order.OrderID,
-					"symbol", order.Symbol,
-				)
 
-				// 更新数据库中的订单状态
-				updates := map[string]interface{}{
-					"status":       "canceled",
-					// 订单取消时间自动记录在updated_at字段中
-				}
-				
-				// 如果有部分成交，记录部分成交信息
-				if orderStatus != nil && orderStatus.FilledQty > 0 {
-					updates["filled_price"] = orderStatus.FilledPrice
-					updates["filled_amount"] = orderStatus.FilledQty
-					updates["fee"] = orderStatus.Fee
-					updates["fee_currency"] = orderStatus.FeeCurrency
-					updates["status"] = "partially_filled"
-				}
-				
-				if err := repository.DB.Model(&models.OrderRecord{}).Where("order_id = ?", order.OrderID).Updates(updates).Error; err != nil {
-					config.Logger.Errorw("更新订单状态失败",
-						"error", err.Error(),
-						"order_id", order.OrderID,
-					)
-				}
+		case <-ctx.Done():
+			if m.finishOnTimeout(order, ex) {
+				GetEngine().executeRemainder(order, ex, exchangeName)
 			}
-
 			return
 		}
 	}
 }
 
+// finishOnTimeout 监控超时后撤销未结束的订单，并以撤单后交易所返回的状态更新订单记录
+// 只有本次撤单成功且查询到撤单后的成交数量时返回true，此时才处理剩余数量
+func (m *OrderMonitor) finishOnTimeout(order *models.OrderRecord, ex exchange.Exchange) bool {
+	// 超时前，先检查订单最新状态，已结束的订单不需要撤单
+	orderStatus, err := ex.GetOrderStatus(order.Symbol, order.OrderID)
+	if err != nil {
+		config.Logger.Errorw("超时前查询订单状态失败",
+			"error", err.Error(),
+			"order_id", order.OrderID,
+		)
+		// 查询失败，继续尝试撤单
+		orderStatus = nil
+	} else if m.applyOrderStatus(order, orderStatus, EventSourceRestPoll) {
+		config.Logger.Infow("订单已结束，无需撤单",
+			"order_id", order.OrderID,
+			"symbol", order.Symbol,
+			"status", orderStatus.Status,
+		)
+		return false
+	} else if orderStatus.FilledQty > 0 {
+		config.Logger.Warnw("订单部分成交，尝试撤销剩余部分",
+			"order_id", order.OrderID,
+			"symbol", order.Symbol,
+			"filled_amount", orderStatus.FilledQty,
+			"total_amount", order.Amount,
+		)
+	}
+	
+	config.Logger.Warnw("订单监控超时，尝试撤单",
+		"order_id", order.OrderID,
+		"symbol", order.Symbol,
+	)
+	
+	cancelErr := ex.CancelOrder(order.Symbol, order.OrderID)
+	switch {
+	case cancelErr == nil:
+		config.Logger.Infow("撤单成功",
+			"order_id", order.OrderID,
+			"symbol", order.Symbol,
+		)
+	case errors.Is(cancelErr, exchange.ErrOrderNotFound) || errors.Is(cancelErr, exchange.ErrOrderClosed):
+		// 订单不存在或已结束时撤单失败是预期内的情况
+		config.Logger.Warnw("撤单失败：订单不存在或已结束，正在检查订单状态",
+			"error", cancelErr.Error(),
+			"order_id", order.OrderID,
+		)
+	default:
+		config.Logger.Errorw("撤单失败",
+			"error", cancelErr.Error(),
+			"order_id", order.OrderID,
+		)
+	}
+	
+	// 无论撤单是否成功，都以交易所的最新状态为准
+	latestStatus, checkErr := ex.GetOrderStatus(order.Symbol, order.OrderID)
+	if checkErr == nil {
+		// 撤单成功后交易所可能还未更新订单状态
+		if !m.applyOrderStatus(order, latestStatus, EventSourceRestPoll) && cancelErr == nil {
+			applyTransition(order, OrderTransition{
+				Status: OrderStatusCanceled,
+				Source: EventSourceRestPoll,
+				Update: latestStatus,
+				Note:   "监控超时撤单",
+			})
+		}
+		return cancelErr == nil
+	}
+	
+	config.Logger.Errorw("撤单后检查订单状态失败",
+		"error", checkErr.Error(),
+		"order_id", order.OrderID,
+	)
+	switch {
+	case cancelErr == nil:
+		// 撤单已成功，成交信息以超时前查询到的为准
+		applyTransition(order, OrderTransition{
+			Status: OrderStatusCanceled,
+			Source: EventSourceRestPoll,
+			Update: orderStatus,
+			Note:   "监控超时撤单，撤单后未能查询订单状态",
+		})
+	case orderStatus != nil && orderStatus.FilledQty > 0:
+		// 撤单和查询都失败，只记录已知的成交
+		applyTransition(order, OrderTransition{
+			Source: EventSourceRestPoll,
+			Update: orderStatus,
+			Note:   "监控超时撤单失败",
+		})
+	}
+	// 无法确认撤单前的最终成交数量，不处理剩余数量，避免重复成交
+	return false
+}
+
+// applyOrderStatus 按查询或推送得到的订单状态变更订单记录
+// 交易所返回订单已成交或已取消时返回true，表示监控可以结束
+func (m *OrderMonitor) applyOrderStatus(order *models.OrderRecord, orderStatus *exchange.OrderResponse, source string) bool {
+	applyTransition(order, OrderTransition{
+		Source: source,
+		Update: orderStatus,
+	})
+
+	// 如果订单已成交或已取消，结束监控
+	if orderStatus.Status == OrderStatusFilled || orderStatus.Status == OrderStatusCanceled {
+		config.Logger.Infow("订单监控结束",
+			"order_id", order.OrderID,
+			"status", orderStatus.Status,
+			"symbol", order.Symbol,
+		)
+		return true
+	}
+	return false
+}
+
 // GetActiveOrders 获取当前活跃订单
 func (m *OrderMonitor) GetActiveOrders() []*models.OrderRecord {
 	var orders []*models.OrderRecord
@@ -398,7 +356,7 @@ func (m *OrderMonitor) CancelOrder(orderID string, exchangeName string) error {
 	}
 
 	// 获取交易所
-	ex, ok := m.exchanges[exchangeName]
+	ex, ok := m.getExchange(exchangeName)
 	if !ok {
 		return nil
 	}
@@ -412,11 +370,12 @@ func (m *OrderMonitor) CancelOrder(orderID string, exchangeName string) error {
 		return err
 	}
 
-	// 更新数据库
-	if err := repository.DB.Model(&models.OrderRecord{}).Where("order_id = ?", order.OrderID).Updates(map[string]interface{}{
-		"status":       "canceled",
-		// 订单取消时间自动记录在updated_at字段中
-	}).Error; err != nil {
+	// 部分成交的订单由状态机记为部分成交，剩余成交信息由监控协程查询后补齐
+	if _, err := transitionOrder(order.OrderID, OrderTransition{
+		Status: OrderStatusCanceled,
+		Source: EventSourceManual,
+		Note:   "手动撤单",
+	}); err != nil {
 		config.Logger.Errorw("更新订单状态失败",
 			"error", err.Error(),
 			"order_id", order.OrderID,
@@ -0,0 +1,105 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+	"order_go/internal/exchange"
+	"order_go/internal/models"
+	"order_go/internal/utils/config"
+	"time"
+)
+
+// ErrOrderUnconfirmed 下单结果不确定，且暂时无法从交易所确认订单是否已创建
+var ErrOrderUnconfirmed = errors.New("下单结果待确认")
+
+// confirmDelays 确认订单时每次查询前的等待时间，刚创建的订单可能稍后才能查到
+var confirmDelays = []time.Duration{0, time.Second, 2 * time.Second}
+
+// confirmOrder 按客户端订单ID确认订单是否已在交易所创建
+// 查到订单时返回订单；多次查询均不存在时返回ErrOrderNotFound；交易所无法查询时返回查询错误
+func confirmOrder(ex exchange.Exchange, req *exchange.OrderRequest) (*exchange.OrderResponse, error) {
+	finder, ok := ex.(exchange.OrderFinder)
+	if !ok {
+		return nil, fmt.Errorf("交易所不支持按客户端订单ID查询")
+	}
+
+	var err error
+	for _, delay := range confirmDelays {
+		time.Sleep(delay)
+
+		var resp *exchange.OrderResponse
+		resp, err = finder.FindOrderByClientID(req.Symbol, req.ClientID)
+		if err == nil {
+			return resp, nil
+		}
+		if !errors.Is(err, exchange.ErrOrderNotFound) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// reconcileOrder 后台确认状态为unknown的订单
+// 查到订单后写回真实订单号并开始监控，确认不存在时记为失败，超过监控超时时间仍无法确认则需人工核对
+func (e *Engine) reconcileOrder(order models.OrderRecord, ex exchange.Exchange, exchangeName string, req *exchange.OrderRequest) {
+	interval, err := time.ParseDuration(config.AppConfig.Monitor.Interval)
+	if err != nil || interval <= 0 {
+		interval = 5 * time.Second
+	}
+	timeout, err := time.ParseDuration(config.AppConfig.Monitor.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = 10 * time.Minute
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+
+		resp, err := confirmOrder(ex, req)
+		if errors.Is(err, exchange.ErrOrderNotFound) {
+			config.Logger.Warnw("确认订单未在交易所创建，记为下单失败",
+				"system_order_id", order.SystemOrderID,
+				"symbol", order.Symbol,
+			)
+			applyTransition(&order, OrderTransition{
+				Status: OrderStatusFailed,
+				Source: EventSourceReconciliation,
+				Note:   "确认订单未在交易所创建",
+			})
+			return
+		}
+		if err != nil {
+			config.Logger.Warnw("确认订单状态失败，稍后重试",
+				"system_order_id", order.SystemOrderID,
+				"error", err.Error(),
+			)
+			continue
+		}
+
+		config.Logger.Warnw("确认订单已在交易所创建，开始监控",
+			"system_order_id", order.SystemOrderID,
+			"order_id", resp.OrderID,
+			"status", resp.Status,
+		)
+		// 交易所状态无法识别时按挂单处理，保证真实订单号写回记录
+		status, statusErr := orderStatusOf(resp.Status, resp.FilledQty)
+		if statusErr != nil {
+			status = OrderStatusOpen
+		}
+		order.Status = applyTransition(&order, OrderTransition{
+			Status: status,
+			Source: EventSourceReconciliation,
+			Update: resp,
+			Fields: map[string]interface{}{"order_id": resp.OrderID},
+		})
+		order.OrderID = resp.OrderID
+		e.monitor.StartMonitor(&order, exchangeName)
+		return
+	}
+
+	config.Logger.Errorw("订单长时间无法确认是否已创建，请人工核对",
+		"system_order_id", order.SystemOrderID,
+		"symbol", order.Symbol,
+		"exchange", exchangeName,
+	)
+}
@@ -0,0 +1,261 @@
+package trading
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"order_go/internal/exchange"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/utils/config"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 订单记录的状态
+// partially_filled表示有成交但未全部成交，包括仍在挂单和剩余部分已取消两种情况
+const (
+	OrderStatusCreated         = "created"
+	OrderStatusUnknown         = "unknown"
+	OrderStatusOpen            = "open"
+	OrderStatusPartiallyFilled = "partially_filled"
+	OrderStatusFilled          = "filled"
+	OrderStatusCanceled        = "canceled"
+	OrderStatusFailed          = "failed"
+)
+
+// 订单状态变更的来源
+const (
+	EventSourcePlacement      = "placement"      // 下单请求的结果
+	EventSourceRestPoll       = "rest_poll"      // 轮询订单状态
+	EventSourceWebsocket      = "websocket"      // 订单推送
+	EventSourceManual         = "manual"         // 后台手动操作
+	EventSourceReconciliation = "reconciliation" // 下单结果不确定时的后台确认
+)
+
+// ErrIllegalTransition 订单状态变更不合法，例如已结束的订单再次变更
+var ErrIllegalTransition = errors.New("非法的订单状态变更")
+
+// orderTransitions 允许的状态变更，状态不变但成交数量变化时不经过此表
+// filled、canceled和failed是结束状态，不能再变更
+var orderTransitions = map[string][]string{
+	"": {OrderStatusCreated, OrderStatusUnknown, OrderStatusOpen, OrderStatusPartiallyFilled,
+		OrderStatusFilled, OrderStatusCanceled, OrderStatusFailed},
+	OrderStatusCreated: {OrderStatusOpen, OrderStatusPartiallyFilled, OrderStatusFilled,
+		OrderStatusCanceled, OrderStatusFailed},
+	OrderStatusUnknown: {OrderStatusOpen, OrderStatusPartiallyFilled, OrderStatusFilled,
+		OrderStatusCanceled, OrderStatusFailed},
+	OrderStatusOpen:            {OrderStatusPartiallyFilled, OrderStatusFilled, OrderStatusCanceled},
+	OrderStatusPartiallyFilled: {OrderStatusFilled},
+}
+
+// canTransition 判断订单能否从from变更为to
+func canTransition(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// isFinalStatus 订单是否已经结束，结束的订单不会再有成交
+// partially_filled可能仍在挂单，需要由交易所返回的状态判断
+func isFinalStatus(status string) bool {
+	return status == OrderStatusFilled || status == OrderStatusCanceled || status == OrderStatusFailed
+}
+
+// orderStatusOf 将交易所返回的订单状态转换为订单记录的状态
+// 有成交但未全部成交时记为partially_filled，无论剩余部分是否仍在挂单
+func orderStatusOf(exchangeStatus string, filledQty float64) (string, error) {
+	switch exchangeStatus {
+	case OrderStatusFilled:
+		return OrderStatusFilled, nil
+	case OrderStatusOpen, OrderStatusPartiallyFilled, OrderStatusCanceled:
+		if filledQty > 0 {
+			return OrderStatusPartiallyFilled, nil
+		}
+		if exchangeStatus == OrderStatusPartiallyFilled {
+			return OrderStatusOpen, nil
+		}
+		return exchangeStatus, nil
+	default:
+		return "", fmt.Errorf("无法识别的订单状态: %s", exchangeStatus)
+	}
+}
+
+// OrderTransition 一次订单状态变更
+type OrderTransition struct {
+	Status string                  // 目标状态，为空时按Update中的交易所状态确定
+	Source string                  // 变更来源
+	Update *exchange.OrderResponse // 交易所返回的订单状态，有成交时同时更新成交信息
+	Note   string                  // 变更说明
+	Fields map[string]interface{}  // 同时写入订单记录的其他字段
+}
+
+// transitionOrder 校验并执行订单状态变更，订单记录和order_events在同一事务中更新
+// 状态和成交数量都没有变化时不做任何修改，返回变更后的状态；变更不合法时返回ErrIllegalTransition
+func transitionOrder(orderID string, t OrderTransition) (string, error) {
+	var status string
+	var changed *models.OrderEvent
+	err := repository.DB.Transaction(func(tx *gorm.DB) error {
+		var record models.OrderRecord
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).First(&record).Error; err != nil {
+			return fmt.Errorf("查询订单记录失败: %w", err)
+		}
+
+		filledQty := record.FilledAmount
+		if t.Update != nil && t.Update.FilledQty > filledQty {
+			filledQty = t.Update.FilledQty
+		}
+
+		to := t.Status
+		if to == "" && t.Update != nil {
+			var err error
+			if to, err = orderStatusOf(t.Update.Status, filledQty); err != nil {
+				return err
+			}
+		}
+		// 部分成交后取消的订单仍记为部分成交
+		if to == OrderStatusCanceled && filledQty > 0 {
+			to = OrderStatusPartiallyFilled
+		}
+		status = record.Status
+
+		fillChanged := t.Update != nil && t.Update.FilledQty > record.FilledAmount
+		if to == record.Status && !fillChanged && len(t.Fields) == 0 {
+			return nil
+		}
+		if to != record.Status && !canTransition(record.Status, to) {
+			return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, record.Status, to)
+		}
+
+		updates := map[string]interface{}{"status": to}
+		for k, v := range t.Fields {
+			updates[k] = v
+		}
+		event := models.OrderEvent{
+			OrderRecordID: record.ID,
+			OrderID:       record.OrderID,
+			FromStatus:    record.Status,
+			ToStatus:      to,
+			Source:        t.Source,
+			FilledAmount:  record.FilledAmount,
+			FilledPrice:   record.FilledPrice,
+			Note:          t.Note,
+		}
+		if newID, ok := t.Fields["order_id"].(string); ok {
+			event.OrderID = newID
+		}
+		if fillChanged {
+			f := fillDelta(&record, t.Update.FilledQty, t.Update.FilledPrice, t.Update.Fee, t.Update.FeeCurrency)
+			realized, err := recordFill(tx, &record, f)
+			if err != nil {
+				return err
+			}
+			if realized != 0 {
+				updates["realized_pnl"] = record.RealizedPnl + realized
+			}
+			updates["filled_price"] = t.Update.FilledPrice
+			updates["filled_amount"] = t.Update.FilledQty
+			updates["fee"] = t.Update.Fee
+			updates["fee_currency"] = t.Update.FeeCurrency
+			event.FilledAmount = t.Update.FilledQty
+			event.FilledPrice = t.Update.FilledPrice
+		}
+		if t.Update != nil {
+			event.Payload = orderPayload(t.Update)
+		}
+
+		if err := tx.Model(&models.OrderRecord{}).Where("id = ?", record.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("更新订单记录失败: %w", err)
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return fmt.Errorf("保存订单事件失败: %w", err)
+		}
+		status = to
+		changed = &event
+		return nil
+	})
+
+	if err == nil && changed != nil && changed.FromStatus != changed.ToStatus {
+		config.Logger.Infow("订单状态变更",
+			"order_id", changed.OrderID,
+			"previous_status", changed.FromStatus,
+			"current_status", changed.ToStatus,
+			"source", changed.Source,
+			"filled_amount", changed.FilledAmount,
+			"filled_price", changed.FilledPrice,
+		)
+	}
+	return status, err
+}
+
+// createOrderRecord 保存新的订单记录，并记录其初始状态
+func createOrderRecord(record *models.OrderRecord, source string, resp *exchange.OrderResponse, note string) error {
+	return repository.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		if record.FilledAmount > 0 {
+			f := fillDelta(&models.OrderRecord{}, record.FilledAmount, record.FilledPrice, record.Fee, record.FeeCurrency)
+			realized, err := recordFill(tx, record, f)
+			if err != nil {
+				return err
+			}
+			if realized != 0 {
+				record.RealizedPnl = realized
+				if err := tx.Model(record).Update("realized_pnl", realized).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		event := models.OrderEvent{
+			OrderRecordID: record.ID,
+			OrderID:       record.OrderID,
+			ToStatus:      record.Status,
+			Source:        source,
+			FilledAmount:  record.FilledAmount,
+			FilledPrice:   record.FilledPrice,
+			Note:          note,
+		}
+		if resp != nil {
+			event.Payload = orderPayload(resp)
+		}
+		return tx.Create(&event).Error
+	})
+}
+
+// applyTransition 执行订单状态变更，失败时只记录日志，返回变更后的状态
+// 状态变更失败不影响交易所上的订单，监控按交易所返回的状态继续
+func applyTransition(order *models.OrderRecord, t OrderTransition) string {
+	status, err := transitionOrder(order.OrderID, t)
+	if err != nil {
+		logFields := []interface{}{
+			"error", err.Error(),
+			"order_id", order.OrderID,
+			"symbol", order.Symbol,
+			"source", t.Source,
+		}
+		if t.Update != nil {
+			logFields = append(logFields, "exchange_status", t.Update.Status)
+		}
+		if errors.Is(err, ErrIllegalTransition) {
+			config.Logger.Warnw("忽略非法的订单状态变更", logFields...)
+		} else {
+			config.Logger.Errorw("更新订单状态失败", logFields...)
+		}
+	}
+	return status
+}
+
+// orderPayload 将交易所返回的订单状态序列化后保存到订单事件中
+func orderPayload(resp *exchange.OrderResponse) string {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -8,6 +8,7 @@ import (
 	"order_go/internal/models"
 	"order_go/internal/repository"
 	"order_go/internal/utils/config"
+	"strconv"
 	"strings"
 )
 
@@ -27,6 +28,9 @@ var (
 	// ErrInsufficientAddPositionRatio 加仓时剩余可用资金比例过低错误
 	ErrInsufficientAddPositionRatio = errors.New("剩余可用资金比例过低，不进行加仓")
 	
+	// ErrBelowMinAmount 持仓量或计算出的下单数量小于交易对最小交易量
+	ErrBelowMinAmount = errors.New("小于最小交易量")
+	
 	// 注意：ErrInsufficientBalance 错误已在 engine.go 中定义
 )
 
@@ -53,6 +57,8 @@ func DetermineSpotOrderStrategy(signal models.TradingSignal, ex exchange.Exchang
 		)
 		return params, err
 	}
+	// 多个策略交易同一交易对时，只按本策略自己成交形成的持仓平仓和加仓
+	position = strategyPosition(signal, position)
 	
 	// 2. 根据持仓情况和信号方向确定下单策略
 	// 获取交易对的最小交易量配置
@@ -73,13 +79,12 @@ func DetermineSpotOrderStrategy(signal models.TradingSignal, ex exchange.Exchang
 		// 没有有效持仓的情况下，需要根据信号方向决定是否下单
 		if signal.Action == "sell" {
 			// 如果是卖出信号但没有有效持仓，忽略该信号
-			var errMsg string
+			var err error
 			if position == nil || position.Size == 0 {
-				errMsg = "当前没有持仓仓位，无法卖出现货"
+				err = fmt.Errorf("%w: 当前没有持仓仓位，无法卖出现货", ErrNoPositionToClose)
 			} else {
-				errMsg = fmt.Sprintf("当前持仓量(%f)小于最小交易量(%f)，视为无持仓，忽略卖出信号", position.Size, minAmount)
+				err = fmt.Errorf("%w: 当前持仓量(%f)小于最小交易量(%f)，视为无持仓，忽略卖出信号", ErrBelowMinAmount, position.Size, minAmount)
 			}
-			err := errors.New(errMsg)
 			config.Logger.Warnw(err.Error(),
 				"symbol", signal.Symbol,
 				"action", signal.Action,
@@ -97,7 +102,7 @@ func DetermineSpotOrderStrategy(signal models.TradingSignal, ex exchange.Exchang
 		
 		// 设置开仓参数
 		params.PositionSide = "open"
-		amount, err := calculateOrderAmount(signal.Price, signal.Symbol, ex)
+		amount, err := calculateOrderAmount(signal.Price, signal.Symbol, ex, signalStrategyID(signal))
 		if err != nil {
 			config.Logger.Errorw("计算开仓数量失败",
 				"error", err.Error(),
@@ -127,15 +132,15 @@ func DetermineSpotOrderStrategy(signal models.TradingSignal, ex exchange.Exchang
 	}
 	closeAmount := roundAmount(position.Size * config.AppConfig.OrderStrategy.ClosePositionRatio, signal.Symbol)
 			params.PositionSide = "close"
-			params.Amount = closeAmount
+			params.Amount = fitSpotCloseAmount(ex, signal.Symbol, position, closeAmount, minAmount)
 			return params, nil
 		}
 		
 		// 计算交易对的最大可用资金
 		maxPositionValue := totalValue * contractCode.MaxPositionRatio / 100.0
 		
-		// 计算当前持仓价值
-		currentPositionValue := position.Size * signal.Price
+		// 计算当前持仓价值（账户计价币种）
+		currentPositionValue := position.Size * signal.Price * quoteRateOrPar(ex, signal.Symbol)
 		
 		// 计算当前持仓价值占交易对最大可用资金的比例
 		currentPositionRatio := currentPositionValue / maxPositionValue
@@ -206,7 +211,7 @@ func DetermineSpotOrderStrategy(signal models.TradingSignal, ex exchange.Exchang
 		)
 		
 		params.PositionSide = "close"
-		params.Amount = closeAmount
+		params.Amount = fitSpotCloseAmount(ex, signal.Symbol, position, closeAmount, minAmount)
 		
 		return params, nil
 	}
@@ -218,7 +223,7 @@ func DetermineSpotOrderStrategy(signal models.TradingSignal, ex exchange.Exchang
 	)
 	
 	// 3. 计算可加仓数量
-	addableAmount, err := calculateAddableAmount(signal.Symbol, signal.Price, ex)
+	addableAmount, err := calculateAddableAmount(signal.Symbol, signal.Price, ex, signalStrategyID(signal))
 	if err != nil {
 		config.Logger.Errorw("计算可加仓数量失败",
 			"error", err.Error(),
@@ -248,7 +253,7 @@ func DetermineSpotOrderStrategy(signal models.TradingSignal, ex exchange.Exchang
 
 // calculateOrderAmount 计算下单数量
 // 根据交易对最大交易额度计算可下单的数量
-func calculateOrderAmount(price float64, symbol string, ex exchange.Exchange) (float64, error) {
+func calculateOrderAmount(price float64, symbol string, ex exchange.Exchange, strategyID uint) (float64, error) {
 	// 交易对格式为"HYPE_USDT"
 	parts := strings.Split(symbol, "_")
 	if len(parts) < 2 {
@@ -269,9 +274,15 @@ func calculateOrderAmount(price float64, symbol string, ex exchange.Exchange) (f
 		return 0, err
 	}
 	
-	// 获取报价货币（USDT）
+	// 获取报价货币
 	quoteCurrency := parts[1]
 	
+	// 账户总价值以账户计价币种表示，交易对的计价币种不同时需要换算
+	rate, err := reportingRate(ex, quoteCurrency)
+	if err != nil {
+		return 0, err
+	}
+	
 	// 获取账户总价值
 	totalValue, err := account.GetTotalValue(ex)
 	if err != nil {
@@ -295,14 +306,16 @@ func calculateOrderAmount(price float64, symbol string, ex exchange.Exchange) (f
 		position = nil
 	}
 	
-	// 计算当前持仓价值
-	currentPositionValue := 0.0
-	if position != nil && position.Size > 0 {
-		currentPositionValue = position.Size * price
-	}
+	// 计算当前持仓价值（账户计价币种），按策略持仓时只计算策略自己的持仓
+	ownSize, totalSize := sizingPositionSize(strategyID, symbol, position)
+	currentPositionValue := ownSize * price * rate
 	
 	// 计算剩余可用资金（最大可用资金 - 当前持仓价值）
 	remainingFunds := maxPositionValue - currentPositionValue
+	// 交易对的最大交易额度是整个账户的限制，其他策略的持仓同样占用
+	if accountRemaining := maxPositionValue - totalSize*price*rate; accountRemaining < remainingFunds {
+		remainingFunds = accountRemaining
+	}
 	if remainingFunds <= 0 {
 		config.Logger.Warnw("已达到或超过交易对最大交易额度限制",
 			"symbol", symbol,
@@ -318,9 +331,9 @@ func calculateOrderAmount(price float64, symbol string, ex exchange.Exchange) (f
 	if config.AppConfig == nil {
 		panic("配置文件未加载，无法获取下单策略参数")
 	}
-	desiredFunds := remainingFunds * config.AppConfig.OrderStrategy.InitialOrderRatio
+	desiredFunds := remainingFunds * config.AppConfig.OrderStrategy.InitialOrderRatio / rate // 换算为交易对计价币种
 	
-	// 获取报价货币（USDT）的可用余额
+	// 获取报价货币的可用余额
 	available, _, err := ex.GetBalance(quoteCurrency)
 	if err != nil {
 		config.Logger.Errorw("获取账户余额失败",
@@ -349,25 +362,31 @@ func calculateOrderAmount(price float64, symbol string, ex exchange.Exchange) (f
 		"remaining_funds", remainingFunds,
 		"desired_funds", desiredFunds,
 		"available", available,
+		"quote_currency", quoteCurrency,
+		"reporting_rate", rate,
 	)
 	
-	// 计算可买入的数量并根据精度进行四舍五入
-	amount := roundAmount(desiredFunds/price, symbol)
+	// 计算可买入的数量并根据精度进行四舍五入，预留的手续费也计入下单资金
+	feeReserve := buyFeeReserve(ex, symbol)
+	amount := roundAmount(desiredFunds/(price*(1+feeReserve)), symbol)
 	if amount == 0 {
-		err := fmt.Errorf("计算的交易数量小于最小交易量 %.5f", contractCode.MinAmount)
+		err := fmt.Errorf("%w: 计算的交易数量小于最小交易量 %.5f", ErrBelowMinAmount, contractCode.MinAmount)
 		config.Logger.Warnw(err.Error(),
 			"symbol", symbol,
 			"min_amount", contractCode.MinAmount,
 		)
 		return 0, err
 	}
+	if err := checkMinNotional(contractCode, amount, price); err != nil {
+		return 0, err
+	}
 	
 	return amount, nil
 }
 
 // calculateAddableAmount 计算可加仓数量
 // 根据交易对最大交易额度计算可加仓的数量
-func calculateAddableAmount(symbol string, price float64, ex exchange.Exchange) (float64, error) {
+func calculateAddableAmount(symbol string, price float64, ex exchange.Exchange, strategyID uint) (float64, error) {
 	// 交易对格式为"HYPE_USDT"
 	parts := strings.Split(symbol, "_")
 	if len(parts) < 2 {
@@ -388,9 +407,15 @@ func calculateAddableAmount(symbol string, price float64, ex exchange.Exchange)
 		return 0, err
 	}
 	
-	// 获取报价货币（USDT）
+	// 获取报价货币
 	quoteCurrency := parts[1]
 	
+	// 账户总价值以账户计价币种表示，交易对的计价币种不同时需要换算
+	rate, err := reportingRate(ex, quoteCurrency)
+	if err != nil {
+		return 0, err
+	}
+	
 	// 获取账户总价值
 	totalValue, err := account.GetTotalValue(ex)
 	if err != nil {
@@ -414,14 +439,16 @@ func calculateAddableAmount(symbol string, price float64, ex exchange.Exchange)
 		position = nil
 	}
 	
-	// 计算当前持仓价值
-	currentPositionValue := 0.0
-	if position != nil && position.Size > 0 {
-		currentPositionValue = position.Size * price
-	}
+	// 计算当前持仓价值（账户计价币种），按策略持仓时只计算策略自己的持仓
+	ownSize, totalSize := sizingPositionSize(strategyID, symbol, position)
+	currentPositionValue := ownSize * price * rate
 	
 	// 计算剩余可用资金（最大可用资金 - 当前持仓价值）
 	remainingFunds := maxPositionValue - currentPositionValue
+	// 交易对的最大交易额度是整个账户的限制，其他策略的持仓同样占用
+	if accountRemaining := maxPositionValue - totalSize*price*rate; accountRemaining < remainingFunds {
+		remainingFunds = accountRemaining
+	}
 	if remainingFunds <= 0 {
 		config.Logger.Warnw("已达到或超过交易对最大交易额度限制",
 			"symbol", symbol,
@@ -455,9 +482,9 @@ func calculateAddableAmount(symbol string, price float64, ex exchange.Exchange)
 	if config.AppConfig == nil {
 		panic("配置文件未加载，无法获取下单策略参数")
 	}
-	desiredFunds := remainingFunds * config.AppConfig.OrderStrategy.AddPositionRatio
+	desiredFunds := remainingFunds * config.AppConfig.OrderStrategy.AddPositionRatio / rate // 换算为交易对计价币种
 	
-	// 获取报价货币（USDT）的可用余额
+	// 获取报价货币的可用余额
 	available, _, err := ex.GetBalance(quoteCurrency)
 	if err != nil {
 		config.Logger.Errorw("获取账户余额失败",
@@ -486,10 +513,13 @@ func calculateAddableAmount(symbol string, price float64, ex exchange.Exchange)
 		"remaining_funds", remainingFunds,
 		"desired_funds", desiredFunds,
 		"available", available,
+		"quote_currency", quoteCurrency,
+		"reporting_rate", rate,
 	)
 	
-	// 计算可买入的数量并根据精度进行四舍五入
-	rawAmount := desiredFunds / price
+	// 计算可买入的数量并根据精度进行四舍五入，预留的手续费也计入下单资金
+	feeReserve := buyFeeReserve(ex, symbol)
+	rawAmount := desiredFunds / (price * (1 + feeReserve))
 	amount := roundAmount(rawAmount, symbol)
 	
 	// 记录计算得到的加仓数量
@@ -497,22 +527,83 @@ func calculateAddableAmount(symbol string, price float64, ex exchange.Exchange)
 		"symbol", symbol,
 		"raw_amount", rawAmount,
 		"rounded_amount", amount,
+		"fee_reserve", feeReserve,
 		"max_position_ratio", contractCode.MaxPositionRatio,
 	)
 	
 	if amount == 0 {
-		err := fmt.Errorf("计算的加仓数量小于最小交易量 %.5f", contractCode.MinAmount)
+		err := fmt.Errorf("%w: 计算的加仓数量小于最小交易量 %.5f", ErrBelowMinAmount, contractCode.MinAmount)
 		config.Logger.Warnw(err.Error(),
 			"symbol", symbol,
 			"min_amount", contractCode.MinAmount,
 		)
 		return 0, err
 	}
+	if err := checkMinNotional(contractCode, amount, price); err != nil {
+		return 0, err
+	}
 	
 	return amount, nil
 }
 
-// 使用engine.go中已定义的getBaseCurrency函数
+// signalStrategyID 获取信号所属的策略ID，无法解析时为0
+func signalStrategyID(signal models.TradingSignal) uint {
+	strategyID, _ := strconv.ParseUint(signal.StrategyID, 10, 64)
+	return uint(strategyID)
+}
+
+// checkMinNotional 检查下单金额是否达到交易所的最小下单金额
+func checkMinNotional(contractCode models.ContractCode, amount, price float64) error {
+	if contractCode.MinNotional <= 0 || amount*price >= contractCode.MinNotional {
+		return nil
+	}
+	
+	err := fmt.Errorf("%w: 下单金额%.8f小于最小下单金额%.8f", ErrBelowMinAmount, amount*price, contractCode.MinNotional)
+	config.Logger.Warnw(err.Error(),
+		"symbol", contractCode.Symbol,
+		"amount", amount,
+		"price", price,
+	)
+	return err
+}
+
+// splitSymbol 拆分交易对为基础币和计价币，交易对格式为"HYPE_USDT"
+func splitSymbol(symbol string) (string, string, error) {
+	parts := strings.Split(symbol, "_")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("无效的交易对格式: %s", symbol)
+	}
+	return parts[0], parts[1], nil
+}
+
+// reportingRate 获取交易对计价币种折合账户计价币种的汇率
+// 最大交易额度按账户计价币种计算，持仓价值和下单资金以交易对计价币种表示，比较前需要换算
+func reportingRate(ex exchange.Exchange, quoteCurrency string) (float64, error) {
+	rate, err := account.ConversionRate(ex, quoteCurrency, account.ReportingCurrency())
+	if err != nil {
+		config.Logger.Errorw("获取计价币种汇率失败",
+			"error", err.Error(),
+			"quote_currency", quoteCurrency,
+			"reporting_currency", account.ReportingCurrency(),
+		)
+		return 0, err
+	}
+	return rate, nil
+}
+
+// quoteRateOrPar 获取交易对计价币种折合账户计价币种的汇率，无法换算时按1:1估算
+// 仅用于平仓比例判断，汇率缺失不应阻止平仓
+func quoteRateOrPar(ex exchange.Exchange, symbol string) float64 {
+	_, quoteCurrency, err := splitSymbol(symbol)
+	if err != nil {
+		return 1
+	}
+	rate, err := reportingRate(ex, quoteCurrency)
+	if err != nil {
+		return 1
+	}
+	return rate
+}
 
 // getContractConfig 获取交易对配置
 // 从数据库中读取交易对的最小交易量和精度等配置
@@ -0,0 +1,130 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"order_go/internal/constants"
+	"order_go/internal/exchange"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/utils/config"
+	"strconv"
+)
+
+// ErrSlippageExceeded 盘口相对信号价格的不利变动超过限制
+var ErrSlippageExceeded = errors.New("盘口价格偏离信号价格超过最大滑点")
+
+// pricingBookDepth 定价和滑点检查只用到买一和卖一
+const pricingBookDepth = 5
+
+// pricingPolicy 策略的限价单定价设置
+type pricingPolicy struct {
+	policy      string
+	ticks       int
+	maxSlippage float64
+}
+
+// strategyPricing 获取策略的定价设置，策略不存在时使用信号价格；策略未设置滑点限制时使用全局配置
+func strategyPricing(strategyID uint) pricingPolicy {
+	p := pricingPolicy{policy: constants.PricePolicySignal}
+
+	var stra models.Strategy
+	if err := repository.DB.Select("price_policy", "price_ticks", "max_slippage").First(&stra, strategyID).Error; err == nil {
+		if stra.PricePolicy != "" {
+			p.policy = stra.PricePolicy
+		}
+		p.ticks = stra.PriceTicks
+		p.maxSlippage = stra.MaxSlippage
+	}
+
+	if p.maxSlippage <= 0 && config.AppConfig != nil {
+		p.maxSlippage = config.AppConfig.OrderStrategy.MaxSlippage
+	}
+	return p
+}
+
+// resolveOrderPrice 按策略的定价方式确定下单价格，并检查盘口相对信号价格的滑点
+// 买入以卖一价、卖出以买一价衡量滑点，只检查不利方向；市价单不按盘口定价但同样检查滑点
+// 使用信号价格且不检查滑点时不查询盘口
+func resolveOrderPrice(signal models.TradingSignal, ex exchange.Exchange, orderType string) (float64, error) {
+	strategyID, _ := strconv.ParseUint(signal.StrategyID, 10, 64)
+	p := strategyPricing(uint(strategyID))
+
+	usePolicy := orderType == "limit" && p.policy != constants.PricePolicySignal
+	if !usePolicy && p.maxSlippage <= 0 {
+		return signal.Price, nil
+	}
+
+	book, err := ex.GetOrderBook(signal.Symbol, pricingBookDepth)
+	if err != nil {
+		return 0, fmt.Errorf("获取盘口失败: %w", err)
+	}
+	bid, ask := book.BestBid(), book.BestAsk()
+	if bid <= 0 || ask <= 0 {
+		return 0, fmt.Errorf("交易对%s的盘口为空", signal.Symbol)
+	}
+
+	isBuy := signal.Action == "buy"
+	if p.maxSlippage > 0 && signal.Price > 0 {
+		slippage := (signal.Price - bid) / signal.Price
+		if isBuy {
+			slippage = (ask - signal.Price) / signal.Price
+		}
+		if slippage > p.maxSlippage {
+			return 0, fmt.Errorf("%w: 信号价格%v，买一%v，卖一%v，偏离%.4f%%，限制%.4f%%",
+				ErrSlippageExceeded, signal.Price, bid, ask, slippage*100, p.maxSlippage*100)
+		}
+	}
+
+	if !usePolicy {
+		return signal.Price, nil
+	}
+
+	contractCode, err := getFullContractConfig(signal.Symbol)
+	if err != nil {
+		return 0, err
+	}
+	tick := math.Pow10(-contractCode.PricePrecision)
+
+	var price float64
+	switch p.policy {
+	case constants.PricePolicyBest:
+		if isBuy {
+			price = ask + float64(p.ticks)*tick
+		} else {
+			price = bid - float64(p.ticks)*tick
+		}
+	case constants.PricePolicyMid:
+		price = (bid + ask) / 2
+	default:
+		return 0, fmt.Errorf("不支持的定价方式: %s", p.policy)
+	}
+
+	price = roundPrice(price, contractCode.PricePrecision, isBuy)
+	if price <= 0 {
+		return 0, fmt.Errorf("按%s定价得到的价格无效: %v", p.policy, price)
+	}
+
+	config.Logger.Infow("按盘口确定下单价格",
+		"symbol", signal.Symbol,
+		"action", signal.Action,
+		"policy", p.policy,
+		"ticks", p.ticks,
+		"signal_price", signal.Price,
+		"best_bid", bid,
+		"best_ask", ask,
+		"price", price,
+	)
+	return price, nil
+}
+
+// roundPrice 按价格精度取整，买入向下、卖出向上，不超出计算出的价格
+func roundPrice(price float64, precision int, isBuy bool) float64 {
+	factor := math.Pow10(precision)
+	// 容差用于消除浮点误差，避免本来就在价格刻度上的价格被多取一档
+	if isBuy {
+		return math.Floor(price*factor+1e-6) / factor
+	}
+	return math.Ceil(price*factor-1e-6) / factor
+}
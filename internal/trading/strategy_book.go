@@ -0,0 +1,156 @@
+package trading
+
+import (
+	"math"
+	"order_go/internal/models"
+	"order_go/internal/repository"
+	"order_go/internal/utils/config"
+	"sort"
+)
+
+// useStrategyBooks 判断现货下单是否按策略自己的持仓计算
+// 未绑定策略的信号只能按账户持仓计算
+func useStrategyBooks(strategyID uint) bool {
+	if strategyID == 0 {
+		return false
+	}
+	return config.AppConfig == nil || !config.AppConfig.OrderStrategy.AccountPositions
+}
+
+// strategyBookSize 获取策略在交易对上的虚拟持仓数量，来自持仓账本中该策略的行，没有成交时为0
+func strategyBookSize(strategyID uint, symbol string) (float64, float64) {
+	var row models.PositionLedger
+	err := repository.DB.Select("quantity", "entry_price").
+		Where("account = ? AND symbol = ? AND strategy_id = ?", strategyAccount(strategyID), symbol, strategyID).
+		First(&row).Error
+	if err != nil || row.Quantity < 0 {
+		return 0, 0
+	}
+	return row.Quantity, row.EntryPrice
+}
+
+// strategyPosition 将账户在交易对上的现货持仓换成策略自己的虚拟持仓
+// 虚拟持仓不超过账户的实际持仓，多出的部分可能已被手动卖出或转出
+func strategyPosition(signal models.TradingSignal, actual *models.Position) *models.Position {
+	strategyID := signalStrategyID(signal)
+	if !useStrategyBooks(strategyID) {
+		return actual
+	}
+
+	size, entryPrice := strategyBookSize(strategyID, signal.Symbol)
+	actualSize := 0.0
+	if actual != nil {
+		actualSize = actual.Size
+	}
+	if size > actualSize {
+		config.Logger.Warnw("策略持仓超过账户实际持仓，按实际持仓计算",
+			"symbol", signal.Symbol,
+			"strategy_id", strategyID,
+			"strategy_size", size,
+			"actual_size", actualSize,
+		)
+		size = math.Max(actualSize, 0)
+	}
+
+	config.Logger.Infow("按策略持仓计算下单数量",
+		"symbol", signal.Symbol,
+		"strategy_id", strategyID,
+		"strategy_size", size,
+		"actual_size", actualSize,
+	)
+	return &models.Position{Symbol: signal.Symbol, Size: size, EntryPrice: entryPrice}
+}
+
+// sizingPositionSize 获取计算开仓和加仓额度时使用的持仓数量
+// 按策略持仓时，额度由策略自己的持仓计算，同时返回账户持仓用于检查交易对的总额度
+func sizingPositionSize(strategyID uint, symbol string, actual *models.Position) (own, total float64) {
+	if actual != nil && actual.Size > 0 {
+		total = actual.Size
+	}
+	if !useStrategyBooks(strategyID) {
+		return total, total
+	}
+	own, _ = strategyBookSize(strategyID, symbol)
+	return math.Min(own, total), total
+}
+
+// NettingBook 策略在交易对上的虚拟持仓
+type NettingBook struct {
+	StrategyID uint    `json:"strategy_id"`
+	Quantity   float64 `json:"quantity"`
+	EntryPrice float64 `json:"entry_price"`
+}
+
+// NettingRow 账户在交易对上各策略虚拟持仓与交易所实际持仓的核对结果
+type NettingRow struct {
+	Account          string        `json:"account"`
+	Symbol           string        `json:"symbol"`
+	MarketType       string        `json:"market_type"`
+	Books            []NettingBook `json:"books"`
+	BooksTotal       float64       `json:"books_total"`       // 各策略虚拟持仓之和
+	LedgerQuantity   float64       `json:"ledger_quantity"`   // 持仓账本中的账户汇总持仓
+	ExchangeQuantity *float64      `json:"exchange_quantity"` // 交易所的实际持仓，查询失败时为空
+	Unallocated      *float64      `json:"unallocated"`       // 实际持仓中不属于任何策略的部分，例如账本建立前的持仓或手动交易
+	Error            string        `json:"error,omitempty"`
+}
+
+// BuildNettingReport 核对各策略的虚拟持仓之和与交易所实际持仓，account为nil时核对所有账户
+func (e *Engine) BuildNettingReport(account *string, symbol string) ([]NettingRow, error) {
+	query := repository.DB.Model(&models.PositionLedger{})
+	if account != nil {
+		query = query.Where("account = ?", *account)
+	}
+	if symbol != "" {
+		query = query.Where("symbol = ?", symbol)
+	}
+
+	var rows []models.PositionLedger
+	if err := query.Order("account, symbol, strategy_id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*NettingRow)
+	var keys []string
+	for _, row := range rows {
+		key := row.Account + "|" + row.Symbol
+		r, ok := byKey[key]
+		if !ok {
+			r = &NettingRow{Account: row.Account, Symbol: row.Symbol, MarketType: row.MarketType, Books: []NettingBook{}}
+			byKey[key] = r
+			keys = append(keys, key)
+		}
+		if row.StrategyID == 0 {
+			r.LedgerQuantity = row.Quantity
+			continue
+		}
+		r.Books = append(r.Books, NettingBook{StrategyID: row.StrategyID, Quantity: row.Quantity, EntryPrice: row.EntryPrice})
+		r.BooksTotal += row.Quantity
+	}
+	sort.Strings(keys)
+
+	report := make([]NettingRow, 0, len(keys))
+	for _, key := range keys {
+		r := byKey[key]
+		ex, err := e.ExchangeForAccount(r.Account, r.Symbol)
+		if err == nil {
+			var position *models.Position
+			if position, err = ex.GetPosition(r.Symbol); err == nil {
+				actual := 0.0
+				if position != nil {
+					actual = position.Size
+				}
+				unallocated := actual - r.BooksTotal
+				if math.Abs(unallocated) < ledgerEpsilon {
+					unallocated = 0
+				}
+				r.ExchangeQuantity = &actual
+				r.Unallocated = &unallocated
+			}
+		}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		report = append(report, *r)
+	}
+	return report, nil
+}
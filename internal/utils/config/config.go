@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -11,11 +14,37 @@ import (
 
 // ExchangeConfig 交易所配置
 type ExchangeConfig struct {
-	ApiKey      string `yaml:"api_key"`
-	ApiSecret   string `yaml:"api_secret"`
-	Passphrase  string `yaml:"passphrase,omitempty"` // OKX需要
-	BaseURL     string `yaml:"base_url"`
-	AccountType string `yaml:"account_type,omitempty"` // 账户类型：spot(现货)、margin(保证金)、futures(期货)，默认为spot
+	ApiKey      string   `yaml:"api_key"`
+	ApiSecret   string   `yaml:"api_secret"`
+	Passphrase  string   `yaml:"passphrase,omitempty"` // OKX需要
+	BaseURL     string   `yaml:"base_url"`
+	WSURL       string   `yaml:"ws_url,omitempty"`       // WebSocket地址，为空时使用交易所默认地址
+	AccountType string   `yaml:"account_type,omitempty"` // 账户类型：spot(现货)、margin(保证金)、futures(期货)，默认为spot
+	Settle      string   `yaml:"settle,omitempty"`       // 永续合约结算币种，例如 usdt，默认为usdt
+	Symbols     []string `yaml:"symbols,omitempty"`      // 路由到该交易所的现货交易对，例如 BTC_USDT
+	StrategyIDs []uint   `yaml:"strategy_ids,omitempty"` // 路由到该交易所的策略ID，优先于交易对路由
+
+	// 限流与重试，未配置时使用默认值
+	RateLimits map[string]float64 `yaml:"rate_limits,omitempty"` // 各接口每秒请求数，例如 create_order: 10
+	MaxRetries int                `yaml:"max_retries,omitempty"` // 临时性错误的最大重试次数，默认为3
+	Timeout    string             `yaml:"timeout,omitempty"`     // REST请求超时时间，例如 "10s"，默认为10秒；超时的下单请求按结果不确定处理
+
+	// 以下字段仅用于模拟盘(paper)
+	PriceSource     string             `yaml:"price_source,omitempty"`     // 行情来源交易所，默认为gateio
+	MakerFeeRate    float64            `yaml:"maker_fee_rate,omitempty"`   // 挂单手续费率，例如 0.002
+	TakerFeeRate    float64            `yaml:"taker_fee_rate,omitempty"`   // 吃单手续费率，例如 0.002
+	InitialBalances map[string]float64 `yaml:"initial_balances,omitempty"` // 初始虚拟余额，例如 USDT: 10000
+}
+
+// defaultRequestTimeout 交易所REST请求的默认超时时间
+const defaultRequestTimeout = 10 * time.Second
+
+// RequestTimeout 获取交易所REST请求的超时时间，未配置或无法解析时使用默认值
+func (c *ExchangeConfig) RequestTimeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultRequestTimeout
 }
 
 // Config 应用配置
@@ -42,8 +71,28 @@ type Config struct {
 		ClosePositionRatio        float64 `yaml:"close_position_ratio"`         // 平仓时平掉持仓量的比例
 		MinPositionRatio          float64 `yaml:"min_position_ratio"`           // 持仓量占交易对最大交易额度的最小比例阈值
 		MinAddPositionRatio       float64 `yaml:"min_add_position_ratio"`        // 加仓时剩余可用资金占交易对最大交易额度的最小比例阈值
+		FeeRate                   float64 `yaml:"fee_rate"`                     // 交易所不支持查询手续费率时，买入预留的手续费率，默认为0.002
+		MaxSlippage               float64 `yaml:"max_slippage"`                 // 策略未设置时，盘口相对信号价格不利变动的最大比例，0表示不检查
+		AccountPositions          bool    `yaml:"account_positions"`            // 现货平仓和加仓按账户持仓计算，而不是策略自己成交形成的持仓
 	} `yaml:"order_strategy"`
-	Exchanges map[string]ExchangeConfig `yaml:"exchanges"`
+	RuleSync struct {
+		Interval  string `yaml:"interval"`   // 同步交易所交易对规则的间隔，例如 "24h"，默认24小时
+		AutoApply bool   `yaml:"auto_apply"` // 是否自动将交易所规则写入交易对配置，否则只报告差异
+	} `yaml:"rule_sync"`
+	Valuation struct {
+		Currency         string   `yaml:"currency"`          // 账户总价值和交易对最大交易额度的计价币种，默认为USDT
+		Stablecoins      []string `yaml:"stablecoins"`       // 按1:1计价的稳定币，默认为USDT、USDC、FDUSD、DAI、TUSD
+		Intermediates    []string `yaml:"intermediates"`     // 没有直接计价交易对时用于换算的中间币种，默认为BTC、ETH
+		SnapshotInterval string   `yaml:"snapshot_interval"` // 定时刷新账户总价值并保存快照的间隔，例如 "1h"，为空时只在手动刷新时保存
+	} `yaml:"valuation"`
+	MarketData struct {
+		Enabled          bool     `yaml:"enabled"`           // 是否启动行情记录任务
+		Intervals        []string `yaml:"intervals"`         // 记录的K线周期，默认为1m、1h、1d
+		Backfill         string   `yaml:"backfill"`          // 首次记录和补齐缺口时回溯的时长，例如 "168h"，默认7天
+		SnapshotInterval string   `yaml:"snapshot_interval"` // 保存行情快照的间隔，例如 "1m"，默认1分钟
+	} `yaml:"market_data"`
+	AccountExchange string                    `yaml:"account_exchange"` // 计算账户总价值使用的交易所代码，spot/futures表示默认账户的现货或永续合约，默认为spot
+	Exchanges       map[string]ExchangeConfig `yaml:"exchanges"`
 }
 
 var AppConfig *Config
@@ -61,11 +110,43 @@ func InitConfig() error {
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return err
 	}
+	if err := validateRoutes(cfg); err != nil {
+		return err
+	}
 
 	AppConfig = cfg
 	return nil
 }
 
+// validateRoutes 检查交易所的策略和交易对路由，同一策略或交易对只能路由到一个交易所
+// 否则信号的下单交易所取决于map的遍历顺序，每次可能不同
+func validateRoutes(cfg *Config) error {
+	names := make([]string, 0, len(cfg.Exchanges))
+	for name := range cfg.Exchanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	strategies := make(map[uint]string)
+	symbols := make(map[string]string)
+	for _, name := range names {
+		ex := cfg.Exchanges[name]
+		for _, id := range ex.StrategyIDs {
+			if other, ok := strategies[id]; ok && other != name {
+				return fmt.Errorf("策略%d同时路由到交易所%s和%s", id, other, name)
+			}
+			strategies[id] = name
+		}
+		for _, symbol := range ex.Symbols {
+			if other, ok := symbols[symbol]; ok && other != name {
+				return fmt.Errorf("交易对%s同时路由到交易所%s和%s", symbol, other, name)
+			}
+			symbols[symbol] = name
+		}
+	}
+	return nil
+}
+
 // InitLogger 初始化日志
 func InitLogger() error {
 	// 创建自定义日志配置
@@ -116,3 +197,30 @@ func GetExchangeConfig(name string) (*ExchangeConfig, bool) {
 	
 	return &cfg, true
 }
+
+
+// GetExchangeForSignal 获取配置了该策略或交易对路由的交易所名称
+// 策略路由优先于交易对路由，加载配置时已保证同一策略或交易对只路由到一个交易所
+func GetExchangeForSignal(strategyID uint, symbol string) (string, bool) {
+	if AppConfig == nil {
+		return "", false
+	}
+	
+	for name, cfg := range AppConfig.Exchanges {
+		for _, id := range cfg.StrategyIDs {
+			if id == strategyID {
+				return name, true
+			}
+		}
+	}
+	
+	for name, cfg := range AppConfig.Exchanges {
+		for _, s := range cfg.Symbols {
+			if s == symbol {
+				return name, true
+			}
+		}
+	}
+	
+	return "", false
+}
@@ -7,10 +7,12 @@ import (
 	"order_go/internal/api/routes"
 	"order_go/internal/cache"
 	"order_go/internal/database"
-	"order_go/internal/exchange"
+	"order_go/internal/marketdata"
 	"order_go/internal/queue"
 	"order_go/internal/repository"
+	"order_go/internal/rulesync"
 	"order_go/internal/strategy"
+	"order_go/internal/trading"
 	"order_go/internal/utils/config"
 	"order_go/internal/validator"
 
@@ -53,8 +55,14 @@ func main() {
 
 // printAccountTotalValue 计算并输出账户总价值
 func printAccountTotalValue() {
-	// 创建Gate.io交易所实例
-	ex := exchange.NewGateIO()
+	// 获取计算账户总价值使用的交易所
+	ex, err := cache.AccountExchange()
+	if err != nil {
+		config.Logger.Errorw("计算账户总价值失败",
+			"error", err.Error(),
+		)
+		return
+	}
 	
 	// 调用账户总价值计算函数
 	totalValue, err := account.GetTotalValue(ex)
@@ -67,7 +75,7 @@ func printAccountTotalValue() {
 	
 	// 输出账户总价值
 	config.Logger.Infow("账户总价值",
-		"total_value_usdt", fmt.Sprintf("%.2f USDT", totalValue),
+		"total_value", fmt.Sprintf("%.2f %s", totalValue, account.ReportingCurrency()),
 	)
 }
 
@@ -104,6 +112,12 @@ func startServer() {
 		)
 		// 注意：这里不会停止系统启动，只是输出警告日志
 	}
+	
+	// 启动交易对规则同步任务
+	rulesync.Start()
+	
+	// 启动行情记录任务，保存K线和行情快照供回测和分析使用
+	marketdata.StartRecorder(trading.GetEngine().ExchangeForSymbol)
 
 	// 设置运行模式
 	gin.SetMode(config.AppConfig.Server.Mode)